@@ -0,0 +1,194 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github/CiroLong/realworld-gin/internal/config"
+	"github/CiroLong/realworld-gin/internal/repository/gorm"
+	"github/CiroLong/realworld-gin/internal/repository/memory"
+	"github/CiroLong/realworld-gin/internal/service"
+	"strconv"
+	"strings"
+)
+
+// 命令行子命令
+//
+//	realworld-server migrate up             执行所有未执行的迁移
+//	realworld-server migrate down [n]       回滚最近 n 个迁移（默认 1）
+//	realworld-server migrate status         查看迁移状态
+//	realworld-server migrate create <name>  为每种方言生成一对空的迁移文件
+//	realworld-server unlock <email>         解除账号的登录锁定
+//	realworld-server roles seed             写入默认角色（user / moderator / admin）
+//	realworld-server roles list             查看角色及权限
+//	realworld-server roles assign <email> <role>  修改用户的角色
+
+// defaultMigrationsDir migrate create 生成文件的位置，需要在仓库根目录下执行
+const defaultMigrationsDir = "internal/repository/gorm/migrations"
+
+const usage = `usage:
+  realworld-server                          start the API server
+  realworld-server migrate up               apply all pending migrations
+  realworld-server migrate down [n]         roll back the last n migrations (default 1)
+  realworld-server migrate status           show migration status
+  realworld-server migrate create <name>    create empty up/down files for every dialect
+  realworld-server unlock <email>           unlock an account locked after failed logins
+  realworld-server roles seed               create or reset the default roles (user, moderator, admin)
+  realworld-server roles list               list roles and their permissions
+  realworld-server roles assign <email> <role>  change a user's role`
+
+func runCommand(args []string) error {
+	switch args[0] {
+	case "migrate":
+		return runMigrate(args[1:])
+	case "unlock":
+		return runUnlock(args[1:])
+	case "roles":
+		return runRoles(args[1:])
+	default:
+		return fmt.Errorf("unknown command %q\n%s", args[0], usage)
+	}
+}
+
+func runMigrate(args []string) error {
+	if len(args) == 0 {
+		return errors.New(usage)
+	}
+
+	// create 只生成文件，不需要连接数据库
+	if args[0] == "create" {
+		if len(args) < 2 {
+			return errors.New("migrate create: missing migration name")
+		}
+		files, err := gorm.CreateMigration(defaultMigrationsDir, args[1])
+		if err != nil {
+			return err
+		}
+		for _, f := range files {
+			fmt.Println("created", f)
+		}
+		return nil
+	}
+
+	if config.C().Database.Driver == memory.Driver {
+		return errors.New("memory driver has no schema to migrate")
+	}
+	if err := gorm.InitDB(); err != nil {
+		return err
+	}
+	migrator, err := gorm.NewMigrator(gorm.GetDB())
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		for _, m := range applied {
+			fmt.Printf("up   %04d_%s\n", m.Version, m.Name)
+		}
+		if err == nil && len(applied) == 0 {
+			fmt.Println("no pending migrations")
+		}
+		return err
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps < 1 {
+				return fmt.Errorf("migrate down: invalid step count %q", args[1])
+			}
+		}
+		reverted, err := migrator.Down(ctx, steps)
+		for _, m := range reverted {
+			fmt.Printf("down %04d_%s\n", m.Version, m.Name)
+		}
+		return err
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		for _, st := range statuses {
+			if st.Applied {
+				fmt.Printf("[x] %04d_%s  applied at %s\n", st.Version, st.Name, st.AppliedAt.Format("2006-01-02 15:04:05"))
+			} else {
+				fmt.Printf("[ ] %04d_%s  pending\n", st.Version, st.Name)
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown migrate command %q\n%s", args[0], usage)
+	}
+}
+
+// runUnlock 清空账号的失败计数和锁定，计数存在数据库里时才能从命令行操作
+func runUnlock(args []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: realworld-server unlock <email>")
+	}
+
+	cfg := config.C()
+	if cfg.Database.Driver == memory.Driver || cfg.LoginProtection.Store == memory.Driver {
+		return errors.New("login attempts are kept in the server process, restart the server to clear them")
+	}
+	if err := gorm.InitDB(); err != nil {
+		return err
+	}
+
+	guard := service.NewLoginProtection(gorm.NewLoginAttemptRepo(gorm.GetDB()), loginProtectionConfig(cfg.LoginProtection))
+	if err := guard.Unlock(context.Background(), args[0]); err != nil {
+		return err
+	}
+	fmt.Println("unlocked", args[0])
+	return nil
+}
+
+// runRoles 角色管理，内存模式下默认角色在启动时写入，数据不在本进程里，无法从命令行操作
+func runRoles(args []string) error {
+	if len(args) == 0 {
+		return errors.New(usage)
+	}
+
+	if config.C().Database.Driver == memory.Driver {
+		return errors.New("memory driver keeps data in the server process, default roles are seeded at startup")
+	}
+	if err := gorm.InitDB(); err != nil {
+		return err
+	}
+	db := gorm.GetDB()
+	roles := service.NewRoleService(gorm.NewRoleRepo(db), gorm.NewUserRepo(db))
+	ctx := context.Background()
+
+	switch args[0] {
+	case "seed":
+		names, err := roles.Seed(ctx)
+		for _, name := range names {
+			fmt.Println("seeded", name)
+		}
+		return err
+	case "list":
+		resp, err := roles.List(ctx)
+		if err != nil {
+			return err
+		}
+		if len(resp.Roles) == 0 {
+			fmt.Println("no roles, run `realworld-server roles seed` first")
+		}
+		for _, role := range resp.Roles {
+			fmt.Printf("%-12s %s\n", role.Name, strings.Join(role.Permissions, " "))
+		}
+		return nil
+	case "assign":
+		if len(args) != 3 {
+			return errors.New("usage: realworld-server roles assign <email> <role>")
+		}
+		if err := roles.Assign(ctx, args[1], args[2]); err != nil {
+			return err
+		}
+		fmt.Printf("%s is now %s\n", args[1], args[2])
+		return nil
+	default:
+		return fmt.Errorf("unknown roles command %q\n%s", args[0], usage)
+	}
+}
@@ -1,12 +1,24 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"github/CiroLong/realworld-gin/internal/config"
 	"github/CiroLong/realworld-gin/internal/pkg/jwt"
+	"github/CiroLong/realworld-gin/internal/pkg/mailer"
+	"github/CiroLong/realworld-gin/internal/pkg/oidc"
+	"github/CiroLong/realworld-gin/internal/pkg/password"
+	"github/CiroLong/realworld-gin/internal/pkg/secretbox"
+	"github/CiroLong/realworld-gin/internal/pkg/slug"
+	"github/CiroLong/realworld-gin/internal/repository"
+	"github/CiroLong/realworld-gin/internal/repository/cache"
 	"github/CiroLong/realworld-gin/internal/repository/gorm"
+	"github/CiroLong/realworld-gin/internal/repository/memory"
 	"github/CiroLong/realworld-gin/internal/router"
 	"github/CiroLong/realworld-gin/internal/service"
 	"log"
+	"os"
+	"sort"
 )
 
 // 	运行流程
@@ -16,6 +28,8 @@ import (
 //	依赖注入
 //	注册路由和中间件
 //	启动 Gin 服务
+//
+//	带参数运行时作为命令行工具，见 cli.go
 
 func main() {
 	// 1. 读配置
@@ -24,43 +38,310 @@ func main() {
 	}
 	cfg := config.C()
 
+	if len(os.Args) > 1 {
+		if err := runCommand(os.Args[1:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	log.Println("========== 配置信息 ==========")
 	log.Printf("Server.Addr: %s", cfg.Server.Addr)
 	log.Printf("Server.ReadTimeout: %v", cfg.Server.ReadTimeout)
 	log.Printf("Server.WriteTimeout: %v", cfg.Server.WriteTimeout)
-	log.Printf("Database.DSN: %s", cfg.Database.DSN)
+	log.Printf("Database.Driver: %s", cfg.Database.Driver)
+	log.Printf("Database.AutoMigrate: %v", cfg.Database.AutoMigrate)
+	log.Printf("Database.DSN: %s", gorm.RedactDSN(cfg.Database.Driver, cfg.Database.DSN))
 	log.Printf("Database.MaxOpenConns: %d", cfg.Database.MaxOpenConns)
 	log.Printf("Database.MaxIdleConns: %d", cfg.Database.MaxIdleConns)
 	log.Printf("Database.ConnMaxLifetime: %v", cfg.Database.ConnMaxLifetime)
-	log.Printf("JWT.Secret: %s", cfg.JWT.Secret)
+	log.Printf("JWT.SigningKeyID: %s", cfg.JWT.SigningKeyID)
 	log.Printf("JWT.ExpireTime: %v", cfg.JWT.ExpireTime)
+	log.Printf("JWT.RefreshExpireTime: %v", cfg.JWT.RefreshExpireTime)
+	log.Printf("JWT.SessionCacheTTL: %v", cfg.JWT.SessionCacheTTL)
+	log.Printf("Password.Algorithm: %s", cfg.Password.Algorithm)
+	log.Printf("Mail.Driver: %s", cfg.Mail.Driver)
+	log.Printf("Account.LinkBaseURL: %s", cfg.Account.LinkBaseURL)
+	log.Printf("Account.RequireVerifiedEmail: %v", cfg.Account.RequireVerifiedEmail)
+	log.Printf("Account.DeletionPolicy: %s", cfg.Account.DeletionPolicy)
+	log.Printf("Article.ScheduleInterval: %v", cfg.Article.ScheduleInterval)
+	log.Printf("MFA.Issuer: %s", cfg.MFA.Issuer)
+	log.Printf("LoginProtection.Enabled: %v", cfg.LoginProtection.Enabled)
 	log.Println("============================")
 
-	// 2. 链接数据库
-	if err := gorm.InitDB(); err != nil {
-		log.Fatalf("initDB failed: %v", err)
+	switch cfg.Account.DeletionPolicy {
+	case "", service.DeletionPolicyAnonymize, service.DeletionPolicyDelete:
+	default:
+		log.Fatalf("unknown account.deletion_policy %q, use anonymize or delete", cfg.Account.DeletionPolicy)
 	}
-	db := gorm.GetDB()
-	err := gorm.AutoMigrate()
+
+	// 2. 链接数据库，初始化 repo
+	repos, err := newRepos(cfg.Database)
 	if err != nil {
-		log.Fatalf("initDB AutoMigrate failed: %v", err)
+		log.Fatalf("init repos failed: %v", err)
+	}
+	// 每个请求都要检查会话是否撤销，加一层进程内缓存；所有 service 共用同一个实例，本进程内的撤销立即生效
+	if cfg.JWT.SessionCacheTTL > 0 {
+		repos.session = cache.NewSessionRepo(repos.session, cfg.JWT.SessionCacheTTL)
 	}
 
 	// 3. 参数注入service
-	jwtMgr := jwt.NewManager(
-		cfg.JWT.Secret,
-		cfg.JWT.ExpireTime,
-	)
+	jwtMgr, err := newJWTManager(cfg.JWT)
+	if err != nil {
+		log.Fatalf("init jwt failed: %v", err)
+	}
 
-	userRepo := gorm.NewUserRepo(db)
-	userService := service.NewUserService(userRepo, jwtMgr)
-	articleRepo := gorm.NewArticleRepo(db)
-	articleService := service.NewArticleService(articleRepo, userRepo)
-	commentRepo := gorm.NewCommentRepo(db)
-	commentService := service.NewCommentService(commentRepo, articleRepo, userRepo)
+	hasher, err := password.NewHasher(password.Config{
+		Algorithm: cfg.Password.Algorithm,
+		Argon2: password.Argon2Params{
+			Memory:      cfg.Password.Argon2.Memory,
+			Iterations:  cfg.Password.Argon2.Iterations,
+			Parallelism: cfg.Password.Argon2.Parallelism,
+			SaltLength:  cfg.Password.Argon2.SaltLength,
+			KeyLength:   cfg.Password.Argon2.KeyLength,
+		},
+		BcryptCost: cfg.Password.Bcrypt.Cost,
+	})
+	if err != nil {
+		log.Fatalf("init password hasher failed: %v", err)
+	}
+
+	mail, err := mailer.New(mailer.Config{
+		Driver:       cfg.Mail.Driver,
+		From:         cfg.Mail.From,
+		SMTPHost:     cfg.Mail.SMTP.Host,
+		SMTPPort:     cfg.Mail.SMTP.Port,
+		SMTPUsername: cfg.Mail.SMTP.Username,
+		SMTPPassword: cfg.Mail.SMTP.Password,
+		FileDir:      cfg.Mail.File.Dir,
+	})
+	if err != nil {
+		log.Fatalf("init mailer failed: %v", err)
+	}
+
+	accountService := service.NewAccountService(repos.user, repos.session, repos.personalAccessToken, repos.oneTimeToken, hasher, mail, service.AccountConfig{
+		LinkBaseURL:          cfg.Account.LinkBaseURL,
+		PasswordResetTTL:     cfg.Account.PasswordResetTTL,
+		EmailVerificationTTL: cfg.Account.EmailVerificationTTL,
+		RequireVerifiedEmail: cfg.Account.RequireVerifiedEmail,
+		MagicLinkTTL:         cfg.Account.MagicLinkTTL,
+		MagicLinkRateLimit:   cfg.Account.MagicLinkRateLimit,
+		MagicLinkRateWindow:  cfg.Account.MagicLinkRateWindow,
+	})
+	mfaBox, err := secretbox.New(cfg.MFA.EncryptionKey)
+	if err != nil {
+		log.Fatalf("init mfa encryption failed: %v", err)
+	}
+	mfaService := service.NewMFAService(repos.mfa, repos.user, jwtMgr, hasher, mfaBox, service.MFAConfig{
+		Issuer:     cfg.MFA.Issuer,
+		PendingTTL: cfg.MFA.PendingTTL,
+	})
+	loginProtection := newLoginProtection(cfg.LoginProtection, repos.loginAttempt)
+	userService := service.NewUserService(repos.user, repos.session, jwtMgr, hasher, cfg.JWT.RefreshExpireTime, accountService, mfaService, loginProtection, service.UserConfig{
+		DeletionPolicy: cfg.Account.DeletionPolicy,
+	})
+	policy := service.NewPolicy(repos.user, repos.role)
+	roleService := service.NewRoleService(repos.role, repos.user)
+	if cfg.Database.Driver == memory.Driver {
+		// 内存模式没法从命令行 roles seed，启动时写入默认角色
+		if _, err := roleService.Seed(context.Background()); err != nil {
+			log.Fatalf("seed roles failed: %v", err)
+		}
+	}
+	slugs, err := slug.New(slug.Options{
+		MaxLength: cfg.Article.SlugMaxLength,
+		Pinyin:    cfg.Article.SlugPinyin,
+	})
+	if err != nil {
+		log.Fatalf("invalid article.slug_max_length: %v", err)
+	}
+	articleService := service.NewArticleService(repos.article, repos.user, policy, slugs, service.ArticleConfig{
+		HideSuspendedAuthors: cfg.Admin.HideSuspendedContent,
+	})
+	if cfg.Article.ScheduleInterval > 0 {
+		scheduler := service.NewArticleScheduler(repos.article, service.ArticleSchedulerConfig{
+			Interval: cfg.Article.ScheduleInterval,
+		})
+		go scheduler.Run(context.Background())
+	}
+	commentService := service.NewCommentService(repos.comment, repos.article, repos.user, policy)
+	oidcService := service.NewOIDCService(repos.identity, repos.user, repos.session, jwtMgr, cfg.JWT.RefreshExpireTime, accountService, mfaService, oidcConfig(cfg.OIDC))
+	tokenService := service.NewTokenService(repos.personalAccessToken)
+	sessionService := service.NewSessionService(repos.session, cfg.JWT.RefreshExpireTime)
+	exportService := service.NewExportService(repos.user, repos.article, repos.comment, repos.dataExport, service.ExportConfig{
+		SyncLimit: cfg.Account.ExportSyncLimit,
+		TTL:       cfg.Account.ExportTTL,
+	})
+	adminService := service.NewAdminService(repos.user, repos.session, jwtMgr, accountService, loginProtection, policy)
+	authenticator := service.NewAuthenticator(jwtMgr, repos.personalAccessToken, repos.session, repos.user)
 
 	// 4. 注册路由和中间件
-	r := router.NewRouter(userService, articleService, commentService, jwtMgr)
+	r := router.NewRouter(userService, accountService, mfaService, oidcService, articleService, commentService, tokenService, sessionService, exportService, roleService, adminService, policy, authenticator, jwtMgr)
+	if err := r.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		log.Fatalf("invalid server.trusted_proxies: %v", err)
+	}
 
 	r.Run(cfg.Server.Addr)
 }
+
+// repos 汇总所有 repo 实现
+type repos struct {
+	user    repository.UserRepo
+	article repository.ArticleRepo
+	comment repository.CommentRepo
+	session repository.SessionRepo
+
+	oneTimeToken repository.OneTimeTokenRepo
+	mfa          repository.MFARepo
+	loginAttempt repository.LoginAttemptRepo
+
+	personalAccessToken repository.PersonalAccessTokenRepo
+	identity            repository.IdentityRepo
+
+	role repository.RoleRepo
+
+	dataExport repository.DataExportRepo
+}
+
+// newRepos 根据 database.driver 选择 repo 实现：
+// memory 不连接数据库，数据只保存在进程内；其余 driver 走 gorm
+func newRepos(cfg config.DatabaseConfig) (*repos, error) {
+	if cfg.Driver == memory.Driver {
+		store := memory.NewStore()
+		return &repos{
+			user:    memory.NewUserRepo(store),
+			article: memory.NewArticleRepo(store),
+			comment: memory.NewCommentRepo(store),
+			session: memory.NewSessionRepo(store),
+
+			oneTimeToken: memory.NewOneTimeTokenRepo(store),
+			mfa:          memory.NewMFARepo(store),
+			loginAttempt: memory.NewLoginAttemptRepo(store),
+
+			personalAccessToken: memory.NewPersonalAccessTokenRepo(store),
+			identity:            memory.NewIdentityRepo(store),
+
+			role: memory.NewRoleRepo(store),
+
+			dataExport: memory.NewDataExportRepo(store),
+		}, nil
+	}
+
+	if err := gorm.InitDB(); err != nil {
+		return nil, err
+	}
+	db := gorm.GetDB()
+	if cfg.AutoMigrate {
+		if err := migrateUp(); err != nil {
+			return nil, err
+		}
+	}
+	return &repos{
+		user:    gorm.NewUserRepo(db),
+		article: gorm.NewArticleRepo(db),
+		comment: gorm.NewCommentRepo(db),
+		session: gorm.NewSessionRepo(db),
+
+		oneTimeToken: gorm.NewOneTimeTokenRepo(db),
+		mfa:          gorm.NewMFARepo(db),
+		loginAttempt: gorm.NewLoginAttemptRepo(db),
+
+		personalAccessToken: gorm.NewPersonalAccessTokenRepo(db),
+		identity:            gorm.NewIdentityRepo(db),
+
+		role: gorm.NewRoleRepo(db),
+
+		dataExport: gorm.NewDataExportRepo(db),
+	}, nil
+}
+
+// newLoginProtection 默认把失败计数存在数据库里（多副本共享），login_protection.store 为 memory 时只在进程内计数
+func newLoginProtection(cfg config.LoginProtectionConfig, repo repository.LoginAttemptRepo) service.LoginProtection {
+	if cfg.Store == memory.Driver {
+		repo = memory.NewLoginAttemptRepo(memory.NewStore())
+	}
+	return service.NewLoginProtection(repo, loginProtectionConfig(cfg))
+}
+
+func loginProtectionConfig(cfg config.LoginProtectionConfig) service.LoginProtectionConfig {
+	return service.LoginProtectionConfig{
+		Enabled:        cfg.Enabled,
+		FreeAttempts:   cfg.FreeAttempts,
+		IPFreeAttempts: cfg.IPFreeAttempts,
+		BaseDelay:      cfg.BaseDelay,
+		MaxDelay:       cfg.MaxDelay,
+		LockThreshold:  cfg.LockThreshold,
+		LockDuration:   cfg.LockDuration,
+		Window:         cfg.Window,
+	}
+}
+
+// oidcConfig 按 provider 名排序，保证 GET /api/users/oidc 的返回顺序稳定
+func oidcConfig(cfg config.OIDCConfig) service.OIDCConfig {
+	names := make([]string, 0, len(cfg.Providers))
+	for name := range cfg.Providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	out := service.OIDCConfig{StateTTL: cfg.StateTTL}
+	for _, name := range names {
+		p := cfg.Providers[name]
+		displayName := p.DisplayName
+		if displayName == "" {
+			displayName = name
+		}
+		out.Providers = append(out.Providers, service.OIDCProviderConfig{
+			Name:        name,
+			DisplayName: displayName,
+			Config: oidc.Config{
+				Issuer:       p.Issuer,
+				ClientID:     p.ClientID,
+				ClientSecret: p.ClientSecret,
+				RedirectURL:  p.RedirectURL,
+				Scopes:       p.Scopes,
+			},
+		})
+	}
+	return out
+}
+
+// migrateUp 执行所有未执行的迁移
+func migrateUp() error {
+	migrator, err := gorm.NewMigrator(gorm.GetDB())
+	if err != nil {
+		return err
+	}
+	applied, err := migrator.Up(context.Background())
+	for _, m := range applied {
+		log.Printf("migration applied: %04d_%s", m.Version, m.Name)
+	}
+	return err
+}
+
+// newJWTManager 未配置密钥时使用 HS256，否则加载 PEM 密钥做非对称签名
+func newJWTManager(cfg config.JWTConfig) (jwt.Manager, error) {
+	if len(cfg.Keys) == 0 {
+		return jwt.NewManager(cfg.Secret, cfg.ExpireTime), nil
+	}
+
+	var (
+		signing *jwt.Key
+		verify  []*jwt.Key
+	)
+	for _, kc := range cfg.Keys {
+		key, err := jwt.LoadKey(kc.ID, kc.PrivateKeyFile, kc.PublicKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		if key.ID == cfg.SigningKeyID {
+			signing = key
+		} else {
+			verify = append(verify, key)
+		}
+	}
+	if signing == nil {
+		return nil, fmt.Errorf("jwt signing key %q not found in jwt.keys", cfg.SigningKeyID)
+	}
+	return jwt.NewKeyManager(signing, verify, cfg.ExpireTime)
+}
@@ -0,0 +1,149 @@
+package api
+
+import (
+	"errors"
+	"github/CiroLong/realworld-gin/internal/middleware"
+	"github/CiroLong/realworld-gin/internal/model/dto"
+	"github/CiroLong/realworld-gin/internal/pkg/common"
+	"github/CiroLong/realworld-gin/internal/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AccountHandler struct {
+	accountService service.AccountService
+}
+
+func NewAccountHandler(accountService service.AccountService) *AccountHandler {
+	return &AccountHandler{
+		accountService: accountService,
+	}
+}
+
+// ForgotPassword
+// 发送密码重置邮件，无论邮箱是否注册都返回 202
+// POST /api/users/password/forgot
+func (h *AccountHandler) ForgotPassword(c *gin.Context) {
+	// 1. 处理请求
+	var req dto.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"errors": gin.H{"body": []string{err.Error()}},
+		})
+		return
+	}
+
+	// 2. 调service发信
+	if err := h.accountService.ForgotPassword(c.Request.Context(), &req); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"errors": gin.H{"body": []string{err.Error()}},
+		})
+		return
+	}
+
+	c.Status(http.StatusAccepted)
+}
+
+// SendMagicLink
+// 发送免密登录链接，无论邮箱是否注册都返回 202
+// POST /api/users/magic-link
+func (h *AccountHandler) SendMagicLink(c *gin.Context) {
+	// 1. 处理请求
+	var req dto.MagicLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"errors": gin.H{"body": []string{err.Error()}},
+		})
+		return
+	}
+
+	// 2. 调service发信
+	if err := h.accountService.SendMagicLink(c.Request.Context(), &req); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"errors": gin.H{"body": []string{err.Error()}},
+		})
+		return
+	}
+
+	c.Status(http.StatusAccepted)
+}
+
+// ResetPassword
+// 用邮件中的令牌设置新密码，成功后所有已登录的会话失效
+// POST /api/users/password/reset
+func (h *AccountHandler) ResetPassword(c *gin.Context) {
+	// 1. 处理请求
+	var req dto.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"errors": gin.H{"body": []string{err.Error()}},
+		})
+		return
+	}
+
+	// 2. 调service重置
+	if err := h.accountService.ResetPassword(c.Request.Context(), &req); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, common.ErrInvalidToken) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{
+			"errors": gin.H{"body": []string{err.Error()}},
+		})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// VerifyEmail
+// 用邮件中的令牌完成邮箱验证
+// POST /api/users/verify
+func (h *AccountHandler) VerifyEmail(c *gin.Context) {
+	// 1. 处理请求
+	var req dto.VerifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"errors": gin.H{"body": []string{err.Error()}},
+		})
+		return
+	}
+
+	// 2. 调service验证
+	if err := h.accountService.VerifyEmail(c.Request.Context(), &req); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, common.ErrInvalidToken) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{
+			"errors": gin.H{"body": []string{err.Error()}},
+		})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ResendVerification
+// Auth needed
+// 重新发送验证邮件，之前的链接作废
+// POST /api/user/verify/resend
+func (h *AccountHandler) ResendVerification(c *gin.Context) {
+	uidVal, exists := c.Get(middleware.ContextUserIDKey)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"errors": gin.H{"body": []string{"unauthorized"}},
+		})
+		return
+	}
+
+	if err := h.accountService.SendVerificationEmail(c.Request.Context(), uidVal.(int64)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"errors": gin.H{"body": []string{err.Error()}},
+		})
+		return
+	}
+
+	c.Status(http.StatusAccepted)
+}
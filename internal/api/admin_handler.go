@@ -0,0 +1,250 @@
+package api
+
+import (
+	"errors"
+	"github/CiroLong/realworld-gin/internal/middleware"
+	"github/CiroLong/realworld-gin/internal/model/dto"
+	"github/CiroLong/realworld-gin/internal/pkg/common"
+	"github/CiroLong/realworld-gin/internal/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 管理接口，需要登录会话且角色拥有 users:manage 权限
+
+type AdminHandler struct {
+	roleService  service.RoleService
+	adminService service.AdminService
+}
+
+func NewAdminHandler(roleService service.RoleService, adminService service.AdminService) *AdminHandler {
+	return &AdminHandler{
+		roleService:  roleService,
+		adminService: adminService,
+	}
+}
+
+// ListRoles
+// GET /api/admin/roles
+func (h *AdminHandler) ListRoles(c *gin.Context) {
+	resp, err := h.roleService.List(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"errors": gin.H{"body": []string{err.Error()}},
+		})
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// ListUsers
+// q 按邮箱 / 用户名模糊搜索，suspended=true|false 按停用状态过滤
+// GET /api/admin/users?q=&suspended=&limit=&offset=
+func (h *AdminHandler) ListUsers(c *gin.Context) {
+	var suspended *bool
+	if v := c.Query("suspended"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"errors": gin.H{"body": []string{"invalid suspended filter"}},
+			})
+			return
+		}
+		suspended = &b
+	}
+	limit, offset, ok := userPageParams(c)
+	if !ok {
+		return
+	}
+
+	resp, err := h.adminService.ListUsers(c.Request.Context(), c.Query("q"), suspended, limit, offset)
+	if err != nil {
+		adminError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetUser
+// GET /api/admin/users/:id
+func (h *AdminHandler) GetUser(c *gin.Context) {
+	userID, ok := userIDParam(c)
+	if !ok {
+		return
+	}
+
+	resp, err := h.adminService.GetUser(c.Request.Context(), userID)
+	if err != nil {
+		adminError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// UpdateUser
+// PUT /api/admin/users/:id
+func (h *AdminHandler) UpdateUser(c *gin.Context) {
+	userID, ok := userIDParam(c)
+	if !ok {
+		return
+	}
+	var req dto.AdminUpdateUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"errors": gin.H{"body": []string{err.Error()}},
+		})
+		return
+	}
+
+	resp, err := h.adminService.UpdateUser(c.Request.Context(), c.GetInt64(middleware.ContextUserIDKey), userID, &req)
+	if err != nil {
+		adminError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// SuspendUser
+// 停用账号，该用户所有会话和个人访问令牌随即失效
+// POST /api/admin/users/:id/suspend
+func (h *AdminHandler) SuspendUser(c *gin.Context) {
+	userID, ok := userIDParam(c)
+	if !ok {
+		return
+	}
+	var req dto.SuspendUserRequest
+	// 请求体可以为空
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{
+				"errors": gin.H{"body": []string{err.Error()}},
+			})
+			return
+		}
+	}
+
+	resp, err := h.adminService.Suspend(c.Request.Context(), c.GetInt64(middleware.ContextUserIDKey), userID, &req)
+	if err != nil {
+		adminError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// UnsuspendUser
+// DELETE /api/admin/users/:id/suspend
+func (h *AdminHandler) UnsuspendUser(c *gin.Context) {
+	userID, ok := userIDParam(c)
+	if !ok {
+		return
+	}
+
+	resp, err := h.adminService.Unsuspend(c.Request.Context(), c.GetInt64(middleware.ContextUserIDKey), userID)
+	if err != nil {
+		adminError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// ForcePasswordReset
+// 原密码立即失效、所有会话被撤销，用户需要通过邮件重新设置密码
+// POST /api/admin/users/:id/password-reset
+func (h *AdminHandler) ForcePasswordReset(c *gin.Context) {
+	userID, ok := userIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.adminService.ForcePasswordReset(c.Request.Context(), c.GetInt64(middleware.ContextUserIDKey), userID); err != nil {
+		adminError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// UnlockUser
+// POST /api/admin/users/:id/unlock
+func (h *AdminHandler) UnlockUser(c *gin.Context) {
+	userID, ok := userIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.adminService.Unlock(c.Request.Context(), c.GetInt64(middleware.ContextUserIDKey), userID); err != nil {
+		adminError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// Impersonate
+// 返回该用户的 access token（不能刷新），用于排查用户反馈的问题
+// POST /api/admin/users/:id/impersonate
+func (h *AdminHandler) Impersonate(c *gin.Context) {
+	userID, ok := userIDParam(c)
+	if !ok {
+		return
+	}
+
+	resp, err := h.adminService.Impersonate(c.Request.Context(), c.GetInt64(middleware.ContextUserIDKey), userID, clientMeta(c))
+	if err != nil {
+		adminError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// 用户列表每页条数：不传或无法解析时取默认值，超出范围的截到 [1, maxUserPageLimit]
+const (
+	defaultUserPageLimit = 20
+	maxUserPageLimit     = 100
+)
+
+// userPageParams 解析用户列表的 limit / offset，offset 为负数时已写入 400
+func userPageParams(c *gin.Context) (limit int, offset int, ok bool) {
+	limit, err := strconv.Atoi(c.Query("limit"))
+	if err != nil {
+		limit = defaultUserPageLimit
+	}
+	limit = min(max(limit, 1), maxUserPageLimit)
+
+	offset, err = strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"errors": gin.H{"body": []string{"invalid offset"}},
+		})
+		return 0, 0, false
+	}
+	return limit, offset, true
+}
+
+// userIDParam 解析路径中的用户 id，失败时已写入 400
+func userIDParam(c *gin.Context) (int64, bool) {
+	userID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"errors": gin.H{"body": []string{"invalid user id"}},
+		})
+		return 0, false
+	}
+	return userID, true
+}
+
+// adminError 把 service 层错误映射为 HTTP 状态码
+func adminError(c *gin.Context, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, common.ErrUserNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, common.ErrUserAlreadyExist):
+		status = http.StatusConflict
+	case errors.Is(err, common.ErrAdminTarget), errors.Is(err, common.ErrAccountSuspended):
+		status = http.StatusForbidden
+	}
+	c.JSON(status, gin.H{
+		"errors": gin.H{"body": []string{err.Error()}},
+	})
+}
@@ -45,14 +45,24 @@ func (h *ArticleHandler) CreateArticle(c *gin.Context) {
 	// 3. 调用 Service
 	articleResp, err := h.articleService.CreateArticle(c.Request.Context(), userID.(int64), &req)
 	if err != nil {
+		if isScheduleError(err) || isInvalidArticleError(err) {
+			c.JSON(http.StatusUnprocessableEntity, errError(err))
+			return
+		}
+		if errors.Is(err, common.ErrSlugTaken) {
+			c.JSON(http.StatusConflict, errError(err))
+			return
+		}
 		c.JSON(http.StatusInternalServerError, errError(err))
 		return
 	}
 
+	setETag(c, articleResp.Article.Version)
 	c.JSON(http.StatusCreated, articleResp)
 }
 
 // GetArticle
+// Authentication optional
 // GET /api/articles/:slug
 func (h *ArticleHandler) GetArticle(c *gin.Context) {
 	// 1. 绑定参数 slug
@@ -62,8 +72,14 @@ func (h *ArticleHandler) GetArticle(c *gin.Context) {
 		return
 	}
 
+	// 草稿只有作者本人能看到，需要知道是谁在请求
+	var userID int64
+	if uid, exists := c.Get(middleware.ContextUserIDKey); exists {
+		userID = uid.(int64)
+	}
+
 	// 2. 调用service
-	resp, err := h.articleService.GetArticle(c.Request.Context(), slug)
+	resp, err := h.articleService.GetArticle(c.Request.Context(), slug, userID)
 	if err != nil {
 		if errors.Is(err, common.ErrNotFound) {
 			c.JSON(http.StatusNotFound, errError(err))
@@ -74,6 +90,7 @@ func (h *ArticleHandler) GetArticle(c *gin.Context) {
 		return
 	}
 
+	setETag(c, resp.Article.Version)
 	c.JSON(http.StatusOK, resp)
 }
 
@@ -93,6 +110,11 @@ func (h *ArticleHandler) UpdateArticle(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, errError(err))
 		return
 	}
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		c.JSON(http.StatusPreconditionFailed, errError(err))
+		return
+	}
 
 	// 2. 取userID
 	userID, exists := c.Get(middleware.ContextUserIDKey)
@@ -102,12 +124,24 @@ func (h *ArticleHandler) UpdateArticle(c *gin.Context) {
 	}
 
 	// 3. 调用service
-	resp, err := h.articleService.UpdateArticle(c.Request.Context(), slug, userID.(int64), &req)
+	resp, err := h.articleService.UpdateArticle(c.Request.Context(), slug, userID.(int64), version, &req)
 	if err != nil {
 		if errors.Is(err, common.ErrNotFound) {
 			c.JSON(http.StatusNotFound, errError(err))
 			return
 		}
+		if errors.Is(err, common.ErrVersionMismatch) {
+			c.JSON(http.StatusPreconditionFailed, errError(err))
+			return
+		}
+		if isScheduleError(err) || isInvalidArticleError(err) {
+			c.JSON(http.StatusUnprocessableEntity, errError(err))
+			return
+		}
+		if errors.Is(err, common.ErrSlugTaken) {
+			c.JSON(http.StatusConflict, errError(err))
+			return
+		}
 		if errors.Is(err, common.ErrPermissionDenied) {
 			c.JSON(http.StatusForbidden, errError(err))
 			return
@@ -116,6 +150,7 @@ func (h *ArticleHandler) UpdateArticle(c *gin.Context) {
 		return
 	}
 
+	setETag(c, resp.Article.Version)
 	c.JSON(http.StatusOK, resp)
 }
 
@@ -135,12 +170,22 @@ func (h *ArticleHandler) DeleteArticle(c *gin.Context) {
 		return
 	}
 
-	err := h.articleService.DeleteArticle(c.Request.Context(), slug, userID.(int64))
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		c.JSON(http.StatusPreconditionFailed, errError(err))
+		return
+	}
+
+	err = h.articleService.DeleteArticle(c.Request.Context(), slug, userID.(int64), version)
 	if err != nil {
 		if errors.Is(err, common.ErrNotFound) {
 			c.JSON(http.StatusNotFound, errError(err))
 			return
 		}
+		if errors.Is(err, common.ErrVersionMismatch) {
+			c.JSON(http.StatusPreconditionFailed, errError(err))
+			return
+		}
 		if errors.Is(err, common.ErrPermissionDenied) {
 			c.JSON(http.StatusForbidden, errError(err))
 			return
@@ -152,6 +197,45 @@ func (h *ArticleHandler) DeleteArticle(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{})
 }
 
+// PublishArticle
+// Authentication required
+// POST /api/articles/:slug/publish
+func (h *ArticleHandler) PublishArticle(c *gin.Context) {
+	slug := c.Param("slug")
+	if slug == "" {
+		c.JSON(http.StatusBadRequest, errString("slug cannot be empty"))
+		return
+	}
+
+	userID, exists := c.Get(middleware.ContextUserIDKey)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, errString("unauthorized"))
+		return
+	}
+
+	resp, err := h.articleService.PublishArticle(c.Request.Context(), slug, userID.(int64))
+	if err != nil {
+		if errors.Is(err, common.ErrNotFound) {
+			c.JSON(http.StatusNotFound, errError(err))
+			return
+		}
+		// 读到之后文章又被别人改了
+		if errors.Is(err, common.ErrVersionMismatch) {
+			c.JSON(http.StatusConflict, errError(err))
+			return
+		}
+		if errors.Is(err, common.ErrPermissionDenied) {
+			c.JSON(http.StatusForbidden, errError(err))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, errError(err))
+		return
+	}
+
+	setETag(c, resp.Article.Version)
+	c.JSON(http.StatusOK, resp)
+}
+
 // FavoriteArticle
 // Authentication required
 // POST /api/articles/:slug/favorite
@@ -178,6 +262,7 @@ func (h *ArticleHandler) FavoriteArticle(c *gin.Context) {
 		return
 	}
 
+	setETag(c, resp.Article.Version)
 	c.JSON(http.StatusOK, resp)
 }
 
@@ -207,16 +292,18 @@ func (h *ArticleHandler) UnfavoriteArticle(c *gin.Context) {
 		return
 	}
 
+	setETag(c, resp.Article.Version)
 	c.JSON(http.StatusOK, resp)
 }
 
 // ListArticles
 // Authentication optional
-// GET /api/articles?tag=&author=&favorited=&limit=&offset=
+// GET /api/articles?tag=&author=&favorited=&status=&limit=&offset=
 func (h *ArticleHandler) ListArticles(c *gin.Context) {
 	tag := c.Query("tag")
 	author := c.Query("author")
 	favorited := c.Query("favorited")
+	status := c.Query("status")
 
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
 	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
@@ -226,8 +313,17 @@ func (h *ArticleHandler) ListArticles(c *gin.Context) {
 		userID = uid.(int64)
 	}
 
-	resp, err := h.articleService.ListArticles(c.Request.Context(), tag, author, favorited, userID, limit, offset)
+	resp, err := h.articleService.ListArticles(c.Request.Context(), tag, author, favorited, status, userID, limit, offset)
 	if err != nil {
+		if errors.Is(err, common.ErrInvalidArticleStatus) {
+			c.JSON(http.StatusBadRequest, errError(err))
+			return
+		}
+		// 未登录时不能列出未发布的文章
+		if errors.Is(err, common.ErrPermissionDenied) {
+			c.JSON(http.StatusUnauthorized, errString("authentication required to list unpublished articles"))
+			return
+		}
 		c.JSON(http.StatusInternalServerError, errError(err))
 		return
 	}
@@ -257,6 +353,28 @@ func (h *ArticleHandler) FeedArticles(c *gin.Context) {
 	c.JSON(http.StatusOK, resp)
 }
 
+// ScheduledArticles
+// Authentication required
+// GET /api/articles/scheduled?limit=&offset=
+func (h *ArticleHandler) ScheduledArticles(c *gin.Context) {
+	userID, exists := c.Get(middleware.ContextUserIDKey)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, errString("unauthorized"))
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	resp, err := h.articleService.ScheduledArticles(c.Request.Context(), userID.(int64), limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errError(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
 // GetTags
 // GET /api/tags
 func (h *ArticleHandler) GetTags(c *gin.Context) {
@@ -268,3 +386,138 @@ func (h *ArticleHandler) GetTags(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"tags": tags})
 }
+
+// ListRevisions
+// Authentication required
+// GET /api/articles/:slug/revisions?limit=&offset=
+func (h *ArticleHandler) ListRevisions(c *gin.Context) {
+	userID, exists := c.Get(middleware.ContextUserIDKey)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, errString("unauthorized"))
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	resp, err := h.articleService.ListRevisions(c.Request.Context(), c.Param("slug"), userID.(int64), limit, offset)
+	if err != nil {
+		revisionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetRevision
+// Authentication required
+// GET /api/articles/:slug/revisions/:id
+func (h *ArticleHandler) GetRevision(c *gin.Context) {
+	userID, exists := c.Get(middleware.ContextUserIDKey)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, errString("unauthorized"))
+		return
+	}
+	revisionID, ok := revisionIDParam(c, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	resp, err := h.articleService.GetRevision(c.Request.Context(), c.Param("slug"), userID.(int64), revisionID)
+	if err != nil {
+		revisionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// DiffRevisions
+// Authentication required
+// GET /api/articles/:slug/revisions/:id/diff?from= - from 缺省时和上一条修订比较
+func (h *ArticleHandler) DiffRevisions(c *gin.Context) {
+	userID, exists := c.Get(middleware.ContextUserIDKey)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, errString("unauthorized"))
+		return
+	}
+	toID, ok := revisionIDParam(c, c.Param("id"))
+	if !ok {
+		return
+	}
+	var fromID int64
+	if from := c.Query("from"); from != "" {
+		if fromID, ok = revisionIDParam(c, from); !ok {
+			return
+		}
+	}
+
+	resp, err := h.articleService.DiffRevisions(c.Request.Context(), c.Param("slug"), userID.(int64), fromID, toID)
+	if err != nil {
+		revisionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// RestoreRevision
+// Authentication required
+// POST /api/articles/:slug/revisions/:id/restore
+func (h *ArticleHandler) RestoreRevision(c *gin.Context) {
+	userID, exists := c.Get(middleware.ContextUserIDKey)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, errString("unauthorized"))
+		return
+	}
+	revisionID, ok := revisionIDParam(c, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	resp, err := h.articleService.RestoreRevision(c.Request.Context(), c.Param("slug"), userID.(int64), revisionID)
+	if err != nil {
+		revisionError(c, err)
+		return
+	}
+
+	setETag(c, resp.Article.Version)
+	c.JSON(http.StatusOK, resp)
+}
+
+func revisionIDParam(c *gin.Context, value string) (int64, bool) {
+	id, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, errString("invalid revision id"))
+		return 0, false
+	}
+	return id, true
+}
+
+// revisionError 把 service 层错误映射为 HTTP 状态码
+func revisionError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, common.ErrNotFound):
+		c.JSON(http.StatusNotFound, errError(err))
+	case errors.Is(err, common.ErrPermissionDenied):
+		c.JSON(http.StatusForbidden, errError(err))
+	case errors.Is(err, common.ErrVersionMismatch), errors.Is(err, common.ErrSlugTaken):
+		c.JSON(http.StatusConflict, errError(err))
+	default:
+		c.JSON(http.StatusInternalServerError, errError(err))
+	}
+}
+
+// isInvalidArticleError slug 或标签不合法
+func isInvalidArticleError(err error) bool {
+	return errors.Is(err, common.ErrInvalidSlug) ||
+		errors.Is(err, common.ErrInvalidTag) ||
+		errors.Is(err, common.ErrTooManyTags)
+}
+
+// isScheduleError 定时发布参数不合法
+func isScheduleError(err error) bool {
+	return errors.Is(err, common.ErrPublishAtInPast) ||
+		errors.Is(err, common.ErrPublishAtWithStatus) ||
+		errors.Is(err, common.ErrAlreadyPublished)
+}
@@ -0,0 +1,16 @@
+package api
+
+import (
+	"github/CiroLong/realworld-gin/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// clientMeta 取客户端 IP 和 User-Agent
+// IP 来自 gin 的 ClientIP，只有 server.trusted_proxies 中的代理转发的 X-Forwarded-For 才会被采信
+func clientMeta(c *gin.Context) service.ClientMeta {
+	return service.ClientMeta{
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	}
+}
@@ -0,0 +1,35 @@
+package api
+
+import (
+	"github/CiroLong/realworld-gin/internal/pkg/common"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 乐观锁：响应头 ETag 是资源的版本号，客户端修改 / 删除时通过 If-Match 带回，
+// 期间资源被别人改过时返回 412 Precondition Failed，客户端重新拉取后再提交
+
+// setETag 设置强 ETag，如 "3"
+func setETag(c *gin.Context, version int64) {
+	c.Header("ETag", strconv.Quote(strconv.FormatInt(version, 10)))
+}
+
+// ifMatchVersion 解析 If-Match 请求头，没有带或为 * 时返回 0（不检查版本）
+// If-Match 要求强比较，弱 ETag（W/"3"）、多个 ETag 或不是本服务签发的值都当作不匹配
+func ifMatchVersion(c *gin.Context) (int64, error) {
+	value := strings.TrimSpace(c.GetHeader("If-Match"))
+	if value == "" || value == "*" {
+		return 0, nil
+	}
+
+	if len(value) < 2 || !strings.HasPrefix(value, `"`) || !strings.HasSuffix(value, `"`) {
+		return 0, common.ErrVersionMismatch
+	}
+	version, err := strconv.ParseInt(value[1:len(value)-1], 10, 64)
+	if err != nil || version <= 0 {
+		return 0, common.ErrVersionMismatch
+	}
+	return version, nil
+}
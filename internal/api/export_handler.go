@@ -0,0 +1,83 @@
+package api
+
+import (
+	"errors"
+	"github/CiroLong/realworld-gin/internal/middleware"
+	"github/CiroLong/realworld-gin/internal/pkg/common"
+	"github/CiroLong/realworld-gin/internal/service"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 个人数据导出，只能用登录会话访问
+
+type ExportHandler struct {
+	exportService service.ExportService
+}
+
+func NewExportHandler(exportService service.ExportService) *ExportHandler {
+	return &ExportHandler{
+		exportService: exportService,
+	}
+}
+
+// Export
+// 数据量小时直接返回 ZIP，否则返回 202 和后台任务，之后轮询 Location
+// GET /api/user/export
+func (h *ExportHandler) Export(c *gin.Context) {
+	result, err := h.exportService.Export(c.Request.Context(), c.GetInt64(middleware.ContextUserIDKey))
+	if err != nil {
+		exportError(c, err)
+		return
+	}
+	writeExport(c, result)
+}
+
+// GetExport
+// GET /api/user/export/:id
+func (h *ExportHandler) GetExport(c *gin.Context) {
+	exportID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"errors": gin.H{"body": []string{"invalid export id"}},
+		})
+		return
+	}
+
+	result, err := h.exportService.Get(c.Request.Context(), c.GetInt64(middleware.ContextUserIDKey), exportID)
+	if err != nil {
+		exportError(c, err)
+		return
+	}
+	writeExport(c, result)
+}
+
+// writeExport 生成好的返回 ZIP 附件，进行中的返回 202，失败的返回 200 和错误信息
+func writeExport(c *gin.Context, result *service.DataExportResult) {
+	if result.Archive != nil {
+		c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": result.Filename}))
+		c.Data(http.StatusOK, "application/zip", result.Archive)
+		return
+	}
+
+	status := http.StatusOK
+	if result.Pending {
+		status = http.StatusAccepted
+		c.Header("Location", "/api/user/export/"+strconv.FormatInt(result.Status.Export.ID, 10))
+	}
+	c.JSON(status, result.Status)
+}
+
+// exportError 把 service 层错误映射为 HTTP 状态码
+func exportError(c *gin.Context, err error) {
+	status := http.StatusInternalServerError
+	if errors.Is(err, common.ErrNotFound) {
+		status = http.StatusNotFound
+	}
+	c.JSON(status, gin.H{
+		"errors": gin.H{"body": []string{err.Error()}},
+	})
+}
@@ -0,0 +1,26 @@
+package api
+
+import (
+	"github/CiroLong/realworld-gin/internal/pkg/jwt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type JWKSHandler struct {
+	jwtMgr jwt.Manager
+}
+
+func NewJWKSHandler(jwtMgr jwt.Manager) *JWKSHandler {
+	return &JWKSHandler{
+		jwtMgr: jwtMgr,
+	}
+}
+
+// GetJWKS
+// 公开当前所有验签公钥，其他服务据此验证 RealWorld 签发的 token，无需共享密钥
+// GET /.well-known/jwks.json
+func (h *JWKSHandler) GetJWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.jwtMgr.JWKS())
+}
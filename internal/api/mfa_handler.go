@@ -0,0 +1,119 @@
+package api
+
+import (
+	"errors"
+	"github/CiroLong/realworld-gin/internal/middleware"
+	"github/CiroLong/realworld-gin/internal/model/dto"
+	"github/CiroLong/realworld-gin/internal/pkg/common"
+	"github/CiroLong/realworld-gin/internal/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 两步验证相关接口，全部需要认证
+
+type MFAHandler struct {
+	mfaService service.MFAService
+}
+
+func NewMFAHandler(mfaService service.MFAService) *MFAHandler {
+	return &MFAHandler{
+		mfaService: mfaService,
+	}
+}
+
+// GetStatus
+// GET /api/user/2fa
+func (h *MFAHandler) GetStatus(c *gin.Context) {
+	resp, err := h.mfaService.Status(c.Request.Context(), c.GetInt64(middleware.ContextUserIDKey))
+	if err != nil {
+		mfaError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// Enroll
+// 生成新密钥，客户端展示二维码（otpauthUri），用户扫码后调用 confirm
+// POST /api/user/2fa/enroll
+func (h *MFAHandler) Enroll(c *gin.Context) {
+	resp, err := h.mfaService.Enroll(c.Request.Context(), c.GetInt64(middleware.ContextUserIDKey))
+	if err != nil {
+		mfaError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// Confirm
+// POST /api/user/2fa/confirm
+func (h *MFAHandler) Confirm(c *gin.Context) {
+	var req dto.MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"errors": gin.H{"body": []string{err.Error()}},
+		})
+		return
+	}
+
+	resp, err := h.mfaService.Confirm(c.Request.Context(), c.GetInt64(middleware.ContextUserIDKey), &req)
+	if err != nil {
+		mfaError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// Disable
+// POST /api/user/2fa/disable
+func (h *MFAHandler) Disable(c *gin.Context) {
+	var req dto.MFADisableRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"errors": gin.H{"body": []string{err.Error()}},
+		})
+		return
+	}
+
+	if err := h.mfaService.Disable(c.Request.Context(), c.GetInt64(middleware.ContextUserIDKey), &req); err != nil {
+		mfaError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// RegenerateRecoveryCodes
+// POST /api/user/2fa/recovery-codes
+func (h *MFAHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	var req dto.MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"errors": gin.H{"body": []string{err.Error()}},
+		})
+		return
+	}
+
+	resp, err := h.mfaService.RegenerateRecoveryCodes(c.Request.Context(), c.GetInt64(middleware.ContextUserIDKey), &req)
+	if err != nil {
+		mfaError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// mfaError 把 service 层错误映射为 HTTP 状态码
+func mfaError(c *gin.Context, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, common.ErrMFAAlreadyEnabled):
+		status = http.StatusConflict
+	case errors.Is(err, common.ErrMFANotEnabled):
+		status = http.StatusBadRequest
+	case errors.Is(err, common.ErrInvalidMFACode), errors.Is(err, common.ErrInvalidPassword):
+		status = http.StatusUnprocessableEntity
+	}
+	c.JSON(status, gin.H{
+		"errors": gin.H{"body": []string{err.Error()}},
+	})
+}
@@ -0,0 +1,135 @@
+package api
+
+import (
+	"errors"
+	"github/CiroLong/realworld-gin/internal/middleware"
+	"github/CiroLong/realworld-gin/internal/model/dto"
+	"github/CiroLong/realworld-gin/internal/pkg/common"
+	"github/CiroLong/realworld-gin/internal/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 第三方登录（OIDC）和第三方账号绑定相关接口
+// 回调地址指向前端页面，前端把 code / state 提交给 callback 接口
+
+type OIDCHandler struct {
+	oidcService service.OIDCService
+}
+
+func NewOIDCHandler(oidcService service.OIDCService) *OIDCHandler {
+	return &OIDCHandler{
+		oidcService: oidcService,
+	}
+}
+
+// ListProviders
+// GET /api/users/oidc
+func (h *OIDCHandler) ListProviders(c *gin.Context) {
+	c.JSON(http.StatusOK, h.oidcService.Providers())
+}
+
+// AuthorizeLogin
+// 返回授权地址，前端跳转过去
+// GET /api/users/oidc/:provider
+func (h *OIDCHandler) AuthorizeLogin(c *gin.Context) {
+	resp, err := h.oidcService.Authorize(c.Request.Context(), c.Param("provider"), 0)
+	if err != nil {
+		oidcError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// LoginCallback
+// POST /api/users/oidc/:provider/callback
+func (h *OIDCHandler) LoginCallback(c *gin.Context) {
+	var req dto.OIDCCallbackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"errors": gin.H{"body": []string{err.Error()}},
+		})
+		return
+	}
+
+	resp, err := h.oidcService.Login(c.Request.Context(), c.Param("provider"), &req, clientMeta(c))
+	if err != nil {
+		oidcError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// ListIdentities
+// GET /api/user/identities
+func (h *OIDCHandler) ListIdentities(c *gin.Context) {
+	resp, err := h.oidcService.ListIdentities(c.Request.Context(), c.GetInt64(middleware.ContextUserIDKey))
+	if err != nil {
+		oidcError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// AuthorizeLink
+// POST /api/user/identities/:provider
+func (h *OIDCHandler) AuthorizeLink(c *gin.Context) {
+	resp, err := h.oidcService.Authorize(c.Request.Context(), c.Param("provider"), c.GetInt64(middleware.ContextUserIDKey))
+	if err != nil {
+		oidcError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// LinkCallback
+// POST /api/user/identities/:provider/callback
+func (h *OIDCHandler) LinkCallback(c *gin.Context) {
+	var req dto.OIDCCallbackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"errors": gin.H{"body": []string{err.Error()}},
+		})
+		return
+	}
+
+	resp, err := h.oidcService.Link(c.Request.Context(), c.GetInt64(middleware.ContextUserIDKey), c.Param("provider"), &req)
+	if err != nil {
+		oidcError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, resp)
+}
+
+// Unlink
+// DELETE /api/user/identities/:provider
+func (h *OIDCHandler) Unlink(c *gin.Context) {
+	err := h.oidcService.Unlink(c.Request.Context(), c.GetInt64(middleware.ContextUserIDKey), c.Param("provider"))
+	if err != nil {
+		oidcError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// oidcError 把 service 层错误映射为 HTTP 状态码
+func oidcError(c *gin.Context, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, common.ErrUnknownProvider), errors.Is(err, common.ErrNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, common.ErrInvalidToken):
+		status = http.StatusBadRequest
+	case errors.Is(err, common.ErrOIDCLoginFailed):
+		status = http.StatusUnauthorized
+	case errors.Is(err, common.ErrAccountSuspended):
+		status = http.StatusForbidden
+	case errors.Is(err, common.ErrIdentityAlreadyLinked), errors.Is(err, common.ErrOIDCEmailInUse),
+		errors.Is(err, common.ErrLastLoginMethod):
+		status = http.StatusConflict
+	}
+	c.JSON(status, gin.H{
+		"errors": gin.H{"body": []string{err.Error()}},
+	})
+}
@@ -0,0 +1,80 @@
+package api
+
+import (
+	"errors"
+	"github/CiroLong/realworld-gin/internal/middleware"
+	"github/CiroLong/realworld-gin/internal/pkg/common"
+	"github/CiroLong/realworld-gin/internal/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 登录会话管理相关接口，只能用登录会话访问
+
+type SessionHandler struct {
+	sessionService service.SessionService
+}
+
+func NewSessionHandler(sessionService service.SessionService) *SessionHandler {
+	return &SessionHandler{
+		sessionService: sessionService,
+	}
+}
+
+// ListSessions
+// GET /api/user/sessions
+func (h *SessionHandler) ListSessions(c *gin.Context) {
+	resp, err := h.sessionService.List(
+		c.Request.Context(),
+		c.GetInt64(middleware.ContextUserIDKey),
+		c.GetInt64(middleware.ContextSessionIDKey),
+	)
+	if err != nil {
+		sessionError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// RevokeSession
+// 撤销指定会话，该设备上的 access token 和 refresh token 随即失效
+// DELETE /api/user/sessions/:id
+func (h *SessionHandler) RevokeSession(c *gin.Context) {
+	sessionID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"errors": gin.H{"body": []string{"invalid session id"}},
+		})
+		return
+	}
+
+	if err := h.sessionService.Revoke(c.Request.Context(), c.GetInt64(middleware.ContextUserIDKey), sessionID); err != nil {
+		sessionError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// RevokeAllSessions
+// 在所有设备上登出，包括当前会话
+// DELETE /api/user/sessions
+func (h *SessionHandler) RevokeAllSessions(c *gin.Context) {
+	if err := h.sessionService.RevokeAll(c.Request.Context(), c.GetInt64(middleware.ContextUserIDKey)); err != nil {
+		sessionError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// sessionError 把 service 层错误映射为 HTTP 状态码
+func sessionError(c *gin.Context, err error) {
+	status := http.StatusInternalServerError
+	if errors.Is(err, common.ErrNotFound) {
+		status = http.StatusNotFound
+	}
+	c.JSON(status, gin.H{
+		"errors": gin.H{"body": []string{err.Error()}},
+	})
+}
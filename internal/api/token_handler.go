@@ -0,0 +1,87 @@
+package api
+
+import (
+	"errors"
+	"github/CiroLong/realworld-gin/internal/middleware"
+	"github/CiroLong/realworld-gin/internal/model/dto"
+	"github/CiroLong/realworld-gin/internal/pkg/common"
+	"github/CiroLong/realworld-gin/internal/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 个人访问令牌相关接口，只能用登录会话访问
+
+type TokenHandler struct {
+	tokenService service.TokenService
+}
+
+func NewTokenHandler(tokenService service.TokenService) *TokenHandler {
+	return &TokenHandler{
+		tokenService: tokenService,
+	}
+}
+
+// ListTokens
+// GET /api/user/tokens
+func (h *TokenHandler) ListTokens(c *gin.Context) {
+	resp, err := h.tokenService.List(c.Request.Context(), c.GetInt64(middleware.ContextUserIDKey))
+	if err != nil {
+		tokenError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// CreateToken
+// POST /api/user/tokens
+func (h *TokenHandler) CreateToken(c *gin.Context) {
+	var req dto.CreateTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"errors": gin.H{"body": []string{err.Error()}},
+		})
+		return
+	}
+
+	resp, err := h.tokenService.Create(c.Request.Context(), c.GetInt64(middleware.ContextUserIDKey), &req)
+	if err != nil {
+		tokenError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, resp)
+}
+
+// RevokeToken
+// DELETE /api/user/tokens/:id
+func (h *TokenHandler) RevokeToken(c *gin.Context) {
+	tokenID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"errors": gin.H{"body": []string{"invalid token id"}},
+		})
+		return
+	}
+
+	if err := h.tokenService.Revoke(c.Request.Context(), c.GetInt64(middleware.ContextUserIDKey), tokenID); err != nil {
+		tokenError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// tokenError 把 service 层错误映射为 HTTP 状态码
+func tokenError(c *gin.Context, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, common.ErrInvalidScope):
+		status = http.StatusUnprocessableEntity
+	case errors.Is(err, common.ErrNotFound):
+		status = http.StatusNotFound
+	}
+	c.JSON(status, gin.H{
+		"errors": gin.H{"body": []string{err.Error()}},
+	})
+}
@@ -1,10 +1,14 @@
 package api
 
 import (
+	"errors"
 	"github/CiroLong/realworld-gin/internal/middleware"
 	"github/CiroLong/realworld-gin/internal/model/dto"
+	"github/CiroLong/realworld-gin/internal/pkg/common"
 	"github/CiroLong/realworld-gin/internal/service"
+	"math"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
@@ -35,7 +39,7 @@ func (h *UserHandler) Register(c *gin.Context) {
 	}
 
 	// 2. 调service注册
-	resp, err := h.userService.Register(c.Request.Context(), &req)
+	resp, err := h.userService.Register(c.Request.Context(), &req, clientMeta(c))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"errors": gin.H{"body": []string{err.Error()}},
@@ -61,9 +65,59 @@ func (h *UserHandler) Login(c *gin.Context) {
 	}
 
 	// 2. 调service认证
-	resp, err := h.userService.Login(c.Request.Context(), &req)
+	resp, err := h.userService.Login(c.Request.Context(), &req, clientMeta(c))
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{
+		loginError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// LoginMFA
+// 登录第二步：开启两步验证的用户用登录返回的 mfa.token 和验证码（或恢复码）换取 token
+// POST /api/users/login/2fa
+func (h *UserHandler) LoginMFA(c *gin.Context) {
+	// 1. 处理请求
+	var req dto.MFALoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"errors": gin.H{"body": []string{err.Error()}},
+		})
+		return
+	}
+
+	// 2. 调service校验
+	resp, err := h.userService.LoginMFA(c.Request.Context(), &req, clientMeta(c))
+	if err != nil {
+		loginError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// Refresh
+// 用 refresh token 换取新的 token，旧 refresh token 随即失效
+// POST /api/users/refresh
+func (h *UserHandler) Refresh(c *gin.Context) {
+	// 1. 处理请求
+	var req dto.RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"errors": gin.H{"body": []string{err.Error()}},
+		})
+		return
+	}
+
+	// 2. 调service轮换
+	resp, err := h.userService.Refresh(c.Request.Context(), &req)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, common.ErrInvalidToken) {
+			status = http.StatusUnauthorized
+		}
+		c.JSON(status, gin.H{
 			"errors": gin.H{"body": []string{err.Error()}},
 		})
 		return
@@ -72,6 +126,30 @@ func (h *UserHandler) Login(c *gin.Context) {
 	c.JSON(http.StatusOK, resp)
 }
 
+// Logout
+// Auth needed
+// 撤销当前会话，会话下的 refresh token 全部失效
+// POST /api/user/logout
+func (h *UserHandler) Logout(c *gin.Context) {
+	uidVal, exists := c.Get(middleware.ContextUserIDKey)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"errors": gin.H{"body": []string{"unauthorized"}},
+		})
+		return
+	}
+
+	err := h.userService.Logout(c.Request.Context(), uidVal.(int64), c.GetInt64(middleware.ContextSessionIDKey))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"errors": gin.H{"body": []string{err.Error()}},
+		})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
 // GetCurrentUser
 // Auth needed
 // GET /api/user
@@ -88,7 +166,7 @@ func (h *UserHandler) GetCurrentUser(c *gin.Context) {
 	userID := uidVal.(int64)
 
 	// 2. 调service处理逻辑
-	resp, err := h.userService.GetCurrentUser(c.Request.Context(), userID)
+	resp, err := h.userService.GetCurrentUser(c.Request.Context(), userID, c.GetString(middleware.ContextTokenKey))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"errors": gin.H{"body": []string{err.Error()}},
@@ -97,6 +175,7 @@ func (h *UserHandler) GetCurrentUser(c *gin.Context) {
 	}
 
 	// 3. 返回
+	setETag(c, resp.User.Version)
 	c.JSON(http.StatusOK, resp)
 }
 
@@ -123,19 +202,125 @@ func (h *UserHandler) UpdateCurrentUser(c *gin.Context) {
 		return
 	}
 
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		c.JSON(http.StatusPreconditionFailed, gin.H{
+			"errors": gin.H{"body": []string{err.Error()}},
+		})
+		return
+	}
+
 	// 3. 处理
 	resp, err := h.userService.UpdateCurrentUser(
 		c.Request.Context(),
 		userID,
+		c.GetInt64(middleware.ContextSessionIDKey),
+		c.GetString(middleware.ContextTokenKey),
+		version,
 		&req,
 	)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
+		status := http.StatusBadRequest
+		switch {
+		case errors.Is(err, common.ErrVersionMismatch):
+			status = http.StatusPreconditionFailed
+		case errors.Is(err, common.ErrImpersonating):
+			status = http.StatusForbidden
+		}
+		c.JSON(status, gin.H{
 			"errors": gin.H{"body": []string{err.Error()}},
 		})
 		return
 	}
 
 	// 4. 返回
+	setETag(c, resp.User.Version)
+	c.JSON(http.StatusOK, resp)
+}
+
+// DeleteCurrentUser
+// 注销账号，文章和评论按 account.deletion_policy 匿名化或删除
+// DELETE /api/user
+// auth needed
+func (h *UserHandler) DeleteCurrentUser(c *gin.Context) {
+	var req dto.DeleteAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"errors": gin.H{"body": []string{err.Error()}},
+		})
+		return
+	}
+
+	err := h.userService.DeleteAccount(
+		c.Request.Context(),
+		c.GetInt64(middleware.ContextUserIDKey),
+		c.GetInt64(middleware.ContextSessionIDKey),
+		&req,
+	)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, common.ErrInvalidPassword), errors.Is(err, common.ErrConfirmationMismatch):
+			status = http.StatusUnprocessableEntity
+		case errors.Is(err, common.ErrImpersonating):
+			status = http.StatusForbidden
+		}
+		c.JSON(status, gin.H{
+			"errors": gin.H{"body": []string{err.Error()}},
+		})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// LoginMagicLink
+// 用邮件中的免密登录链接换取 token，开启两步验证时返回 mfa
+// POST /api/users/magic-link/redeem
+func (h *UserHandler) LoginMagicLink(c *gin.Context) {
+	// 1. 处理请求
+	var req dto.RedeemMagicLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"errors": gin.H{"body": []string{err.Error()}},
+		})
+		return
+	}
+
+	// 2. 调service核销
+	resp, err := h.userService.LoginMagicLink(c.Request.Context(), &req, clientMeta(c))
+	if err != nil {
+		loginError(c, err)
+		return
+	}
+
 	c.JSON(http.StatusOK, resp)
 }
+
+// loginError 登录失败的响应：密码、验证码、令牌不对返回 401；退避返回 429，账号锁定返回 423，都带 Retry-After（秒）；
+// 账号停用返回 403；其余（数据库等故障）返回 500，不能当成凭证错误
+func loginError(c *gin.Context, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, common.ErrInvalidCredentials),
+		errors.Is(err, common.ErrInvalidMFACode),
+		errors.Is(err, common.ErrInvalidToken),
+		errors.Is(err, common.ErrUserNotFound):
+		status = http.StatusUnauthorized
+	case errors.Is(err, common.ErrAccountSuspended):
+		status = http.StatusForbidden
+	}
+
+	var retry *common.RetryAfterError
+	if errors.As(err, &retry) {
+		status = http.StatusTooManyRequests
+		if errors.Is(err, common.ErrAccountLocked) {
+			status = http.StatusLocked
+		}
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retry.RetryAfter.Seconds()))))
+	}
+
+	c.JSON(status, gin.H{
+		"errors": gin.H{"body": []string{err.Error()}},
+	})
+}
@@ -11,26 +11,171 @@ import (
 // Config holds application configuration
 // Use github.com/spf13/viper for env & file support
 type Config struct {
-	Server   ServerConfig `mapstructure:"server"`
-	Database MySQLConfig  `mapstructure:"database"`
-	JWT      JWTConfig    `mapstructure:"jwt"`
+	Server   ServerConfig   `mapstructure:"server"`
+	Database DatabaseConfig `mapstructure:"database"`
+	JWT      JWTConfig      `mapstructure:"jwt"`
+	Password PasswordConfig `mapstructure:"password"`
+	Mail     MailConfig     `mapstructure:"mail"`
+	Account  AccountConfig  `mapstructure:"account"`
+	MFA      MFAConfig      `mapstructure:"mfa"`
+	OIDC     OIDCConfig     `mapstructure:"oidc"`
+	Admin    AdminConfig    `mapstructure:"admin"`
+	Article  ArticleConfig  `mapstructure:"article"`
+
+	LoginProtection LoginProtectionConfig `mapstructure:"login_protection"`
 }
 
+// ServerConfig TrustedProxies 为前置代理（负载均衡 / nginx）的地址或网段，
+// 只有来自这些地址的 X-Forwarded-For 才会被采信为客户端 IP，为空时直接使用连接的对端地址
 type ServerConfig struct {
-	Addr         string        `mapstructure:"addr"`
-	ReadTimeout  time.Duration `mapstructure:"read_timeout"`
-	WriteTimeout time.Duration `mapstructure:"write_timeout"`
+	Addr           string        `mapstructure:"addr"`
+	ReadTimeout    time.Duration `mapstructure:"read_timeout"`
+	WriteTimeout   time.Duration `mapstructure:"write_timeout"`
+	TrustedProxies []string      `mapstructure:"trusted_proxies"`
 }
 
-type MySQLConfig struct {
+// DatabaseConfig 数据库配置
+// Driver 可选 mysql / postgres / sqlite / memory，DSN 的格式由 Driver 决定
+// AutoMigrate 为 true 时启动时自动执行未执行的版本化迁移，生产环境可关闭后用 migrate 子命令手动执行
+type DatabaseConfig struct {
+	Driver          string        `mapstructure:"driver"`
+	AutoMigrate     bool          `mapstructure:"auto_migrate"`
 	DSN             string        `mapstructure:"dsn"`
 	MaxOpenConns    int           `mapstructure:"max_open_conns"`
 	MaxIdleConns    int           `mapstructure:"max_idle_conns"`
 	ConnMaxLifetime time.Duration `mapstructure:"conn_max_lifetime"`
 }
+
+// JWTConfig access token 使用 JWT，ExpireTime 应尽量短；
+// refresh token 是不透明的随机串，有效期由 RefreshExpireTime 决定
+//
+// 未配置 Keys 时使用 Secret 做 HS256 签名；
+// 配置了 Keys 时使用非对称签名（RS256 / EdDSA），SigningKeyID 指定签发用的密钥，其余密钥只用于验签，方便轮换
 type JWTConfig struct {
-	Secret     string        `mapstructure:"secret"`
-	ExpireTime time.Duration `mapstructure:"expire_time"`
+	Secret            string         `mapstructure:"secret"`
+	ExpireTime        time.Duration  `mapstructure:"expire_time"`
+	RefreshExpireTime time.Duration  `mapstructure:"refresh_expire_time"`
+	SigningKeyID      string         `mapstructure:"signing_key_id"`
+	Keys              []JWTKeyConfig `mapstructure:"keys"`
+	// SessionCacheTTL 会话撤销检查的缓存时间，其他副本上的撤销最多延迟这么久生效；0 表示每个请求都查库
+	SessionCacheTTL time.Duration `mapstructure:"session_cache_ttl"`
+}
+
+// JWTKeyConfig PEM 格式的密钥文件，签发密钥需要私钥，仅验签的旧密钥提供公钥即可
+type JWTKeyConfig struct {
+	ID             string `mapstructure:"id"`
+	PrivateKeyFile string `mapstructure:"private_key_file"`
+	PublicKeyFile  string `mapstructure:"public_key_file"`
+}
+
+// PasswordConfig 密码哈希配置，Algorithm 只影响新生成的哈希，旧哈希在登录成功后自动升级
+type PasswordConfig struct {
+	Algorithm string       `mapstructure:"algorithm"`
+	Argon2    Argon2Config `mapstructure:"argon2"`
+	Bcrypt    BcryptConfig `mapstructure:"bcrypt"`
+}
+
+type Argon2Config struct {
+	Memory      uint32 `mapstructure:"memory"` // KiB
+	Iterations  uint32 `mapstructure:"iterations"`
+	Parallelism uint8  `mapstructure:"parallelism"`
+	SaltLength  uint32 `mapstructure:"salt_length"`
+	KeyLength   uint32 `mapstructure:"key_length"`
+}
+
+type BcryptConfig struct {
+	Cost int `mapstructure:"cost"`
+}
+
+// MailConfig 发信配置
+// Driver 可选 smtp / file / log：file 把邮件写成 .eml 文件放到 FileDir，log 只打日志，都用于本地开发和测试
+type MailConfig struct {
+	Driver string     `mapstructure:"driver"`
+	From   string     `mapstructure:"from"`
+	SMTP   SMTPConfig `mapstructure:"smtp"`
+	File   struct {
+		Dir string `mapstructure:"dir"`
+	} `mapstructure:"file"`
+}
+
+type SMTPConfig struct {
+	Host     string `mapstructure:"host"`
+	Port     int    `mapstructure:"port"`
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+}
+
+// AccountConfig 密码找回 / 邮箱验证等邮件链接流程的配置，LinkBaseURL 是邮件中链接指向的前端地址
+// RequireVerifiedEmail 为 true 时，邮箱未验证的用户不能发文章和评论
+// MagicLinkRateLimit 为每个邮箱在 MagicLinkRateWindow 内最多发送的免密登录链接数，0 表示不限制
+// DeletionPolicy 注销账号时文章和评论的处理方式：anonymize 转给 deleted-user 占位账号，delete 一并删除
+// ExportSyncLimit 个人数据导出中文章、评论和收藏的条数不超过它时直接返回 ZIP，否则后台生成并保留 ExportTTL
+type AccountConfig struct {
+	LinkBaseURL          string        `mapstructure:"link_base_url"`
+	PasswordResetTTL     time.Duration `mapstructure:"password_reset_ttl"`
+	EmailVerificationTTL time.Duration `mapstructure:"email_verification_ttl"`
+	RequireVerifiedEmail bool          `mapstructure:"require_verified_email"`
+	MagicLinkTTL         time.Duration `mapstructure:"magic_link_ttl"`
+	MagicLinkRateLimit   int           `mapstructure:"magic_link_rate_limit"`
+	MagicLinkRateWindow  time.Duration `mapstructure:"magic_link_rate_window"`
+	DeletionPolicy       string        `mapstructure:"deletion_policy"`
+	ExportSyncLimit      int           `mapstructure:"export_sync_limit"`
+	ExportTTL            time.Duration `mapstructure:"export_ttl"`
+}
+
+// MFAConfig 两步验证配置
+// EncryptionKey 用于加密保存 TOTP 密钥，修改后已绑定的用户需要重新绑定
+type MFAConfig struct {
+	Issuer        string        `mapstructure:"issuer"`
+	EncryptionKey string        `mapstructure:"encryption_key"`
+	PendingTTL    time.Duration `mapstructure:"pending_ttl"`
+}
+
+// OIDCConfig 第三方登录（OpenID Connect），Providers 的 key 即 URL 中的 provider 名（如 gitlab / google）
+// StateTTL 为跳转到第三方授权页面后完成登录的时限
+type OIDCConfig struct {
+	StateTTL  time.Duration                 `mapstructure:"state_ttl"`
+	Providers map[string]OIDCProviderConfig `mapstructure:"providers"`
+}
+
+// OIDCProviderConfig Issuer 用于自动发现端点（<issuer>/.well-known/openid-configuration）
+// RedirectURL 是在第三方登记的回调地址，指向前端页面，由前端把 code / state 提交给后端
+type OIDCProviderConfig struct {
+	DisplayName  string   `mapstructure:"display_name"`
+	Issuer       string   `mapstructure:"issuer"`
+	ClientID     string   `mapstructure:"client_id"`
+	ClientSecret string   `mapstructure:"client_secret"`
+	RedirectURL  string   `mapstructure:"redirect_url"`
+	Scopes       []string `mapstructure:"scopes"`
+}
+
+// AdminConfig 用户管理配置
+// HideSuspendedContent 为 true 时，被停用账号的文章不出现在文章列表和关注流中（按 slug 仍可访问）
+type AdminConfig struct {
+	HideSuspendedContent bool `mapstructure:"hide_suspended_content"`
+}
+
+// ArticleConfig 文章配置
+// ScheduleInterval 定时发布的检查间隔，文章最多比计划时间晚这么久发布；0 表示本进程不运行定时发布
+// SlugMaxLength 由标题生成的 slug 和自定义 slug 的最大长度（20 ~ 255）；SlugPinyin 标题中的汉字转为拼音
+type ArticleConfig struct {
+	ScheduleInterval time.Duration `mapstructure:"schedule_interval"`
+	SlugMaxLength    int           `mapstructure:"slug_max_length"`
+	SlugPinyin       bool          `mapstructure:"slug_pinyin"`
+}
+
+// LoginProtectionConfig 登录防爆破配置
+// Store 可选 sql / memory，为空时跟随 database.driver；memory 只在单个进程内计数，多副本部署请使用 sql
+type LoginProtectionConfig struct {
+	Enabled        bool          `mapstructure:"enabled"`
+	Store          string        `mapstructure:"store"`
+	FreeAttempts   int           `mapstructure:"free_attempts"`
+	IPFreeAttempts int           `mapstructure:"ip_free_attempts"`
+	BaseDelay      time.Duration `mapstructure:"base_delay"`
+	MaxDelay       time.Duration `mapstructure:"max_delay"`
+	LockThreshold  int           `mapstructure:"lock_threshold"`
+	LockDuration   time.Duration `mapstructure:"lock_duration"`
+	Window         time.Duration `mapstructure:"window"`
 }
 
 // 这里是一个全局变量，只提供一个Getter
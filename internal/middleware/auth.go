@@ -1,16 +1,24 @@
 package middleware
 
-// Auth Middleware 负责从 HTTP 请求中解析 JWT，校验合法性，并将用户身份注入上下文。
+// Auth Middleware 负责从 HTTP 请求中解析凭证（JWT 或个人访问令牌），校验合法性，并将用户身份注入上下文。
 // internal/middleware/auth.go
 import (
-	"github/CiroLong/realworld-gin/internal/pkg/jwt"
+	"context"
+	"errors"
+	"github/CiroLong/realworld-gin/internal/pkg/auth"
+	"github/CiroLong/realworld-gin/internal/pkg/common"
 	"net/http"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 )
 
-func AuthMiddleware(jwtMgr jwt.Manager) gin.HandlerFunc {
+// Authenticator 由 service 层实现，校验 token 并返回调用方身份
+type Authenticator interface {
+	Authenticate(ctx context.Context, token string) (*auth.Principal, error)
+}
+
+func AuthMiddleware(authenticator Authenticator) gin.HandlerFunc {
 	// 注意Middleware写法：一个闭包函数
 	return func(c *gin.Context) {
 
@@ -39,8 +47,16 @@ func AuthMiddleware(jwtMgr jwt.Manager) gin.HandlerFunc {
 
 		token := parts[1]
 
-		// 3. 校验并解析 JWT
-		userID, err := jwtMgr.Parse(token)
+		// 3. 校验 JWT / 个人访问令牌
+		principal, err := authenticator.Authenticate(c.Request.Context(), token)
+		if errors.Is(err, common.ErrAccountSuspended) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"errors": gin.H{
+					"body": []string{err.Error()},
+				},
+			})
+			return
+		}
 		if err != nil {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
 				"errors": gin.H{
@@ -52,7 +68,7 @@ func AuthMiddleware(jwtMgr jwt.Manager) gin.HandlerFunc {
 
 		// 4. 注入上下文
 		// 这里将userID注入context中向下传递
-		c.Set(ContextUserIDKey, userID)
+		setPrincipal(c, principal, token)
 
 		// 5. 放行
 		c.Next()
@@ -60,7 +76,7 @@ func AuthMiddleware(jwtMgr jwt.Manager) gin.HandlerFunc {
 }
 
 // OptionalAuthMiddleware 这个中间件是考虑到部分endpoint对带token和不带token的请求响应不同
-func OptionalAuthMiddleware(jwtMgr jwt.Manager) gin.HandlerFunc {
+func OptionalAuthMiddleware(authenticator Authenticator) gin.HandlerFunc {
 
 	return func(c *gin.Context) {
 
@@ -76,11 +92,18 @@ func OptionalAuthMiddleware(jwtMgr jwt.Manager) gin.HandlerFunc {
 			return
 		}
 
-		userID, err := jwtMgr.Parse(parts[1])
+		principal, err := authenticator.Authenticate(c.Request.Context(), parts[1])
 		if err == nil {
-			c.Set(ContextUserIDKey, userID)
+			setPrincipal(c, principal, parts[1])
 		}
 
 		c.Next()
 	}
 }
+
+func setPrincipal(c *gin.Context, principal *auth.Principal, token string) {
+	c.Set(ContextPrincipalKey, principal)
+	c.Set(ContextUserIDKey, principal.UserID)
+	c.Set(ContextSessionIDKey, principal.SessionID)
+	c.Set(ContextTokenKey, token)
+}
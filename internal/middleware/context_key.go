@@ -1,3 +1,12 @@
 package middleware
 
 const ContextUserIDKey = "userID"
+
+// ContextSessionIDKey 当前 access token 所属的会话 id，个人访问令牌为 0
+const ContextSessionIDKey = "sessionID"
+
+// ContextTokenKey 请求携带的原始 access token
+const ContextTokenKey = "token"
+
+// ContextPrincipalKey 通过认证的调用方（*auth.Principal）
+const ContextPrincipalKey = "principal"
@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"context"
+	"github/CiroLong/realworld-gin/internal/pkg/common"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PermissionChecker 由 service 层的 Policy 实现
+type PermissionChecker interface {
+	HasPermission(ctx context.Context, userID int64, perm string) (bool, error)
+}
+
+// RequirePermission 需要放在 AuthMiddleware 之后，当前用户的角色没有 perm 时返回 403
+func RequirePermission(checker PermissionChecker, perm string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ok, err := checker.HasPermission(c.Request.Context(), c.GetInt64(ContextUserIDKey), perm)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+				"errors": gin.H{"body": []string{err.Error()}},
+			})
+			return
+		}
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"errors": gin.H{"body": []string{common.ErrPermissionDenied.Error() + ": " + perm}},
+			})
+			return
+		}
+		c.Next()
+	}
+}
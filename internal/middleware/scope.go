@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"github/CiroLong/realworld-gin/internal/pkg/auth"
+	"github/CiroLong/realworld-gin/internal/pkg/common"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireScope 需要放在 AuthMiddleware 之后，个人访问令牌缺少 scope 时返回 403；登录会话不受限制
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, ok := c.MustGet(ContextPrincipalKey).(*auth.Principal)
+		if !ok || !principal.HasScope(scope) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"errors": gin.H{
+					"body": []string{common.ErrInsufficientScope.Error() + ": " + scope},
+				},
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RequireSession 需要放在 AuthMiddleware 之后，只允许登录会话访问（账号安全相关操作），个人访问令牌返回 403
+func RequireSession() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, ok := c.MustGet(ContextPrincipalKey).(*auth.Principal)
+		if !ok || principal.Kind != auth.KindSession {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"errors": gin.H{
+					"body": []string{"personal access tokens cannot be used for this endpoint"},
+				},
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RejectImpersonation 需要放在 AuthMiddleware 之后，管理员模拟登录的会话不能修改账号的登录凭证（令牌、两步验证、
+// 第三方绑定、会话），否则可以借此接管账号；返回 403
+func RejectImpersonation() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, ok := c.MustGet(ContextPrincipalKey).(*auth.Principal)
+		if !ok || principal.Impersonated() {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"errors": gin.H{
+					"body": []string{common.ErrImpersonating.Error()},
+				},
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SlugResolver 由 service 层的 ArticleService 实现
+type SlugResolver interface {
+	ResolveSlug(ctx context.Context, slug string, userID int64) (string, error)
+}
+
+// ResolveSlug 文章改过 slug 后旧链接仍然可用：路由参数 :slug 是旧 slug 时改写为当前 slug 继续处理，
+// 并通过 Location 响应头返回当前地址，相当于 301，客户端应改用新地址
+// 需要放在认证中间件之后，草稿等只有作者能看到的文章不会向其他人暴露新 slug
+func ResolveSlug(resolver SlugResolver) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		slug := c.Param("slug")
+		if slug == "" {
+			c.Next()
+			return
+		}
+
+		current, err := resolver.ResolveSlug(c.Request.Context(), slug, c.GetInt64(ContextUserIDKey))
+		if err != nil || current == slug {
+			// 已经是当前 slug，或者不是旧 slug，交给 handler 按原样处理
+			c.Next()
+			return
+		}
+
+		for i, p := range c.Params {
+			if p.Key == "slug" {
+				c.Params[i].Value = current
+			}
+		}
+		path := strings.Replace(c.Request.URL.Path, "/articles/"+slug, "/articles/"+current, 1)
+		location := url.URL{Path: path, RawQuery: c.Request.URL.RawQuery}
+		c.Header("Location", location.String())
+		c.Next()
+	}
+}
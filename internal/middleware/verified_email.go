@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"github/CiroLong/realworld-gin/internal/pkg/common"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// EmailVerifiedChecker 由 service 层实现，是否要求验证邮箱由它的配置决定
+type EmailVerifiedChecker interface {
+	CheckEmailVerified(ctx context.Context, userID int64) error
+}
+
+// RequireVerifiedEmail 需要放在 AuthMiddleware 之后，邮箱未验证时返回 403
+func RequireVerifiedEmail(checker EmailVerifiedChecker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		err := checker.CheckEmailVerified(c.Request.Context(), c.GetInt64(ContextUserIDKey))
+		if err != nil {
+			status := http.StatusInternalServerError
+			if errors.Is(err, common.ErrEmailNotVerified) {
+				status = http.StatusForbidden
+			}
+			c.AbortWithStatusJSON(status, gin.H{
+				"errors": gin.H{
+					"body": []string{err.Error()},
+				},
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,69 @@
+package dto
+
+import "time"
+
+// 管理接口的请求 / 响应
+
+// GET /api/admin/users?q=&suspended=&limit=&offset=
+//{
+//  "users": [
+//    {
+//      "id": 3,
+//      "email": "spam@example.com",
+//      "username": "spammer",
+//      "role": "user",
+//      "emailVerified": true,
+//      "suspended": true,
+//      "suspendedAt": "...",
+//      "suspendReason": "spam",
+//      "createdAt": "..."
+//    }
+//  ],
+//  "usersCount": 1
+//}
+
+type AdminUserListResponse struct {
+	Users      []AdminUserDTO `json:"users"`
+	UsersCount int64          `json:"usersCount"`
+}
+
+type AdminUserResponse struct {
+	User AdminUserDTO `json:"user"`
+}
+
+type AdminUserDTO struct {
+	ID            int64      `json:"id"`
+	Email         string     `json:"email"`
+	Username      string     `json:"username"`
+	Role          string     `json:"role"`
+	EmailVerified bool       `json:"emailVerified"`
+	Suspended     bool       `json:"suspended"`
+	SuspendedAt   *time.Time `json:"suspendedAt"`
+	SuspendReason string     `json:"suspendReason,omitempty"`
+	CreatedAt     time.Time  `json:"createdAt"`
+}
+
+// PUT /api/admin/users/:id
+//{
+//  "user": {
+//    "email": "jake@example.com",
+//    "username": "jake"
+//  }
+//}
+// 只改传了的字段；改邮箱后需要重新验证
+
+type AdminUpdateUserRequest struct {
+	User struct {
+		Email    *string `json:"email" binding:"omitempty,email"`
+		Username *string `json:"username" binding:"omitempty,min=3,max=50"`
+	} `json:"user"`
+}
+
+// POST /api/admin/users/:id/suspend
+//{
+//  "reason": "spam"
+//}
+
+type SuspendUserRequest struct {
+	Reason string `json:"reason" binding:"max=255"`
+}
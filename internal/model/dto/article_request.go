@@ -1,11 +1,19 @@
 package dto
 
+import "time"
+
 type CreateArticleRequest struct {
 	Article struct {
 		Title       string   `json:"title" binding:"required"`
 		Description string   `json:"description" binding:"required"`
 		Body        string   `json:"body" binding:"required"`
 		TagList     []string `json:"tagList"`
+		// Slug 自定义 slug，指定后修改标题不会改变它；不传时根据标题生成
+		Slug string `json:"slug" binding:"omitempty,max=255"`
+		// Status 不传时直接发布
+		Status string `json:"status" binding:"omitempty,oneof=draft published unlisted"`
+		// PublishAt 定时发布，RFC 3339 格式且必须带时区，如 2026-10-19T08:00:00+08:00；不能和 Status 同时传
+		PublishAt *time.Time `json:"publishAt"`
 	} `json:"article" binding:"required"`
 }
 
@@ -14,5 +22,17 @@ type UpdateArticleRequest struct {
 		Title       string `json:"title"`
 		Description string `json:"description"`
 		Body        string `json:"body"`
+		// TagList 文章的完整标签列表，不传表示不修改，空数组清空标签
+		TagList *[]string `json:"tagList"`
+		// AddTags / RemoveTags 在 TagList（未传时为当前标签）的基础上增加 / 去掉的标签，同时出现在两者中的标签被去掉
+		AddTags    []string `json:"addTags"`
+		RemoveTags []string `json:"removeTags"`
+		// Slug 改用自定义 slug 并锁定，旧 slug 会重定向到新 slug
+		Slug string `json:"slug" binding:"omitempty,max=255"`
+		// SlugLocked 锁定 / 解锁当前 slug，未锁定时修改标题会重新生成 slug
+		SlugLocked *bool  `json:"slugLocked"`
+		Status     string `json:"status" binding:"omitempty,oneof=draft published unlisted archived"`
+		// PublishAt 改为定时发布或修改发布时间，已发布的文章不能再定时
+		PublishAt *time.Time `json:"publishAt"`
 	} `json:"article"`
 }
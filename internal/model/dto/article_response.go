@@ -13,16 +13,22 @@ type AuthorDTO struct {
 }
 
 type ArticleDTO struct {
-	Slug           string    `json:"slug"`
-	Title          string    `json:"title"`
-	Description    string    `json:"description"`
-	Body           string    `json:"body"`
-	TagList        []string  `json:"tagList"`
-	CreatedAt      time.Time `json:"createdAt"`
-	UpdatedAt      time.Time `json:"updatedAt"`
-	Favorited      bool      `json:"favorited"` //TODO: check所有返回article，需要填写这个
-	FavoritesCount int       `json:"favoritesCount"`
-	Author         AuthorDTO `json:"author"`
+	Slug           string     `json:"slug"`
+	SlugLocked     bool       `json:"slugLocked"`
+	Title          string     `json:"title"`
+	Description    string     `json:"description"`
+	Body           string     `json:"body"`
+	TagList        []string   `json:"tagList"`
+	Status         string     `json:"status"`
+	PublishedAt    *time.Time `json:"publishedAt"`
+	PublishAt      *time.Time `json:"publishAt,omitempty"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	UpdatedAt      time.Time  `json:"updatedAt"`
+	Favorited      bool       `json:"favorited"` //TODO: check所有返回article，需要填写这个
+	FavoritesCount int        `json:"favoritesCount"`
+	Author         AuthorDTO  `json:"author"`
+
+	Version int64 `json:"-"` // 通过 ETag 响应头返回
 }
 
 type ArticleResponse struct {
@@ -33,32 +39,57 @@ func NewArticleResponse(article *entity.Article, tags []string, author AuthorDTO
 	return &ArticleResponse{
 		Article: ArticleDTO{
 			Slug:           article.Slug,
+			SlugLocked:     article.SlugLocked,
 			Title:          article.Title,
 			Description:    article.Description,
 			Body:           article.Body,
 			TagList:        tags,
+			Status:         article.Status,
+			PublishedAt:    article.PublishedAt,
+			PublishAt:      article.PublishAt,
 			CreatedAt:      article.CreatedAt,
 			UpdatedAt:      article.UpdatedAt,
 			Favorited:      favorited,
 			FavoritesCount: article.FavoritesCount,
 			Author:         author,
+			Version:        article.Version,
 		},
 	}
 }
 
 // 注意这里不返回Body
 type ArticleWithoutBodyDTO struct {
-	Slug           string    `json:"slug"`
-	Title          string    `json:"title"`
-	Description    string    `json:"description"`
-	TagList        []string  `json:"tagList"`
-	CreatedAt      time.Time `json:"createdAt"`
-	UpdatedAt      time.Time `json:"updatedAt"`
-	Favorited      bool      `json:"favorited"`
-	FavoritesCount int       `json:"favoritesCount"`
-	Author         AuthorDTO `json:"author"`
+	Slug           string     `json:"slug"`
+	Title          string     `json:"title"`
+	Description    string     `json:"description"`
+	TagList        []string   `json:"tagList"`
+	Status         string     `json:"status"`
+	PublishedAt    *time.Time `json:"publishedAt"`
+	PublishAt      *time.Time `json:"publishAt,omitempty"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	UpdatedAt      time.Time  `json:"updatedAt"`
+	Favorited      bool       `json:"favorited"`
+	FavoritesCount int        `json:"favoritesCount"`
+	Author         AuthorDTO  `json:"author"`
 }
 type MultipleArticlesResponse struct {
 	Articles      []ArticleWithoutBodyDTO `json:"articles"`
 	ArticlesCount int                     `json:"articlesCount"`
 }
+
+func NewArticleWithoutBodyDTO(article *entity.Article, tags []string, author AuthorDTO, favorited bool) ArticleWithoutBodyDTO {
+	return ArticleWithoutBodyDTO{
+		Slug:           article.Slug,
+		Title:          article.Title,
+		Description:    article.Description,
+		TagList:        tags,
+		Status:         article.Status,
+		PublishedAt:    article.PublishedAt,
+		PublishAt:      article.PublishAt,
+		CreatedAt:      article.CreatedAt,
+		UpdatedAt:      article.UpdatedAt,
+		Favorited:      favorited,
+		FavoritesCount: article.FavoritesCount,
+		Author:         author,
+	}
+}
@@ -0,0 +1,34 @@
+package dto
+
+import "time"
+
+// RevisionDTO 列表中不返回 Body；Editor 为修改者的用户名
+type RevisionDTO struct {
+	ID           int64     `json:"id"`
+	Title        string    `json:"title"`
+	Description  string    `json:"description"`
+	Body         string    `json:"body,omitempty"`
+	Editor       string    `json:"editor"`
+	RestoredFrom int64     `json:"restoredFrom,omitempty"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+type RevisionResponse struct {
+	Revision RevisionDTO `json:"revision"`
+}
+
+type MultipleRevisionsResponse struct {
+	Revisions      []RevisionDTO `json:"revisions"`
+	RevisionsCount int           `json:"revisionsCount"`
+}
+
+// RevisionDiffDTO From 为 0 表示和空内容比较（文章的第一版）；Unified 为 unified diff 文本，没有差异时为空
+type RevisionDiffDTO struct {
+	From    int64  `json:"from"`
+	To      int64  `json:"to"`
+	Unified string `json:"unified"`
+}
+
+type RevisionDiffResponse struct {
+	Diff RevisionDiffDTO `json:"diff"`
+}
@@ -0,0 +1,76 @@
+package dto
+
+import "time"
+
+// 个人数据导出
+
+// GET /api/user/export
+// 数据量小时直接返回 ZIP（application/zip），否则在后台生成，返回 202：
+//{
+//  "export": {
+//    "id": 7,
+//    "status": "pending",
+//    "createdAt": "...",
+//    "completedAt": null,
+//    "expiresAt": "..."
+//  }
+//}
+// 之后轮询 GET /api/user/export/:id，生成好后同样返回 ZIP；status 为 pending / ready / failed
+
+type DataExportResponse struct {
+	Export DataExportDTO `json:"export"`
+}
+
+type DataExportDTO struct {
+	ID          int64      `json:"id"`
+	Status      string     `json:"status"`
+	Error       string     `json:"error,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	CompletedAt *time.Time `json:"completedAt"`
+	ExpiresAt   time.Time  `json:"expiresAt"`
+}
+
+// ZIP 中每类数据一个 JSON 文件：
+// profile.json / articles.json / comments.json / favorites.json / follows.json
+
+type ExportProfile struct {
+	Username      string    `json:"username"`
+	Email         string    `json:"email"`
+	Bio           string    `json:"bio"`
+	Image         string    `json:"image"`
+	Role          string    `json:"role"`
+	EmailVerified bool      `json:"emailVerified"`
+	CreatedAt     time.Time `json:"createdAt"`
+}
+
+type ExportArticle struct {
+	Slug           string     `json:"slug"`
+	Title          string     `json:"title"`
+	Description    string     `json:"description"`
+	Body           string     `json:"body"`
+	TagList        []string   `json:"tagList"`
+	FavoritesCount int        `json:"favoritesCount"`
+	Status         string     `json:"status"`
+	PublishedAt    *time.Time `json:"publishedAt"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	UpdatedAt      time.Time  `json:"updatedAt"`
+}
+
+// ExportComment Article 为评论所在文章的 slug
+type ExportComment struct {
+	ID        int64     `json:"id"`
+	Article   string    `json:"article"`
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type ExportFavorite struct {
+	Slug   string `json:"slug"`
+	Title  string `json:"title"`
+	Author string `json:"author"`
+}
+
+type ExportFollows struct {
+	Following []string `json:"following"`
+	Followers []string `json:"followers"`
+}
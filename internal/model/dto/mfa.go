@@ -0,0 +1,84 @@
+package dto
+
+// 两步验证相关的请求 / 响应
+
+// Confirm / regenerate POST /api/user/2fa/confirm, POST /api/user/2fa/recovery-codes
+//{
+//  "code": "123456"
+//}
+
+type MFACodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// Disable POST /api/user/2fa/disable
+//{
+//  "password": "jakejake",
+//  "code": "123456"
+//}
+// code 也可以是恢复码；没有设置密码的账号（第三方登录创建）不用传 password
+
+type MFADisableRequest struct {
+	Password string `json:"password"`
+	Code     string `json:"code" binding:"required"`
+}
+
+// Login with 2FA POST /api/users/login/2fa
+//{
+//  "mfaToken": "...",
+//  "code": "123456"
+//}
+
+type MFALoginRequest struct {
+	MFAToken string `json:"mfaToken" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
+
+// GET /api/user/2fa
+//{
+//  "twoFactor": {
+//    "enabled": true,
+//    "recoveryCodesRemaining": 8
+//  }
+//}
+
+type MFAStatusResponse struct {
+	TwoFactor MFAStatusDTO `json:"twoFactor"`
+}
+
+type MFAStatusDTO struct {
+	Enabled                bool  `json:"enabled"`
+	RecoveryCodesRemaining int64 `json:"recoveryCodesRemaining"`
+}
+
+// POST /api/user/2fa/enroll
+//{
+//  "twoFactor": {
+//    "secret": "JBSWY3DPEHPK3PXP",
+//    "otpauthUri": "otpauth://totp/RealWorld:jake@jake.jake?..."
+//  }
+//}
+
+type MFAEnrollResponse struct {
+	TwoFactor MFAEnrollDTO `json:"twoFactor"`
+}
+
+type MFAEnrollDTO struct {
+	Secret     string `json:"secret"`
+	OtpauthURI string `json:"otpauthUri"`
+}
+
+// 恢复码只在生成时返回这一次
+//{
+//  "recoveryCodes": ["abcd-efgh", ...]
+//}
+
+type MFARecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
+
+// MFAChallenge 登录时密码正确但需要两步验证
+type MFAChallenge struct {
+	Token     string `json:"token"`
+	ExpiresIn int    `json:"expiresIn"` // 秒
+}
@@ -0,0 +1,72 @@
+package dto
+
+import "time"
+
+// 第三方登录（OIDC）相关的请求 / 响应
+
+// GET /api/users/oidc
+//{
+//  "providers": [
+//    {"name": "gitlab", "displayName": "GitLab"}
+//  ]
+//}
+
+type OIDCProvidersResponse struct {
+	Providers []OIDCProviderDTO `json:"providers"`
+}
+
+type OIDCProviderDTO struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+}
+
+// GET /api/users/oidc/:provider, POST /api/user/identities/:provider
+//{
+//  "oidc": {
+//    "authorizationUrl": "https://gitlab.com/oauth/authorize?...",
+//    "state": "..."
+//  }
+//}
+// 前端保存 state 后跳转到 authorizationUrl，回调时核对 state 是否一致
+
+type OIDCAuthorizeResponse struct {
+	OIDC OIDCAuthorizeDTO `json:"oidc"`
+}
+
+type OIDCAuthorizeDTO struct {
+	AuthorizationURL string `json:"authorizationUrl"`
+	State            string `json:"state"`
+}
+
+// Callback POST /api/users/oidc/:provider/callback, POST /api/user/identities/:provider/callback
+//{
+//  "code": "...",
+//  "state": "..."
+//}
+// 前端把回调地址上的 code / state 原样提交
+
+type OIDCCallbackRequest struct {
+	Code  string `json:"code" binding:"required"`
+	State string `json:"state" binding:"required"`
+}
+
+// GET /api/user/identities
+//{
+//  "identities": [
+//    {"provider": "gitlab", "email": "jake@jake.jake", "createdAt": "..."}
+//  ]
+//}
+
+type IdentityListResponse struct {
+	Identities []IdentityDTO `json:"identities"`
+}
+
+type IdentityResponse struct {
+	Identity IdentityDTO `json:"identity"`
+}
+
+type IdentityDTO struct {
+	Provider  string    `json:"provider"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"createdAt"`
+}
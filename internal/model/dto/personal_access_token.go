@@ -0,0 +1,62 @@
+package dto
+
+import "time"
+
+// 个人访问令牌相关的请求 / 响应
+
+// Create POST /api/user/tokens
+//{
+//  "token": {
+//    "name": "ci deploy",
+//    "scopes": ["articles:write"],
+//    "expiresInDays": 90
+//  }
+//}
+// expiresInDays 不传表示永不过期
+
+type CreateTokenRequest struct {
+	Token struct {
+		Name          string   `json:"name" binding:"required,max=100"`
+		Scopes        []string `json:"scopes" binding:"required,min=1"`
+		ExpiresInDays int      `json:"expiresInDays" binding:"omitempty,min=1,max=365"`
+	} `json:"token"`
+}
+
+// POST /api/user/tokens
+//{
+//  "token": {
+//    "id": 1,
+//    "name": "ci deploy",
+//    "scopes": ["articles:write"],
+//    "prefix": "rwpat_Ab12",
+//    "token": "rwpat_Ab12...",
+//    "createdAt": "...",
+//    "expiresAt": "...",
+//    "lastUsedAt": null
+//  }
+//}
+// token 明文只在创建时返回这一次
+
+type TokenResponse struct {
+	Token TokenDTO `json:"token"`
+}
+
+// GET /api/user/tokens
+//{
+//  "tokens": [...]
+//}
+
+type TokenListResponse struct {
+	Tokens []TokenDTO `json:"tokens"`
+}
+
+type TokenDTO struct {
+	ID         int64      `json:"id"`
+	Name       string     `json:"name"`
+	Scopes     []string   `json:"scopes"`
+	Prefix     string     `json:"prefix"`
+	Token      string     `json:"token,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	ExpiresAt  *time.Time `json:"expiresAt"`
+	LastUsedAt *time.Time `json:"lastUsedAt"`
+}
@@ -0,0 +1,24 @@
+package dto
+
+// 角色相关的响应
+
+// GET /api/admin/roles
+//{
+//  "roles": [
+//    {
+//      "name": "moderator",
+//      "description": "Can remove other users' articles and comments",
+//      "permissions": ["articles:delete_any", "comments:delete_any"]
+//    }
+//  ]
+//}
+
+type RoleListResponse struct {
+	Roles []RoleDTO `json:"roles"`
+}
+
+type RoleDTO struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+}
@@ -0,0 +1,38 @@
+package dto
+
+import "time"
+
+// 会话管理相关的响应
+
+// GET /api/user/sessions
+//{
+//  "sessions": [
+//    {
+//      "id": 12,
+//      "device": "Firefox on macOS",
+//      "userAgent": "Mozilla/5.0 (Macintosh; ...) Firefox/131.0",
+//      "ip": "203.0.113.7",
+//      "createdAt": "...",
+//      "lastSeenAt": "...",
+//      "current": true,
+//      "impersonated": false
+//    }
+//  ]
+//}
+// current 表示发起请求的会话；ip 是登录时的地址；impersonated 表示管理员以该用户身份登录的会话
+
+type SessionListResponse struct {
+	Sessions []SessionDTO `json:"sessions"`
+}
+
+type SessionDTO struct {
+	ID         int64     `json:"id"`
+	Device     string    `json:"device"`
+	UserAgent  string    `json:"userAgent"`
+	IP         string    `json:"ip"`
+	CreatedAt  time.Time `json:"createdAt"`
+	LastSeenAt time.Time `json:"lastSeenAt"`
+	Current    bool      `json:"current"`
+
+	Impersonated bool `json:"impersonated"`
+}
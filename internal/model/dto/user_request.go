@@ -53,3 +53,70 @@ type UpdateUserRequest struct {
 		Image    *string `json:"image"`
 	} `json:"user"`
 }
+
+// Refresh POST /api/users/refresh
+//{
+//  "refreshToken": "..."
+//}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refreshToken" binding:"required"`
+}
+
+// Forgot password POST /api/users/password/forgot
+//{
+//  "email": "jake@jake.jake"
+//}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// Reset password POST /api/users/password/reset
+//{
+//  "token": "...",
+//  "password": "newpassword"
+//}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required,min=6"`
+}
+
+// Verify email POST /api/users/verify
+//{
+//  "token": "..."
+//}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// Magic link POST /api/users/magic-link
+//{
+//  "email": "jake@jake.jake"
+//}
+
+type MagicLinkRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// Redeem magic link POST /api/users/magic-link/redeem
+//{
+//  "token": "..."
+//}
+
+type RedeemMagicLinkRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// Delete account DELETE /api/user
+//{
+//  "password": "jakejake"
+//}
+// 没有设置密码的账号（第三方登录 / 免密登录创建）改为输入用户名确认：{"username": "jake"}
+
+type DeleteAccountRequest struct {
+	Password string `json:"password"`
+	Username string `json:"username"`
+}
@@ -16,11 +16,31 @@ type UserResponse struct {
 }
 
 type UserDTO struct {
-	Email    string `json:"email"`
-	Token    string `json:"token"`
-	Username string `json:"username"`
-	Bio      string `json:"bio"`
-	Image    string `json:"image"`
+	Email        string `json:"email"`
+	Token        string `json:"token"`
+	RefreshToken string `json:"refreshToken,omitempty"` // 只在登录 / 注册 / 刷新时返回
+	Username     string `json:"username"`
+	Bio          string `json:"bio"`
+	Image        string `json:"image"`
+
+	EmailVerified bool   `json:"emailVerified"`
+	Role          string `json:"role"` // 前端据此决定是否展示管理入口
+
+	Version int64 `json:"-"` // 通过 ETag 响应头返回
+}
+
+// LoginResponse 未开启两步验证时与 UserResponse 相同；
+// 开启时只返回 mfa，客户端带上 mfa.token 和验证码调用 POST /api/users/login/2fa 换取 token
+//{
+//  "mfa": {
+//    "token": "...",
+//    "expiresIn": 300
+//  }
+//}
+
+type LoginResponse struct {
+	*UserResponse
+	MFA *MFAChallenge `json:"mfa,omitempty"`
 }
 
 type ProfileDTO struct {
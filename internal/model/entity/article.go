@@ -7,6 +7,7 @@ import "time"
 // CREATE TABLE articles (
 //  id BIGINT AUTO_INCREMENT PRIMARY KEY,
 //  slug VARCHAR(255) NOT NULL UNIQUE,
+//  slug_locked BOOLEAN NOT NULL DEFAULT FALSE,
 //  title VARCHAR(255) NOT NULL,
 //  description VARCHAR(255) NOT NULL,
 //  body TEXT NOT NULL,
@@ -15,16 +16,37 @@ import "time"
 //
 //  favorites_count INT NOT NULL DEFAULT 0,
 //
+//  status VARCHAR(16) NOT NULL DEFAULT 'published',
+//  published_at DATETIME NULL,
+//  publish_at DATETIME NULL,
+//
+//  version BIGINT NOT NULL DEFAULT 1,
+//
 //  created_at DATETIME NOT NULL,
 //  updated_at DATETIME NOT NULL,
 //
 //  INDEX idx_author_id (author_id),
+//  INDEX idx_articles_status (status),
+//  INDEX idx_articles_published_at (published_at),
+//  INDEX idx_articles_publish_at (publish_at),
 //  CONSTRAINT fk_articles_author
 //    FOREIGN KEY (author_id) REFERENCES users(id)
 //);
 
 // 注意 这里不在 struct 里显式声明 GORM 的外键关系
 
+// 文章状态：草稿、定时发布和归档只有作者本人可见；unlisted 拿到链接就能访问，但不出现在列表、Feed 里
+const (
+	ArticleStatusDraft     = "draft"
+	ArticleStatusScheduled = "scheduled"
+	ArticleStatusPublished = "published"
+	ArticleStatusUnlisted  = "unlisted"
+	ArticleStatusArchived  = "archived"
+)
+
+// ArticleStatuses 全部文章状态
+var ArticleStatuses = []string{ArticleStatusDraft, ArticleStatusScheduled, ArticleStatusPublished, ArticleStatusUnlisted, ArticleStatusArchived}
+
 type Article struct {
 	ID          int64  `gorm:"primaryKey"`
 	Slug        string `gorm:"size:255;uniqueIndex;not null"`
@@ -32,10 +54,22 @@ type Article struct {
 	Description string `gorm:"size:255;not null"`
 	Body        string `gorm:"type:text;not null"`
 
+	// SlugLocked 作者自定义或锁定了 slug，修改标题时不再重新生成
+	SlugLocked bool `gorm:"not null;default:false"`
+
 	AuthorID int64 `gorm:"index;not null"`
 
 	FavoritesCount int `gorm:"not null;default:0"`
 
+	Status string `gorm:"size:16;not null;default:published;index"`
+	// PublishedAt 第一次发布的时间，草稿为空；之后撤回再发布不会改变
+	PublishedAt *time.Time `gorm:"index"`
+	// PublishAt 定时发布的时间（UTC），只有 scheduled 状态的文章才有
+	PublishAt *time.Time `gorm:"index"`
+
+	// Version 每次更新加一，更新时带上读到的版本做条件，防止并发编辑互相覆盖；对外作为 ETag
+	Version int64 `gorm:"not null;default:1"`
+
 	CreatedAt time.Time
 	UpdatedAt time.Time
 }
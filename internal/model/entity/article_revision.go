@@ -0,0 +1,35 @@
+package entity
+
+import "time"
+
+// 文章修订历史：每次修改标题 / 描述 / 正文都追加一条完整内容，已有的修订不再修改
+// 恢复旧版本也是追加一条新修订，RestoredFrom 记录来源
+
+// CREATE TABLE article_revisions (
+//  id BIGINT AUTO_INCREMENT PRIMARY KEY,
+//  article_id BIGINT NOT NULL,
+//  editor_id BIGINT NOT NULL,
+//  title VARCHAR(255) NOT NULL,
+//  description VARCHAR(255) NOT NULL,
+//  body TEXT NOT NULL,
+//  restored_from BIGINT NOT NULL DEFAULT 0,
+//  created_at DATETIME NULL,
+//
+//  INDEX idx_article_revisions_article_id (article_id),
+//  INDEX idx_article_revisions_editor_id (editor_id)
+//);
+
+type ArticleRevision struct {
+	ID        int64 `gorm:"primaryKey"`
+	ArticleID int64 `gorm:"index;not null"`
+	EditorID  int64 `gorm:"index;not null"`
+
+	Title       string `gorm:"size:255;not null"`
+	Description string `gorm:"size:255;not null"`
+	Body        string `gorm:"type:text;not null"`
+
+	// RestoredFrom 由恢复操作产生时为来源修订的 id，否则为 0
+	RestoredFrom int64 `gorm:"not null;default:0"`
+
+	CreatedAt time.Time
+}
@@ -0,0 +1,24 @@
+package entity
+
+import "time"
+
+// 文章用过的旧 slug：slug 变化时把旧值记下来，旧链接通过它找到文章，再提示客户端改用当前 slug
+// 一个 slug 同一时间只属于一篇文章，要么是它当前的 slug，要么在这里；文章改回旧 slug 时从这里删掉
+
+// CREATE TABLE article_slugs (
+//  id BIGINT AUTO_INCREMENT PRIMARY KEY,
+//  article_id BIGINT NOT NULL,
+//  slug VARCHAR(255) NOT NULL,
+//  created_at DATETIME NULL,
+//
+//  UNIQUE INDEX idx_article_slugs_slug (slug),
+//  INDEX idx_article_slugs_article_id (article_id)
+//);
+
+type ArticleSlug struct {
+	ID        int64  `gorm:"primaryKey"`
+	ArticleID int64  `gorm:"index;not null"`
+	Slug      string `gorm:"size:255;uniqueIndex;not null"`
+
+	CreatedAt time.Time
+}
@@ -0,0 +1,37 @@
+package entity
+
+import "time"
+
+// 个人数据导出（GDPR）：数据量大的账号在后台生成，生成好的 ZIP 直接存在表里，过期后不能再下载
+
+// CREATE TABLE data_exports (
+//  id BIGINT AUTO_INCREMENT PRIMARY KEY,
+//  user_id BIGINT NOT NULL,
+//  status VARCHAR(16) NOT NULL,
+//  data LONGBLOB NULL,
+//  error VARCHAR(255) NOT NULL DEFAULT '',
+//  expires_at DATETIME NOT NULL,
+//  completed_at DATETIME NULL,
+//  created_at DATETIME NOT NULL,
+//
+//  INDEX idx_data_exports_user_id (user_id)
+//);
+
+const (
+	ExportStatusPending = "pending"
+	ExportStatusReady   = "ready"
+	ExportStatusFailed  = "failed"
+)
+
+type DataExport struct {
+	ID     int64  `gorm:"primaryKey"`
+	UserID int64  `gorm:"index;not null"`
+	Status string `gorm:"size:16;not null"`
+
+	Data  []byte // ZIP 内容，ready 之后才有
+	Error string `gorm:"size:255;not null;default:''"`
+
+	ExpiresAt   time.Time `gorm:"not null"`
+	CompletedAt *time.Time
+	CreatedAt   time.Time
+}
@@ -4,7 +4,8 @@ package entity
 //  这个包下主要是原始的gorm定义
 //  entity的职责： 映射数据库
 
-// 注意，这里的建表操作全部是由orm完成的，由于没有使用orm的关系机制，orm也没有生成外键
+// 注意，表结构由 repository/gorm/migrations 下的版本化 SQL 迁移维护，修改字段时要同步新增迁移；
+// 由于没有使用orm的关系机制，也没有建外键
 
 // AI：
 // 在这个项目里我选择不在数据库层加外键约束，而是在应用层保证一致性，
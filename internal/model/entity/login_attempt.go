@@ -0,0 +1,23 @@
+package entity
+
+import "time"
+
+// 登录失败计数，用于退避和锁定
+// Subject 是计数的维度，如 "account:<email>"、"ip:<addr>"；登录成功或管理员解锁后删除
+
+// CREATE TABLE login_attempts (
+//  subject VARCHAR(191) PRIMARY KEY,
+//  failures INT NOT NULL DEFAULT 0,
+//  last_failed_at DATETIME NOT NULL,
+//  locked_until DATETIME NULL,
+//  updated_at DATETIME NOT NULL
+//);
+
+type LoginAttempt struct {
+	Subject  string `gorm:"primaryKey;size:191"`
+	Failures int    `gorm:"not null;default:0"`
+
+	LastFailedAt time.Time `gorm:"not null"`
+	LockedUntil  *time.Time
+	UpdatedAt    time.Time
+}
@@ -0,0 +1,53 @@
+package entity
+
+import "time"
+
+// 两步验证（TOTP）
+// 用户发起绑定时生成密钥，输入一次正确的验证码确认后 EnabledAt 才置位；
+// 密钥需要还原出来计算验证码，所以是加密存储而不是哈希
+
+// CREATE TABLE user_mfa (
+//  user_id BIGINT PRIMARY KEY,
+//  secret VARCHAR(255) NOT NULL,
+//  enabled_at DATETIME NULL,
+//  last_used_step BIGINT NOT NULL DEFAULT 0,
+//  created_at DATETIME NOT NULL,
+//  updated_at DATETIME NOT NULL
+//);
+
+type UserMFA struct {
+	UserID int64  `gorm:"primaryKey;autoIncrement:false"`
+	Secret string `gorm:"size:255;not null"` // 加密后的 base32 密钥
+
+	EnabledAt *time.Time // 为空表示还在绑定中，未生效
+	// LastUsedStep 最近一次验证通过的时间步，同一个验证码不能用两次
+	LastUsedStep int64 `gorm:"not null;default:0"`
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (UserMFA) TableName() string {
+	return "user_mfa"
+}
+
+// 恢复码：手机丢失时代替验证码使用，每个只能用一次
+
+// CREATE TABLE mfa_recovery_codes (
+//  id BIGINT AUTO_INCREMENT PRIMARY KEY,
+//  user_id BIGINT NOT NULL,
+//  code_hash VARCHAR(64) NOT NULL,
+//  used_at DATETIME NULL,
+//  created_at DATETIME NOT NULL,
+//
+//  INDEX idx_mfa_recovery_codes_user_id (user_id)
+//);
+
+type MFARecoveryCode struct {
+	ID       int64  `gorm:"primaryKey"`
+	UserID   int64  `gorm:"index;not null"`
+	CodeHash string `gorm:"size:64;not null"` // sha256，不存明文
+
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
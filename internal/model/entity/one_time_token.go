@@ -0,0 +1,36 @@
+package entity
+
+import "time"
+
+// 一次性令牌：通过邮件链接下发的凭证（密码重置 / 邮箱验证 / 免密登录等）
+// 明文只出现在邮件里，库中只存 sha256 摘要；使用一次即作废
+
+// 令牌用途，同一张表按 purpose 区分
+const (
+	TokenPurposePasswordReset     = "password_reset"
+	TokenPurposeEmailVerification = "email_verification"
+	TokenPurposeMagicLink         = "magic_link"
+)
+
+// CREATE TABLE one_time_tokens (
+//  id BIGINT AUTO_INCREMENT PRIMARY KEY,
+//  user_id BIGINT NOT NULL,
+//  purpose VARCHAR(32) NOT NULL,
+//  token_hash VARCHAR(64) NOT NULL UNIQUE,
+//  expires_at DATETIME NOT NULL,
+//  used_at DATETIME NULL,
+//  created_at DATETIME NOT NULL,
+//
+//  INDEX idx_one_time_tokens_user_purpose (user_id, purpose)
+//);
+
+type OneTimeToken struct {
+	ID        int64  `gorm:"primaryKey"`
+	UserID    int64  `gorm:"index:idx_one_time_tokens_user_purpose;not null"`
+	Purpose   string `gorm:"size:32;index:idx_one_time_tokens_user_purpose;not null"`
+	TokenHash string `gorm:"size:64;uniqueIndex;not null"`
+
+	ExpiresAt time.Time `gorm:"not null"`
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
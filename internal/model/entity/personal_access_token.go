@@ -0,0 +1,35 @@
+package entity
+
+import "time"
+
+// 个人访问令牌：给脚本 / CI 使用的长期凭证，带名称和权限范围，可随时撤销
+// 明文形如 rwpat_xxxx，只在创建时返回一次；库中只存 sha256 摘要和用于辨认的前缀
+
+// CREATE TABLE personal_access_tokens (
+//  id BIGINT AUTO_INCREMENT PRIMARY KEY,
+//  user_id BIGINT NOT NULL,
+//  name VARCHAR(100) NOT NULL,
+//  token_hash VARCHAR(64) NOT NULL UNIQUE,
+//  prefix VARCHAR(16) NOT NULL,
+//  scopes VARCHAR(255) NOT NULL,
+//  expires_at DATETIME NULL,
+//  last_used_at DATETIME NULL,
+//  revoked_at DATETIME NULL,
+//  created_at DATETIME NOT NULL,
+//
+//  INDEX idx_personal_access_tokens_user_id (user_id)
+//);
+
+type PersonalAccessToken struct {
+	ID        int64  `gorm:"primaryKey"`
+	UserID    int64  `gorm:"index;not null"`
+	Name      string `gorm:"size:100;not null"`
+	TokenHash string `gorm:"size:64;uniqueIndex;not null"`
+	Prefix    string `gorm:"size:16;not null"`  // 明文的前几位，方便用户在列表里辨认
+	Scopes    string `gorm:"size:255;not null"` // 空格分隔
+
+	ExpiresAt  *time.Time // 为空表示不过期
+	LastUsedAt *time.Time
+	RevokedAt  *time.Time
+	CreatedAt  time.Time
+}
@@ -0,0 +1,25 @@
+package entity
+
+import "time"
+
+// 角色：users.role 保存角色名，权限以空格分隔存在 permissions 中
+// 默认角色由 realworld-server roles seed 写入
+
+// CREATE TABLE roles (
+//  id BIGINT AUTO_INCREMENT PRIMARY KEY,
+//  name VARCHAR(50) NOT NULL UNIQUE,
+//  description VARCHAR(255) NOT NULL DEFAULT '',
+//  permissions VARCHAR(1000) NOT NULL DEFAULT '',
+//  created_at DATETIME NOT NULL,
+//  updated_at DATETIME NOT NULL
+//);
+
+type Role struct {
+	ID          int64  `gorm:"primaryKey"`
+	Name        string `gorm:"size:50;uniqueIndex;not null"`
+	Description string `gorm:"size:255;not null;default:''"`
+	Permissions string `gorm:"size:1000;not null;default:''"` // 空格分隔
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
@@ -0,0 +1,59 @@
+package entity
+
+import "time"
+
+// 登录会话：每次登录（注册）创建一个会话
+// 会话下的 refresh token 每次刷新都会轮换，同一会话的所有 refresh token 构成一个 token family，
+// 撤销会话即让整个 family 失效
+
+// CREATE TABLE sessions (
+//  id BIGINT AUTO_INCREMENT PRIMARY KEY,
+//  user_id BIGINT NOT NULL,
+//  user_agent VARCHAR(255) NOT NULL DEFAULT '',
+//  ip VARCHAR(64) NOT NULL DEFAULT '',
+//  impersonator_id BIGINT NOT NULL DEFAULT 0,
+//  created_at DATETIME NOT NULL,
+//  last_seen_at DATETIME NOT NULL,
+//  revoked_at DATETIME NULL,
+//
+//  INDEX idx_sessions_user_id (user_id)
+//);
+
+type Session struct {
+	ID     int64 `gorm:"primaryKey"`
+	UserID int64 `gorm:"index;not null"`
+
+	// 登录时的客户端信息，用于在会话列表中辨认设备
+	UserAgent string `gorm:"size:255;not null;default:''"`
+	IP        string `gorm:"size:64;not null;default:''"`
+
+	// ImpersonatorID 管理员以该用户身份登录（客服排查问题）时为管理员 id，普通登录为 0
+	ImpersonatorID int64 `gorm:"not null;default:0"`
+
+	CreatedAt  time.Time
+	LastSeenAt time.Time // 最近一次刷新或访问的时间，按间隔更新，不是每个请求都写
+	RevokedAt  *time.Time
+}
+
+// CREATE TABLE refresh_tokens (
+//  id BIGINT AUTO_INCREMENT PRIMARY KEY,
+//  session_id BIGINT NOT NULL,
+//  user_id BIGINT NOT NULL,
+//  token_hash VARCHAR(64) NOT NULL UNIQUE,
+//  expires_at DATETIME NOT NULL,
+//  used_at DATETIME NULL,
+//  created_at DATETIME NOT NULL,
+//
+//  INDEX idx_refresh_tokens_session_id (session_id)
+//);
+
+type RefreshToken struct {
+	ID        int64  `gorm:"primaryKey"`
+	SessionID int64  `gorm:"index;not null"`
+	UserID    int64  `gorm:"not null"`
+	TokenHash string `gorm:"size:64;uniqueIndex;not null"` // 注意这里是 sha256 后的，不存明文
+
+	ExpiresAt time.Time  `gorm:"not null"`
+	UsedAt    *time.Time // 轮换后置位，已轮换的 token 再次出现即视为重放
+	CreatedAt time.Time
+}
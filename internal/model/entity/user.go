@@ -14,6 +14,11 @@ import "time"
 //    password VARCHAR(255) NOT NULL,
 //    bio TEXT,
 //    image VARCHAR(255),
+//    email_verified_at DATETIME NULL,
+//    role VARCHAR(50) NOT NULL DEFAULT 'user',
+//    suspended_at DATETIME NULL,
+//    suspend_reason VARCHAR(255) NOT NULL DEFAULT '',
+//    version BIGINT NOT NULL DEFAULT 1,
 //    created_at DATETIME NOT NULL,
 //    updated_at DATETIME NOT NULL
 //);
@@ -24,8 +29,22 @@ type User struct {
 	Username string `gorm:"uniqueIndex;size:50;not null"`
 	Password string `gorm:"size:255;not null"` // 注意这里是hash后的
 
-	Bio       string `gorm:"type:text"`
-	Image     string `gorm:"size:255"`
+	Bio   string `gorm:"type:text"`
+	Image string `gorm:"size:255"`
+
+	// EmailVerifiedAt 为空表示邮箱未验证；注册和修改邮箱后需要点邮件里的链接完成验证
+	EmailVerifiedAt *time.Time
+
+	// Role 角色名，对应 roles.name，决定用户能否处理别人的内容
+	Role string `gorm:"size:50;not null;default:user"`
+
+	// SuspendedAt 不为空表示账号被管理员停用：不能登录，已签发的凭证全部失效
+	SuspendedAt   *time.Time `gorm:"index"`
+	SuspendReason string     `gorm:"size:255;not null;default:''"`
+
+	// Version 每次 Update 加一，用于乐观锁和 ETag
+	Version int64 `gorm:"not null;default:1"`
+
 	CreatedAt time.Time
 	UpdatedAt time.Time
 }
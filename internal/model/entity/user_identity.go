@@ -0,0 +1,58 @@
+package entity
+
+import "time"
+
+// 第三方账号（OIDC provider）和本站用户的绑定关系
+// 同一个第三方账号只能绑定一个用户，同一个用户在每个 provider 上只能绑定一个账号
+
+// CREATE TABLE user_identities (
+//  id BIGINT AUTO_INCREMENT PRIMARY KEY,
+//  user_id BIGINT NOT NULL,
+//  provider VARCHAR(50) NOT NULL,
+//  subject VARCHAR(191) NOT NULL,
+//  email VARCHAR(255) NOT NULL DEFAULT '',
+//  created_at DATETIME NOT NULL,
+//
+//  UNIQUE KEY idx_user_identities_provider_subject (provider, subject),
+//  UNIQUE KEY idx_user_identities_user_provider (user_id, provider)
+//);
+
+type UserIdentity struct {
+	ID       int64  `gorm:"primaryKey"`
+	UserID   int64  `gorm:"not null;uniqueIndex:idx_user_identities_user_provider"`
+	Provider string `gorm:"size:50;not null;uniqueIndex:idx_user_identities_provider_subject;uniqueIndex:idx_user_identities_user_provider"`
+	Subject  string `gorm:"size:191;not null;uniqueIndex:idx_user_identities_provider_subject"` // ID token 中的 sub
+	Email    string `gorm:"size:255;not null;default:''"`                                       // 绑定时第三方账号的邮箱，仅用于展示
+
+	CreatedAt time.Time
+}
+
+// OIDCState 跳转到第三方授权页面时保存的一次性上下文，回调时凭 state 取出并删除
+// UserID 为 0 表示登录，否则表示为该用户绑定第三方账号
+
+// CREATE TABLE oidc_states (
+//  id BIGINT AUTO_INCREMENT PRIMARY KEY,
+//  state_hash VARCHAR(64) NOT NULL UNIQUE,
+//  provider VARCHAR(50) NOT NULL,
+//  nonce VARCHAR(64) NOT NULL,
+//  code_verifier VARCHAR(128) NOT NULL,
+//  user_id BIGINT NOT NULL DEFAULT 0,
+//  expires_at DATETIME NOT NULL,
+//  created_at DATETIME NOT NULL
+//);
+
+type OIDCState struct {
+	ID           int64  `gorm:"primaryKey"`
+	StateHash    string `gorm:"size:64;uniqueIndex;not null"`
+	Provider     string `gorm:"size:50;not null"`
+	Nonce        string `gorm:"size:64;not null"`
+	CodeVerifier string `gorm:"size:128;not null"`
+	UserID       int64  `gorm:"not null;default:0"`
+
+	ExpiresAt time.Time `gorm:"index;not null"`
+	CreatedAt time.Time
+}
+
+func (OIDCState) TableName() string {
+	return "oidc_states"
+}
@@ -0,0 +1,56 @@
+package auth
+
+// auth 包定义认证后的调用方身份（Principal）和个人访问令牌的权限范围（scope）
+// 登录得到的 JWT 代表用户本人，拥有全部权限；个人访问令牌只拥有创建时勾选的 scope
+
+import "slices"
+
+// 凭证类型
+const (
+	KindSession = "session" // 登录得到的 JWT
+	KindPAT     = "pat"     // 个人访问令牌
+)
+
+// 个人访问令牌的权限范围；所有令牌都可以访问只读接口，只勾选 read 即为只读令牌
+const (
+	ScopeRead          = "read"
+	ScopeArticlesWrite = "articles:write" // 发布 / 修改 / 删除文章，收藏
+	ScopeCommentsWrite = "comments:write" // 发表 / 删除评论
+	ScopeProfilesWrite = "profiles:write" // 关注 / 取消关注
+)
+
+// Scopes 所有合法的 scope
+var Scopes = []string{ScopeRead, ScopeArticlesWrite, ScopeCommentsWrite, ScopeProfilesWrite}
+
+// ValidScope 是否为合法的 scope
+func ValidScope(scope string) bool {
+	return slices.Contains(Scopes, scope)
+}
+
+// Principal 通过认证的调用方
+type Principal struct {
+	UserID int64
+	Kind   string
+
+	// SessionID JWT 所属的登录会话
+	SessionID int64
+	// ImpersonatorID 管理员模拟登录的会话为管理员 id，其余为 0
+	ImpersonatorID int64
+
+	// TokenID / Scopes 个人访问令牌的 id 和权限范围
+	TokenID int64
+	Scopes  []string
+}
+
+// Impersonated 是否是管理员以该用户身份登录
+func (p *Principal) Impersonated() bool {
+	return p.ImpersonatorID != 0
+}
+
+// HasScope 登录会话拥有全部权限；个人访问令牌都可以读，写操作需要对应的 scope
+func (p *Principal) HasScope(scope string) bool {
+	if p.Kind != KindPAT || scope == ScopeRead {
+		return true
+	}
+	return slices.Contains(p.Scopes, scope)
+}
@@ -0,0 +1,55 @@
+package auth
+
+import "slices"
+
+// 角色和权限：用户只有一个角色，角色拥有一组权限
+// 作者总是可以修改 / 删除自己的内容，权限决定能否处理别人的内容以及能否使用管理接口
+// 角色存在数据库里（realworld-server roles seed 写入默认角色），权限是代码里的固定集合
+
+// 默认角色
+const (
+	RoleUser      = "user"
+	RoleModerator = "moderator"
+	RoleAdmin     = "admin"
+)
+
+// 权限
+const (
+	PermArticlesUpdateAny = "articles:update_any" // 修改任何人的文章
+	PermArticlesDeleteAny = "articles:delete_any" // 删除任何人的文章
+	PermCommentsDeleteAny = "comments:delete_any" // 删除任何人的评论
+	PermUsersManage       = "users:manage"        // 使用 /api/admin 下的用户管理接口
+)
+
+// Permissions 所有合法的权限
+var Permissions = []string{PermArticlesUpdateAny, PermArticlesDeleteAny, PermCommentsDeleteAny, PermUsersManage}
+
+// ValidPermission 是否为合法的权限
+func ValidPermission(perm string) bool {
+	return slices.Contains(Permissions, perm)
+}
+
+// RoleDefinition 默认角色的定义
+type RoleDefinition struct {
+	Name        string
+	Description string
+	Permissions []string
+}
+
+// DefaultRoles roles seed 写入的角色；重复执行会把这些角色的权限恢复成这里的定义，其他角色不受影响
+var DefaultRoles = []RoleDefinition{
+	{
+		Name:        RoleUser,
+		Description: "Regular user, can only manage their own content",
+	},
+	{
+		Name:        RoleModerator,
+		Description: "Can remove other users' articles and comments",
+		Permissions: []string{PermArticlesDeleteAny, PermCommentsDeleteAny},
+	},
+	{
+		Name:        RoleAdmin,
+		Description: "Full access, including user management",
+		Permissions: Permissions,
+	},
+}
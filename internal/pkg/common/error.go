@@ -1,6 +1,9 @@
 package common
 
-import "errors"
+import (
+	"errors"
+	"time"
+)
 
 var ErrNotFound = errors.New("record not found")
 
@@ -9,3 +12,77 @@ var ErrPermissionDenied = errors.New("permission denied")
 var ErrUserNotFound = errors.New("user not found")
 
 var ErrUserAlreadyExist = errors.New("user already exists")
+
+var ErrInvalidToken = errors.New("invalid or expired token")
+
+var ErrEmailNotVerified = errors.New("email address is not verified")
+
+var ErrInvalidPassword = errors.New("invalid password")
+
+var ErrInvalidCredentials = errors.New("invalid email or password")
+
+var ErrMFAAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+
+var ErrMFANotEnabled = errors.New("two-factor authentication is not enabled")
+
+var ErrInvalidMFACode = errors.New("invalid two-factor authentication code")
+
+var ErrTooManyAttempts = errors.New("too many failed login attempts, try again later")
+
+var ErrAccountLocked = errors.New("account is temporarily locked")
+
+var ErrInvalidScope = errors.New("invalid token scope")
+
+var ErrInsufficientScope = errors.New("token does not have the required scope")
+
+var ErrUnknownProvider = errors.New("unknown identity provider")
+
+var ErrOIDCLoginFailed = errors.New("sign-in with the identity provider failed")
+
+var ErrIdentityAlreadyLinked = errors.New("identity is already linked to an account")
+
+var ErrOIDCEmailInUse = errors.New("an account with this email already exists, sign in and link the provider first")
+
+var ErrLastLoginMethod = errors.New("cannot unlink the only way to sign in, set a password first")
+
+var ErrUnknownRole = errors.New("unknown role")
+
+var ErrAccountSuspended = errors.New("account is suspended")
+
+var ErrAdminTarget = errors.New("this action cannot be performed on an administrator")
+
+var ErrImpersonating = errors.New("this action is not allowed while impersonating a user")
+
+var ErrConfirmationMismatch = errors.New("confirmation does not match, enter your username")
+
+var ErrInvalidArticleStatus = errors.New("invalid article status")
+
+var ErrPublishAtInPast = errors.New("publishAt must be in the future")
+
+var ErrPublishAtWithStatus = errors.New("publishAt cannot be combined with status")
+
+var ErrAlreadyPublished = errors.New("article is already published")
+
+var ErrVersionMismatch = errors.New("resource has been modified, reload and try again")
+
+var ErrInvalidSlug = errors.New("slug may only contain lowercase letters, digits and single hyphens, and cannot be a reserved word")
+
+var ErrSlugTaken = errors.New("slug is already taken")
+
+var ErrInvalidTag = errors.New("tags must be at most 50 characters")
+
+var ErrTooManyTags = errors.New("an article can have at most 10 tags")
+
+// RetryAfterError 需要客户端等待一段时间再重试的错误，handler 据此设置 Retry-After 响应头
+type RetryAfterError struct {
+	Err        error
+	RetryAfter time.Duration
+}
+
+func (e *RetryAfterError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *RetryAfterError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,189 @@
+package diff
+
+// diff 包按行比较两段文本，输出 unified diff（与 diff -u / git diff 相同的格式）
+// 先去掉公共前后缀，剩下的部分用最长公共子序列（LCS）求最小编辑
+
+import (
+	"fmt"
+	"strings"
+)
+
+// contextLines 每个 hunk 前后保留的相同行数
+const contextLines = 3
+
+// maxCells LCS 动态规划表的最大格数，超过时不再求最小编辑，整段按删除 + 新增输出，避免大文本占用过多内存
+const maxCells = 4_000_000
+
+type opKind int
+
+const (
+	opEqual opKind = iota
+	opDelete
+	opInsert
+)
+
+type edit struct {
+	kind opKind
+	line string
+}
+
+// Unified 返回从 a 到 b 的 unified diff，fromFile / toFile 为文件头中显示的名字；没有差异时返回空串
+func Unified(fromFile, toFile, a, b string) string {
+	edits := diffLines(splitLines(a), splitLines(b))
+
+	// 每条编辑之前 a、b 已经走过的行数，用于计算 hunk 头里的行号
+	aPos := make([]int, len(edits)+1)
+	bPos := make([]int, len(edits)+1)
+	for i, e := range edits {
+		aPos[i+1], bPos[i+1] = aPos[i], bPos[i]
+		if e.kind != opInsert {
+			aPos[i+1]++
+		}
+		if e.kind != opDelete {
+			bPos[i+1]++
+		}
+	}
+
+	var buf strings.Builder
+	for i := 0; i < len(edits); {
+		if edits[i].kind == opEqual {
+			i++
+			continue
+		}
+
+		// 1. 向后合并：两处修改之间的相同行不超过 2 倍上下文时放进同一个 hunk
+		end := i
+		for end < len(edits) {
+			if edits[end].kind != opEqual {
+				end++
+				continue
+			}
+			k := end
+			for k < len(edits) && edits[k].kind == opEqual {
+				k++
+			}
+			if k == len(edits) || k-end > 2*contextLines {
+				break
+			}
+			end = k
+		}
+
+		// 2. 前后各加上下文
+		start := max(i-contextLines, 0)
+		stop := min(end+contextLines, len(edits))
+
+		if buf.Len() == 0 {
+			fmt.Fprintf(&buf, "--- %s\n+++ %s\n", fromFile, toFile)
+		}
+		aCount, bCount := aPos[stop]-aPos[start], bPos[stop]-bPos[start]
+		fmt.Fprintf(&buf, "@@ -%s +%s @@\n", hunkRange(aPos[start], aCount), hunkRange(bPos[start], bCount))
+		for _, e := range edits[start:stop] {
+			switch e.kind {
+			case opEqual:
+				buf.WriteString(" ")
+			case opDelete:
+				buf.WriteString("-")
+			case opInsert:
+				buf.WriteString("+")
+			}
+			buf.WriteString(e.line)
+			buf.WriteString("\n")
+		}
+
+		i = stop
+	}
+	return buf.String()
+}
+
+// hunkRange 行号从 1 开始；范围为空时按惯例写它前面那一行的行号
+func hunkRange(pos, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", pos)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", pos+1)
+	}
+	return fmt.Sprintf("%d,%d", pos+1, count)
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+func diffLines(a, b []string) []edit {
+	// 1. 公共前缀和后缀直接算相同
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	edits := make([]edit, 0, len(a)+len(b))
+	for _, line := range a[:prefix] {
+		edits = append(edits, edit{opEqual, line})
+	}
+	// 2. 中间部分求 LCS
+	edits = append(edits, lcs(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, line := range a[len(a)-suffix:] {
+		edits = append(edits, edit{opEqual, line})
+	}
+	return edits
+}
+
+func lcs(a, b []string) []edit {
+	n, m := len(a), len(b)
+	edits := make([]edit, 0, n+m)
+
+	if n*m > maxCells {
+		for _, line := range a {
+			edits = append(edits, edit{opDelete, line})
+		}
+		for _, line := range b {
+			edits = append(edits, edit{opInsert, line})
+		}
+		return edits
+	}
+
+	// dp[i][j] 为 a[i:] 和 b[j:] 的最长公共子序列长度，按一维数组存
+	w := m + 1
+	dp := make([]int32, (n+1)*w)
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				dp[i*w+j] = dp[(i+1)*w+j+1] + 1
+			} else {
+				dp[i*w+j] = max(dp[(i+1)*w+j], dp[i*w+j+1])
+			}
+		}
+	}
+
+	// 回溯：相同时前进一行，否则走 LCS 更长的方向，删除优先于新增
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case a[i] == b[j]:
+			edits = append(edits, edit{opEqual, a[i]})
+			i++
+			j++
+		case dp[(i+1)*w+j] >= dp[i*w+j+1]:
+			edits = append(edits, edit{opDelete, a[i]})
+			i++
+		default:
+			edits = append(edits, edit{opInsert, b[j]})
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		edits = append(edits, edit{opDelete, a[i]})
+	}
+	for ; j < m; j++ {
+		edits = append(edits, edit{opInsert, b[j]})
+	}
+	return edits
+}
@@ -0,0 +1,103 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// numbered 生成 "1\n2\n...n\n"，replace 中的行号替换成给定内容
+func numbered(n int, replace map[int]string) string {
+	var b strings.Builder
+	for i := 1; i <= n; i++ {
+		line, ok := replace[i]
+		if !ok {
+			line = fmt.Sprint(i)
+		}
+		b.WriteString(line + "\n")
+	}
+	return b.String()
+}
+
+func TestUnified(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want string
+	}{
+		{
+			name: "identical",
+			a:    "a\nb\nc\n",
+			b:    "a\nb\nc\n",
+			want: "",
+		},
+		{
+			name: "both empty",
+			want: "",
+		},
+		{
+			name: "pure insert",
+			a:    "a\nb\nc\n",
+			b:    "a\nb\nx\nc\n",
+			want: "--- a\n+++ b\n" +
+				"@@ -1,3 +1,4 @@\n" +
+				" a\n b\n+x\n c\n",
+		},
+		{
+			name: "pure delete",
+			a:    "a\nb\nc\n",
+			b:    "a\nc\n",
+			want: "--- a\n+++ b\n" +
+				"@@ -1,3 +1,2 @@\n" +
+				" a\n-b\n c\n",
+		},
+		{
+			name: "from empty",
+			a:    "",
+			b:    "x\ny\n",
+			want: "--- a\n+++ b\n" +
+				"@@ -0,0 +1,2 @@\n" +
+				"+x\n+y\n",
+		},
+		{
+			name: "to empty",
+			a:    "x\n",
+			b:    "",
+			want: "--- a\n+++ b\n" +
+				"@@ -1 +0,0 @@\n" +
+				"-x\n",
+		},
+		{
+			// 两处修改之间只隔一行，合并成一个 hunk，前后各带 3 行上下文
+			name: "mixed hunk with context",
+			a:    numbered(10, nil),
+			b:    "1\n2\n3\n4\nfive\n6\n6.5\n7\n8\n9\n10\n",
+			want: "--- a\n+++ b\n" +
+				"@@ -2,8 +2,9 @@\n" +
+				" 2\n 3\n 4\n-5\n+five\n 6\n+6.5\n 7\n 8\n 9\n",
+		},
+		{
+			// 相隔超过 2 倍上下文的修改分成两个 hunk
+			name: "separate hunks",
+			a:    numbered(20, nil),
+			b:    numbered(20, map[int]string{2: "two", 18: "eighteen"}),
+			want: "--- a\n+++ b\n" +
+				"@@ -1,5 +1,5 @@\n" +
+				" 1\n-2\n+two\n 3\n 4\n 5\n" +
+				"@@ -15,6 +15,6 @@\n" +
+				" 15\n 16\n 17\n-18\n+eighteen\n 19\n 20\n",
+		},
+	}
+	for _, tc := range tests {
+		if got := Unified("a", "b", tc.a, tc.b); got != tc.want {
+			t.Errorf("%s: got\n%s\nwant\n%s", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestUnifiedMissingFinalNewline(t *testing.T) {
+	// 末尾有没有换行不算差异
+	if got := Unified("a", "b", "a\nb", "a\nb\n"); got != "" {
+		t.Errorf("got\n%s\nwant no diff", got)
+	}
+}
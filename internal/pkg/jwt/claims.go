@@ -1,9 +1,31 @@
 package jwt
 
-import "github.com/golang-jwt/jwt/v5"
+import (
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// token 类型，写在 typ claim 中，防止一种 token 被当成另一种使用
+const (
+	TypeAccess     = "access"
+	TypeMFAPending = "mfa_pending" // 密码已验证、等待两步验证码的临时 token
+)
+
+// AccessTokenHeaderType access token 的 JOSE header typ（RFC 9068），其他 token 仍是默认的 "JWT"；
+// 用 JWKS 验签的下游服务应检查这个 header，不能把两步验证的临时 token 当成 access token
+const AccessTokenHeaderType = "at+jwt"
+
+// LegacyTokenDeadline 加入 typ / sid 之前签发的旧 access token（没有 typ，也没有会话）在此之前仍然接受，
+// 之后一律拒绝。旧版本的 token 有效期默认 24 小时，留出的时间足够它们自然过期；过了这一天可以删掉兼容分支
+var LegacyTokenDeadline = time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
 
 // 这里的Claims 的 claim是jwt中的概念
 type Claims struct {
 	UserID int64 `json:"uid"`
+	// SessionID 签发该 token 的登录会话，用于登出 / 撤销
+	SessionID int64 `json:"sid,omitempty"`
+	// Type 为空的是加入 typ 之前签发的 access token，只在 LegacyTokenDeadline 之前接受
+	Type string `json:"typ,omitempty"`
 	jwt.RegisteredClaims
 }
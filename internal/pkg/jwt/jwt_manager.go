@@ -2,16 +2,25 @@ package jwt
 
 import (
 	"errors"
+	"fmt"
+	"sort"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
 type jwtManager struct {
-	secret     []byte
+	// 对称签名（HS256）时使用
+	secret []byte
+
+	// 非对称签名时使用：signing 负责签发，keys 里的所有密钥都可以验签（kid -> key）
+	signing *Key
+	keys    map[string]*Key
+
 	expireTime time.Duration
 }
 
+// NewManager 使用 HS256 共享密钥签名
 func NewManager(secret string, expire time.Duration) Manager {
 	return &jwtManager{
 		secret:     []byte(secret),
@@ -19,38 +28,136 @@ func NewManager(secret string, expire time.Duration) Manager {
 	}
 }
 
-func (m *jwtManager) Generate(userID int64) (string, error) {
-	claims := Claims{
+// NewKeyManager 使用非对称密钥签名，token header 中带 kid
+// signing 必须包含私钥；verify 为同时生效的验签密钥（轮换期间的旧公钥），signing 会自动加入其中
+func NewKeyManager(signing *Key, verify []*Key, expire time.Duration) (Manager, error) {
+	if signing == nil || signing.Private == nil {
+		return nil, errors.New("jwt: signing key must have a private key")
+	}
+
+	keys := make(map[string]*Key, len(verify)+1)
+	for _, k := range append(verify, signing) {
+		if other, ok := keys[k.ID]; ok && other != k {
+			return nil, fmt.Errorf("jwt: duplicate key id %q", k.ID)
+		}
+		keys[k.ID] = k
+	}
+
+	return &jwtManager{
+		signing:    signing,
+		keys:       keys,
+		expireTime: expire,
+	}, nil
+}
+
+func (m *jwtManager) Generate(userID int64, sessionID int64) (string, error) {
+	return m.sign(Claims{
+		UserID:    userID,
+		SessionID: sessionID,
+		Type:      TypeAccess,
+	}, m.expireTime, AccessTokenHeaderType)
+}
+
+func (m *jwtManager) Parse(tokenStr string) (*Claims, error) {
+	claims, err := m.parse(tokenStr)
+	if err != nil {
+		return nil, err
+	}
+	switch claims.Type {
+	case TypeAccess:
+		return claims, nil
+	case "":
+		// 旧版本签发的 token，过渡期内仍然接受
+		if time.Now().Before(LegacyTokenDeadline) {
+			return claims, nil
+		}
+	}
+	return nil, errors.New("invalid token type")
+}
+
+func (m *jwtManager) GenerateMFAToken(userID int64, ttl time.Duration) (string, error) {
+	return m.sign(Claims{
 		UserID: userID,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(m.expireTime)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-		},
+		Type:   TypeMFAPending,
+	}, ttl, "JWT")
+}
+
+func (m *jwtManager) ParseMFAToken(tokenStr string) (*Claims, error) {
+	claims, err := m.parse(tokenStr)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Type != TypeMFAPending {
+		return nil, errors.New("invalid token type")
 	}
+	return claims, nil
+}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(m.secret)
+// sign 签发 token，headerType 写在 JOSE header 的 typ 中
+func (m *jwtManager) sign(claims Claims, ttl time.Duration, headerType string) (string, error) {
+	now := time.Now()
+	claims.RegisteredClaims = jwt.RegisteredClaims{
+		ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		IssuedAt:  jwt.NewNumericDate(now),
+	}
+
+	if m.signing == nil {
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+		token.Header["typ"] = headerType
+		return token.SignedString(m.secret)
+	}
+
+	token := jwt.NewWithClaims(m.signing.Method, claims)
+	token.Header["typ"] = headerType
+	token.Header["kid"] = m.signing.ID
+	return token.SignedString(m.signing.Private)
 }
 
-func (m *jwtManager) Parse(tokenStr string) (int64, error) {
+func (m *jwtManager) parse(tokenStr string) (*Claims, error) {
 	token, err := jwt.ParseWithClaims(
 		tokenStr,
 		&Claims{},
-		func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, errors.New("unexpected signing method")
-			}
-			return m.secret, nil
-		},
+		m.keyFunc,
 	)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
 
 	claims, ok := token.Claims.(*Claims) // 强转为预定义的Claims
 	if !ok || !token.Valid {
-		return 0, errors.New("invalid token")
+		return nil, errors.New("invalid token")
 	}
 
-	return claims.UserID, nil
+	return claims, nil
+}
+
+// keyFunc 根据 header 中的 kid 选择验签密钥，并校验算法与密钥匹配，防止算法混淆攻击
+func (m *jwtManager) keyFunc(token *jwt.Token) (interface{}, error) {
+	if m.signing == nil {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, errors.New("unexpected signing method")
+		}
+		return m.secret, nil
+	}
+
+	kid, _ := token.Header["kid"].(string)
+	key, ok := m.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown key id %q", kid)
+	}
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, errors.New("unexpected signing method")
+	}
+	return key.Public, nil
+}
+
+func (m *jwtManager) JWKS() JWKS {
+	jwks := JWKS{Keys: make([]JWK, 0, len(m.keys))}
+	for _, k := range m.keys {
+		jwks.Keys = append(jwks.Keys, k.JWK())
+	}
+	sort.Slice(jwks.Keys, func(i, j int) bool {
+		return jwks.Keys[i].Kid < jwks.Keys[j].Kid
+	})
+	return jwks
 }
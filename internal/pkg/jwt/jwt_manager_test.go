@@ -0,0 +1,92 @@
+package jwt
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestManagerTokenTypes(t *testing.T) {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := &Key{ID: "test", Method: jwt.SigningMethodEdDSA, Private: priv, Public: priv.Public()}
+	keyMgr, err := NewKeyManager(key, nil, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, m := range map[string]Manager{"HS256": NewManager("secret", time.Minute), "EdDSA": keyMgr} {
+		t.Run(name, func(t *testing.T) {
+			// 1. access token 的 header typ 是 at+jwt，只能当 access token 用
+			access, err := m.Generate(1, 2)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if typ := headerType(t, access); typ != AccessTokenHeaderType {
+				t.Errorf("access token typ header = %q", typ)
+			}
+			claims, err := m.Parse(access)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if claims.UserID != 1 || claims.SessionID != 2 || claims.Type != TypeAccess {
+				t.Errorf("claims = %+v", claims)
+			}
+			if _, err := m.ParseMFAToken(access); err == nil {
+				t.Error("access token accepted as mfa token")
+			}
+
+			// 2. 两步验证的临时 token 用同一个密钥签名，但 header typ 不同，也不能当 access token 用
+			pending, err := m.GenerateMFAToken(1, time.Minute)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if typ := headerType(t, pending); typ == AccessTokenHeaderType {
+				t.Errorf("mfa token typ header = %q", typ)
+			}
+			if _, err := m.Parse(pending); err == nil {
+				t.Error("mfa token accepted as access token")
+			}
+			if _, err := m.ParseMFAToken(pending); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestManagerLegacyToken(t *testing.T) {
+	m := NewManager("secret", time.Minute).(*jwtManager)
+	deadline := LegacyTokenDeadline
+	t.Cleanup(func() { LegacyTokenDeadline = deadline })
+
+	// 旧版本签发的 token 没有 typ 和 sid
+	legacy, err := m.sign(Claims{UserID: 1}, time.Minute, "JWT")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	LegacyTokenDeadline = time.Now().Add(time.Hour)
+	if _, err := m.Parse(legacy); err != nil {
+		t.Errorf("legacy token before the deadline: %v", err)
+	}
+	LegacyTokenDeadline = time.Now().Add(-time.Hour)
+	if _, err := m.Parse(legacy); err == nil {
+		t.Error("legacy token accepted after the deadline")
+	}
+}
+
+// headerType 不验签读出 token header 中的 typ
+func headerType(t *testing.T, raw string) string {
+	t.Helper()
+	token, _, err := jwt.NewParser().ParseUnverified(raw, &Claims{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	typ, _ := token.Header["typ"].(string)
+	return typ
+}
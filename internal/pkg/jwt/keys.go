@@ -0,0 +1,167 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Key 一把签名 / 验签密钥，ID 写入 token header 的 kid
+// 算法由密钥类型决定：RSA -> RS256，Ed25519 -> EdDSA；只有公钥的 Key 只能用来验签（轮换后的旧密钥）
+type Key struct {
+	ID      string
+	Method  jwt.SigningMethod
+	Private crypto.Signer
+	Public  crypto.PublicKey
+}
+
+// LoadKey 从 PEM 文件加载密钥，privateFile 和 publicFile 至少提供一个
+// 私钥支持 PKCS#8 / PKCS#1(RSA)，公钥支持 PKIX
+func LoadKey(id, privateFile, publicFile string) (*Key, error) {
+	if id == "" {
+		return nil, errors.New("jwt key: id is required")
+	}
+
+	key := &Key{ID: id}
+	switch {
+	case privateFile != "":
+		block, err := readPEM(privateFile)
+		if err != nil {
+			return nil, err
+		}
+		priv, err := parsePrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("jwt key %s: %w", id, err)
+		}
+		key.Private = priv
+		key.Public = priv.Public()
+	case publicFile != "":
+		block, err := readPEM(publicFile)
+		if err != nil {
+			return nil, err
+		}
+		pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("jwt key %s: %w", id, err)
+		}
+		key.Public = pub
+	default:
+		return nil, fmt.Errorf("jwt key %s: private_key_file or public_key_file is required", id)
+	}
+
+	switch key.Public.(type) {
+	case *rsa.PublicKey:
+		key.Method = jwt.SigningMethodRS256
+	case ed25519.PublicKey:
+		key.Method = jwt.SigningMethodEdDSA
+	default:
+		return nil, fmt.Errorf("jwt key %s: unsupported key type %T", id, key.Public)
+	}
+	return key, nil
+}
+
+func readPEM(file string) (*pem.Block, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s: no PEM data found", file)
+	}
+	return block, nil
+}
+
+func parsePrivateKey(der []byte) (crypto.Signer, error) {
+	if key, err := x509.ParsePKCS8PrivateKey(der); err == nil {
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("unsupported private key type %T", key)
+		}
+		return signer, nil
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(der); err == nil {
+		return key, nil
+	}
+	return nil, errors.New("unsupported private key format, expected PKCS#8 or PKCS#1")
+}
+
+// ---- JWKS ----
+
+// JWK 公钥的 JSON Web Key 表示（RFC 7517）
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+
+	// OKP (Ed25519)
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JWKS GET /.well-known/jwks.json 的响应体
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWK 公钥的 JWK 表示
+func (k *Key) JWK() JWK {
+	j := JWK{Kid: k.ID, Alg: k.Method.Alg(), Use: "sig"}
+	switch pub := k.Public.(type) {
+	case *rsa.PublicKey:
+		j.Kty = "RSA"
+		j.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+		j.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+	case ed25519.PublicKey:
+		j.Kty = "OKP"
+		j.Crv = "Ed25519"
+		j.X = base64.RawURLEncoding.EncodeToString(pub)
+	}
+	return j
+}
+
+// PublicKey 从 JWK 还原公钥，用于校验第三方（OIDC provider）签发的 token
+// 支持 RSA 和 Ed25519
+func (j JWK) PublicKey() (crypto.PublicKey, error) {
+	switch j.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(j.N)
+		if err != nil {
+			return nil, fmt.Errorf("jwk %s: invalid modulus: %w", j.Kid, err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(j.E)
+		if err != nil {
+			return nil, fmt.Errorf("jwk %s: invalid exponent: %w", j.Kid, err)
+		}
+		exp := new(big.Int).SetBytes(e)
+		if !exp.IsInt64() || exp.Int64() > 1<<31-1 || exp.Int64() < 3 {
+			return nil, fmt.Errorf("jwk %s: invalid exponent", j.Kid)
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exp.Int64())}, nil
+	case "OKP":
+		if j.Crv != "Ed25519" {
+			return nil, fmt.Errorf("jwk %s: unsupported curve %q", j.Kid, j.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(j.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("jwk %s: invalid public key", j.Kid)
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, fmt.Errorf("jwk %s: unsupported key type %q", j.Kid, j.Kty)
+	}
+}
@@ -1,6 +1,17 @@
 package jwt
 
+import "time"
+
 type Manager interface {
-	Generate(userID int64) (string, error)
-	Parse(token string) (int64, error)
+	// Generate 为某个会话签发短期 access token
+	Generate(userID int64, sessionID int64) (string, error)
+	// Parse 解析 access token，其他类型的 token 一律视为无效
+	Parse(token string) (*Claims, error)
+
+	// GenerateMFAToken 签发等待两步验证的临时 token，只能用来换取 access token
+	GenerateMFAToken(userID int64, ttl time.Duration) (string, error)
+	ParseMFAToken(token string) (*Claims, error)
+
+	// JWKS 返回所有验签公钥，HS256 时为空
+	JWKS() JWKS
 }
@@ -0,0 +1,73 @@
+package mailer
+
+// mailer 包负责发送邮件
+// 生产环境用 SMTP，本地开发 / 测试用 file（写 .eml 文件）或 log（打到日志），无需真实邮件服务
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// 支持的驱动
+const (
+	DriverSMTP = "smtp"
+	DriverFile = "file"
+	DriverLog  = "log"
+)
+
+// Message 一封纯文本邮件
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// render 生成 RFC 5322 格式的邮件内容
+func render(from string, msg Message) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", msg.Subject)
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+	return []byte(b.String())
+}
+
+// Config 发信配置
+type Config struct {
+	Driver string
+	From   string
+
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+
+	FileDir string
+}
+
+// New 根据 Driver 创建发信实现，Driver 为空时只打日志
+func New(cfg Config) (Mailer, error) {
+	switch cfg.Driver {
+	case DriverSMTP:
+		if cfg.SMTPHost == "" {
+			return nil, fmt.Errorf("mailer: smtp host is required")
+		}
+		return NewSMTPMailer(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.From), nil
+	case DriverFile:
+		return NewFileMailer(cfg.FileDir, cfg.From)
+	case "", DriverLog:
+		return NewLogMailer(cfg.From), nil
+	default:
+		return nil, fmt.Errorf("mailer: unsupported driver %q", cfg.Driver)
+	}
+}
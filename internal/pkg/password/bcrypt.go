@@ -1,21 +1,50 @@
 package password
 
-import "golang.org/x/crypto/bcrypt"
+import (
+	"strings"
 
-const defaultCost = bcrypt.DefaultCost
+	"golang.org/x/crypto/bcrypt"
+)
 
-func bcryptHash(plain string) (string, error) {
-	bytes, err := bcrypt.GenerateFromPassword([]byte(plain), defaultCost)
+// bcrypt 自带 $2a$<cost>$... 的模块化格式，与 PHC 前缀风格兼容，直接沿用
+
+type bcryptHasher struct {
+	cost int
+}
+
+func newBcryptHasher(cost int) *bcryptHasher {
+	if cost < bcrypt.MinCost {
+		cost = bcrypt.DefaultCost
+	}
+	return &bcryptHasher{cost: cost}
+}
+
+func (h *bcryptHasher) Hash(plain string) (string, error) {
+	bytes, err := bcrypt.GenerateFromPassword([]byte(plain), h.cost)
 	if err != nil {
 		return "", err
 	}
 	return string(bytes), nil
 }
 
-func bcryptVerify(hash string, plain string) bool {
+func (h *bcryptHasher) Verify(hash string, plain string) bool {
 	err := bcrypt.CompareHashAndPassword(
 		[]byte(hash),
 		[]byte(plain),
 	)
 	return err == nil
 }
+
+func (h *bcryptHasher) NeedsRehash(hash string) bool {
+	cost, err := bcrypt.Cost([]byte(hash))
+	if err != nil {
+		return true
+	}
+	return cost < h.cost
+}
+
+func (h *bcryptHasher) Match(hash string) bool {
+	return strings.HasPrefix(hash, "$2a$") ||
+		strings.HasPrefix(hash, "$2b$") ||
+		strings.HasPrefix(hash, "$2y$")
+}
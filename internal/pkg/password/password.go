@@ -1,9 +1,91 @@
 package password
 
-func Hash(plain string) (string, error) {
-	return bcryptHash(plain)
+// password 包负责密码哈希
+// 哈希结果统一使用 PHC 字符串格式（$<算法>$<参数>$...），算法从字符串前缀识别，
+// 因此可以同时校验多种算法的历史哈希，并在登录成功后升级到当前配置的算法 / 参数
+
+import (
+	"fmt"
+	"strings"
+)
+
+// 支持的算法
+const (
+	AlgorithmArgon2id = "argon2id"
+	AlgorithmBcrypt   = "bcrypt"
+)
+
+// Hasher 一种密码哈希算法
+type Hasher interface {
+	// Hash 生成 PHC 格式的哈希
+	Hash(plain string) (string, error)
+	// Verify 校验明文与哈希是否匹配
+	Verify(hash string, plain string) bool
+	// NeedsRehash 哈希的参数弱于当前配置时返回 true
+	NeedsRehash(hash string) bool
+	// Match 判断哈希是否由该算法生成
+	Match(hash string) bool
+}
+
+// Config 哈希配置，Algorithm 为新密码使用的算法
+type Config struct {
+	Algorithm  string
+	Argon2     Argon2Params
+	BcryptCost int
+}
+
+// manager 用首选算法生成哈希，按前缀把校验分发给对应算法
+type manager struct {
+	preferred Hasher
+	hashers   []Hasher
+}
+
+// NewHasher 根据配置创建 Hasher，所有支持的算法都可以用于校验
+func NewHasher(cfg Config) (Hasher, error) {
+	argon := newArgon2idHasher(cfg.Argon2)
+	bc := newBcryptHasher(cfg.BcryptCost)
+
+	m := &manager{hashers: []Hasher{argon, bc}}
+	switch strings.ToLower(cfg.Algorithm) {
+	case "", AlgorithmArgon2id:
+		m.preferred = argon
+	case AlgorithmBcrypt:
+		m.preferred = bc
+	default:
+		return nil, fmt.Errorf("unsupported password algorithm: %q", cfg.Algorithm)
+	}
+	return m, nil
+}
+
+func (m *manager) Hash(plain string) (string, error) {
+	return m.preferred.Hash(plain)
+}
+
+func (m *manager) Verify(hash string, plain string) bool {
+	h := m.find(hash)
+	if h == nil {
+		return false
+	}
+	return h.Verify(hash, plain)
+}
+
+// NeedsRehash 算法不是首选算法，或参数弱于当前配置
+func (m *manager) NeedsRehash(hash string) bool {
+	if !m.preferred.Match(hash) {
+		return true
+	}
+	return m.preferred.NeedsRehash(hash)
+}
+
+func (m *manager) Match(hash string) bool {
+	return m.find(hash) != nil
 }
 
-func Verify(hash string, plain string) bool {
-	return bcryptVerify(hash, plain)
+func (m *manager) find(hash string) Hasher {
+	for _, h := range m.hashers {
+		if h.Match(hash) {
+			return h
+		}
+	}
+	return nil
 }
@@ -0,0 +1,147 @@
+package slug
+
+// slug 包把文章标题转换成 URL 安全的 slug：只含小写 ASCII 字母、数字和单个连字符
+// 转换是确定的，同一个标题总是得到同一个 slug；重名时由调用方用 WithSuffix 加随机后缀区分
+//
+// 1. NFKD 分解后去掉附加符号：é -> e，全角 ＡＢＣ -> abc，ﬁ -> fi
+// 2. 不能分解的拉丁字母以及西里尔、希腊字母按表转写：ß -> ss，ж -> zh，θ -> th
+// 3. 汉字可选转为不带声调的拼音，每个字一段：你好 -> ni-hao
+// 4. 撇号直接去掉（don't -> dont），其余字符（标点、空白、emoji 等）都当作分隔符
+
+import (
+	"fmt"
+	"github/CiroLong/realworld-gin/internal/pkg/utils"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/mozillazg/go-pinyin"
+	"golang.org/x/text/unicode/norm"
+)
+
+// DefaultMaxLength 未配置时 slug 的最大长度
+const DefaultMaxLength = 100
+
+const (
+	// minMaxLength 最大长度的下限，加上后缀后标题部分还要留有足够的长度
+	minMaxLength = 20
+	// maxMaxLength articles.slug 的列宽
+	maxMaxLength = 255
+	// suffixLength 重名时随机后缀的长度，36^6 约 21 亿种
+	suffixLength = 6
+	// fallback 标题里没有任何可转换的字符时使用
+	fallback = "article"
+)
+
+// pattern 合法的 slug：小写字母和数字，用单个连字符分隔
+var pattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// reserved 和 /api/articles 下的固定路由或前端页面同名，不能作为 slug
+var reserved = map[string]bool{
+	"feed":      true,
+	"scheduled": true,
+	"new":       true,
+	"edit":      true,
+}
+
+// Options 生成规则
+type Options struct {
+	// MaxLength slug 最大长度，0 表示 DefaultMaxLength
+	MaxLength int
+	// Pinyin 汉字转拼音；关闭时汉字和其他无法转写的字符一样当作分隔符
+	Pinyin bool
+}
+
+// Slugifier 按配置生成和校验 slug，创建后只读，可并发使用
+type Slugifier struct {
+	maxLength int
+	pinyin    bool
+	args      pinyin.Args
+}
+
+// New 创建 Slugifier，MaxLength 超出允许范围时返回错误
+func New(opts Options) (*Slugifier, error) {
+	if opts.MaxLength == 0 {
+		opts.MaxLength = DefaultMaxLength
+	}
+	if opts.MaxLength < minMaxLength || opts.MaxLength > maxMaxLength {
+		return nil, fmt.Errorf("slug max length must be between %d and %d, got %d", minMaxLength, maxMaxLength, opts.MaxLength)
+	}
+	return &Slugifier{
+		maxLength: opts.MaxLength,
+		pinyin:    opts.Pinyin,
+		args:      pinyin.NewArgs(),
+	}, nil
+}
+
+// Make 由标题生成 slug，不检查是否重名，也不排除保留字
+func (s *Slugifier) Make(title string) string {
+	var b strings.Builder
+	pending := false // 下一段之前要不要写连字符
+	emit := func(part string) {
+		if pending && b.Len() > 0 {
+			b.WriteByte('-')
+		}
+		pending = false
+		b.WriteString(part)
+	}
+
+	for _, r := range norm.NFKD.String(title) {
+		r = unicode.ToLower(r)
+		switch {
+		case 'a' <= r && r <= 'z', '0' <= r && r <= '9':
+			emit(string(r))
+		case unicode.Is(unicode.Mn, r), r == '\'', r == '’':
+			// 附加符号和撇号去掉，不断词
+		case s.pinyin && unicode.Is(unicode.Han, r):
+			pending = true
+			if pys := pinyin.SinglePinyin(r, s.args); len(pys) > 0 {
+				emit(pys[0])
+				pending = true
+			}
+		default:
+			if t, ok := transliterations[r]; ok {
+				emit(t)
+			} else {
+				pending = true
+			}
+		}
+	}
+
+	if slug := truncate(b.String(), s.maxLength); slug != "" {
+		return slug
+	}
+	return fallback
+}
+
+// WithSuffix 在 base 后加随机后缀区分重名，总长度不超过最大长度
+func (s *Slugifier) WithSuffix(base string) string {
+	return truncate(base, s.maxLength-suffixLength-1) + "-" + utils.RandString(suffixLength)
+}
+
+// Valid 作者自定义的 slug 是否合法：格式正确、不超过最大长度、不是保留字
+func (s *Slugifier) Valid(slug string) bool {
+	return len(slug) <= s.maxLength && pattern.MatchString(slug) && !Reserved(slug)
+}
+
+// MaxLength 最大长度
+func (s *Slugifier) MaxLength() int {
+	return s.maxLength
+}
+
+// Reserved 是否是保留字
+func Reserved(slug string) bool {
+	return reserved[slug]
+}
+
+// truncate 截断到 n 个字节以内（slug 只有 ASCII），尽量在连字符处断开，不留末尾的连字符
+func truncate(slug string, n int) string {
+	if len(slug) <= n {
+		return slug
+	}
+	slug = slug[:n]
+	if i := strings.LastIndexByte(slug, '-'); i >= n/2 {
+		slug = slug[:i]
+	}
+	return strings.TrimRight(slug, "-")
+}
@@ -0,0 +1,161 @@
+package slug
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMake(t *testing.T) {
+	plain, err := New(Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	withPinyin, err := New(Options{Pinyin: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		s     *Slugifier
+		title string
+		want  string
+	}{
+		{"ascii", plain, "How to Train Your Dragon", "how-to-train-your-dragon"},
+		{"accents", plain, "Café crème brûlée", "cafe-creme-brulee"},
+		{"full width", plain, "ＧＯ　１２３", "go-123"},
+		{"sharp s", plain, "Straße", "strasse"},
+		{"cyrillic", plain, "Журнал", "zhurnal"},
+		{"greek", plain, "Θέατρο", "theatro"},
+		{"apostrophe", plain, "Don't stop", "dont-stop"},
+		{"typographic apostrophe", plain, "Rock’n’roll", "rocknroll"},
+		{"punctuation and emoji", plain, "Go -- is  fun!! 🎉 really?", "go-is-fun-really"},
+		{"han without pinyin", plain, "Go 你好 world", "go-world"},
+		{"han with pinyin", withPinyin, "Go 你好 world", "go-ni-hao-world"},
+		{"han only with pinyin", withPinyin, "你好", "ni-hao"},
+		{"empty", plain, "", fallback},
+		{"symbols only", plain, "!!! ??? ---", fallback},
+		{"han only without pinyin", plain, "你好", fallback},
+	}
+	for _, tc := range tests {
+		if got := tc.s.Make(tc.title); got != tc.want {
+			t.Errorf("%s: Make(%q) = %q, want %q", tc.name, tc.title, got, tc.want)
+		}
+	}
+}
+
+func TestMakeTruncates(t *testing.T) {
+	s, err := New(Options{MaxLength: 20})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		title string
+		want  string
+	}{
+		// 在最后一个连字符处断开，不留半个单词
+		{"alpha beta gamma delta epsilon", "alpha-beta-gamma"},
+		// 连字符太靠前（不到一半）时直接按长度截断
+		{"go abcdefghijklmnopqrstuvwxyz", "go-abcdefghijklmnopq"},
+		// 截断后不留末尾的连字符
+		{"abcdefghijklmnopqrs tuv", "abcdefghijklmnopqrs"},
+		{"short title", "short-title"},
+	}
+	for _, tc := range tests {
+		got := s.Make(tc.title)
+		if got != tc.want {
+			t.Errorf("Make(%q) = %q, want %q", tc.title, got, tc.want)
+		}
+		if len(got) > s.MaxLength() {
+			t.Errorf("Make(%q) = %q, longer than %d", tc.title, got, s.MaxLength())
+		}
+	}
+}
+
+func TestWithSuffix(t *testing.T) {
+	s, err := New(Options{MaxLength: 30})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		base     string
+		wantBase string
+	}{
+		{"short", "short"},
+		{"a-very-long-title-that-will-not-fit", "a-very-long-title-that"},
+	}
+	for _, tc := range tests {
+		got := s.WithSuffix(tc.base)
+		base, suffix, ok := strings.Cut(got, tc.wantBase+"-")
+		if !ok || base != "" || len(suffix) != suffixLength {
+			t.Errorf("WithSuffix(%q) = %q, want %q plus a %d-character suffix", tc.base, got, tc.wantBase, suffixLength)
+		}
+		if len(got) > s.MaxLength() || !s.Valid(got) {
+			t.Errorf("WithSuffix(%q) = %q is not a valid slug of at most %d bytes", tc.base, got, s.MaxLength())
+		}
+	}
+	if a, b := s.WithSuffix("post"), s.WithSuffix("post"); a == b {
+		t.Errorf("WithSuffix returned %q twice", a)
+	}
+}
+
+func TestNewMaxLength(t *testing.T) {
+	for _, n := range []int{-1, minMaxLength - 1, maxMaxLength + 1} {
+		if _, err := New(Options{MaxLength: n}); err == nil {
+			t.Errorf("New(MaxLength: %d) succeeded", n)
+		}
+	}
+	s, err := New(Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.MaxLength() != DefaultMaxLength {
+		t.Errorf("MaxLength() = %d, want %d", s.MaxLength(), DefaultMaxLength)
+	}
+}
+
+func TestValidAndReserved(t *testing.T) {
+	s, err := New(Options{MaxLength: 20})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		slug  string
+		valid bool
+	}{
+		{"my-post", true},
+		{"post-2", true},
+		{"a", true},
+		{"", false},
+		{"My-Post", false},
+		{"my--post", false},
+		{"-my-post", false},
+		{"my-post-", false},
+		{"my_post", false},
+		{"café", false},
+		{strings.Repeat("a", 20), true},
+		{strings.Repeat("a", 21), false},
+		{"feed", false},
+		{"scheduled", false},
+		{"new", false},
+		{"edit", false},
+		{"feeds", true},
+	}
+	for _, tc := range tests {
+		if got := s.Valid(tc.slug); got != tc.valid {
+			t.Errorf("Valid(%q) = %v, want %v", tc.slug, got, tc.valid)
+		}
+	}
+
+	for _, slug := range []string{"feed", "scheduled", "new", "edit"} {
+		if !Reserved(slug) {
+			t.Errorf("Reserved(%q) = false", slug)
+		}
+	}
+	if Reserved("my-post") {
+		t.Error(`Reserved("my-post") = true`)
+	}
+}
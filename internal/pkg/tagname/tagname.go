@@ -0,0 +1,23 @@
+package tagname
+
+// tagname 包规范化标签名，写入时（service）、按标签查询时和迁移旧数据时（repository）使用同一套规则
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// MaxLength 规范化后标签的最大字符数，tags.name 的列宽
+const MaxLength = 50
+
+// Normalize NFKC 统一全角等写法，转小写，去掉首尾空白，中间连续的空白合并成一个空格
+func Normalize(name string) string {
+	return strings.Join(strings.Fields(strings.ToLower(norm.NFKC.String(name))), " ")
+}
+
+// Valid 规范化后的标签是否可以保存：非空且不超过 MaxLength 个字符
+func Valid(name string) bool {
+	return name != "" && utf8.RuneCountInString(name) <= MaxLength
+}
@@ -0,0 +1,30 @@
+package token
+
+// token 包负责生成不透明的随机令牌（refresh token 等）
+// 令牌明文只下发给客户端一次，数据库里只保存 sha256 摘要
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+)
+
+// defaultSize 随机字节数，256 bit
+const defaultSize = 32
+
+// Generate 生成一个随机令牌，返回明文（base64url）和用于存储的摘要
+func Generate() (plain string, hash string, err error) {
+	b := make([]byte, defaultSize)
+	if _, err = rand.Read(b); err != nil {
+		return "", "", err
+	}
+	plain = base64.RawURLEncoding.EncodeToString(b)
+	return plain, Hash(plain), nil
+}
+
+// Hash 计算令牌摘要（hex 编码的 sha256），用于入库和查找
+func Hash(plain string) string {
+	sum := sha256.Sum256([]byte(plain))
+	return hex.EncodeToString(sum[:])
+}
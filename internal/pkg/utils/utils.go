@@ -1,29 +1,22 @@
 package utils
 
 import (
-	"strings"
-	"time"
+	"crypto/rand"
+	"math/big"
 )
 
-// 生成文章Slug
-func GenerateSlug(title string) string {
-	t := strings.ToLower(title)
-	t = strings.ReplaceAll(t, " ", "-")
-	// 可进一步加随机字符串保证唯一性
-	return t + "-" + RandString(6)
-}
-
-// 生成随机 6 位字符串（简单示例）
+// RandString 生成 n 位由小写字母和数字组成的随机串，使用 crypto/rand，连续调用也不会相同
 func RandString(n int) string {
-	letters := []rune("abcdefghijklmnopqrstuvwxyz0123456789")
-	b := make([]rune, n)
+	const letters = "abcdefghijklmnopqrstuvwxyz0123456789"
+	size := big.NewInt(int64(len(letters)))
+	b := make([]byte, n)
 	for i := range b {
-		b[i] = letters[RandInt(len(letters))]
+		idx, err := rand.Int(rand.Reader, size)
+		if err != nil {
+			// 系统随机数源不可用时无法继续安全地工作
+			panic(err)
+		}
+		b[i] = letters[idx.Int64()]
 	}
 	return string(b)
 }
-
-// 简单随机数生成
-func RandInt(max int) int {
-	return int(time.Now().UnixNano() % int64(max))
-}
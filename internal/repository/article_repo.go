@@ -3,12 +3,25 @@ package repository
 import (
 	"context"
 	"github/CiroLong/realworld-gin/internal/model/entity"
+	"time"
 )
 
 type ListArticlesFilter struct {
 	Tag         *string
 	Author      *string
 	FavoritedBy *string
+	// Statuses 为空时只返回已发布的文章
+	Statuses []string
+
+	// ExcludeSuspendedAuthors 不返回被停用账号的文章
+	ExcludeSuspendedAuthors bool
+
+	Limit  int
+	Offset int
+}
+
+type FeedFilter struct {
+	ExcludeSuspendedAuthors bool
 
 	Limit  int
 	Offset int
@@ -17,33 +30,66 @@ type ListArticlesFilter struct {
 type ArticleRepo interface {
 	// ---- Article 相关 ----
 
-	// Create 创建文章
+	// Create 创建文章，slug 由调用方生成，被其他文章占用时返回 ErrSlugTaken
 	Create(ctx context.Context, article *entity.Article) error
+	// CreateWithRevisionAndTags 创建文章、第一版修订和标签（已规范化，不存在的自动创建），全部在同一个事务里；
+	// 修订的文章 id 和创建时间取自新建的文章，slug 冲突同 Create
+	CreateWithRevisionAndTags(ctx context.Context, article *entity.Article, revision *entity.ArticleRevision, tags []string) error
 	// FindBySlug 根据 slug 查询文章
 	FindBySlug(ctx context.Context, slug string) (*entity.Article, error)
-	// Update 更新文章
+	// FindByIDs 批量查询文章，不存在的 id 直接忽略
+	FindByIDs(ctx context.Context, ids []int64) ([]*entity.Article, error)
+	// Update 更新文章，以 article.Version（读到的版本）为条件，成功后版本加一；期间被别人改过返回 ErrVersionMismatch
+	// slug 有变化时旧 slug 记入历史，新 slug 被其他文章占用时返回 ErrSlugTaken
 	Update(ctx context.Context, article *entity.Article) error
-	// Delete 删除文章
-	Delete(ctx context.Context, articleID int64) error
+	// Delete 删除文章，文章版本不是 version 时返回 ErrVersionMismatch
+	Delete(ctx context.Context, articleID int64, version int64) error
 
-	// List 公开文章列表（支持多条件）
+	// List 文章列表（支持多条件），按发布时间倒序
 	List(ctx context.Context, query ListArticlesFilter) ([]*entity.Article, int64, error)
-	// Feed 关注者文章流
-	Feed(ctx context.Context, userID int64, limit, offset int) ([]*entity.Article, int64, error)
+	// Feed 关注者文章流，只包含已发布的文章
+	Feed(ctx context.Context, userID int64, query FeedFilter) ([]*entity.Article, int64, error)
+	// ListScheduled 作者的定时发布文章，按发布时间先后排序
+	ListScheduled(ctx context.Context, authorID int64, limit, offset int) ([]*entity.Article, int64, error)
+	// PublishDue 发布 publish_at 不晚于 now 的定时文章，最多 limit 篇，返回本次发布的文章；
+	// 逐篇条件更新，多个副本同时执行时每篇文章只会被其中一个发布
+	PublishDue(ctx context.Context, now time.Time, limit int) ([]*entity.Article, error)
 
 	// 这里塞入 tag 和 favorite : Tag / Favorite 是article内部关系
 	// ---- Tag 相关 ----
 
-	// GetOrCreateTags 根据 tag 名称获取或创建
+	// GetOrCreateTags 根据 tag 名称获取或创建，按 names 的顺序返回，并发创建同名标签时不会报错
 	GetOrCreateTags(ctx context.Context, names []string) ([]*entity.Tag, error)
 	// ReplaceArticleTags 重置文章标签
 	ReplaceArticleTags(ctx context.Context, articleID int64, tags []*entity.Tag) error
 	// GetTagsByArticleID 根据 articleID 查 tag
 	GetTagsByArticleID(ctx context.Context, articleID int64) ([]*entity.Tag, error)
+	// ListTags 已发布文章上用到的标签
 	ListTags(ctx context.Context) ([]string, error)
 	// return map[articleID] []tags
 	GetTagsByArticleIDs(ctx context.Context, articleIDs []int64) (map[int64][]string, error)
 
+	// ---- Slug 历史 ----
+
+	// FindByOldSlug 根据文章用过的旧 slug 查询文章
+	FindByOldSlug(ctx context.Context, slug string) (*entity.Article, error)
+	// SlugTaken slug 是否是 exceptArticleID 以外的文章当前或曾经用过的 slug
+	SlugTaken(ctx context.Context, slug string, exceptArticleID int64) (bool, error)
+
+	// ---- Revision 相关 ----
+
+	// UpdateWithRevision 更新文章并追加一条修订，两者在同一个事务里，版本检查同 Update
+	UpdateWithRevision(ctx context.Context, article *entity.Article, revision *entity.ArticleRevision) error
+	// UpdateWithTags 更新文章并把标签改成 tags（已规范化，不存在的自动创建），revision 不为空时追加一条修订；
+	// 全部在同一个事务里，版本检查同 Update
+	UpdateWithTags(ctx context.Context, article *entity.Article, revision *entity.ArticleRevision, tags []string) error
+	// ListRevisions 文章的修订列表，新的在前；不返回正文
+	ListRevisions(ctx context.Context, articleID int64, limit, offset int) ([]*entity.ArticleRevision, int64, error)
+	// FindRevision 查文章的某条修订，不存在或不属于该文章时返回 common.ErrNotFound
+	FindRevision(ctx context.Context, articleID, revisionID int64) (*entity.ArticleRevision, error)
+	// PreviousRevision revisionID 之前的一条修订，没有时返回 common.ErrNotFound
+	PreviousRevision(ctx context.Context, articleID, revisionID int64) (*entity.ArticleRevision, error)
+
 	// ---- Favorite 相关 ----
 
 	// 是否点赞
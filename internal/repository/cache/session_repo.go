@@ -0,0 +1,112 @@
+package cache
+
+import (
+	"context"
+	"github/CiroLong/realworld-gin/internal/model/entity"
+	"github/CiroLong/realworld-gin/internal/repository"
+	"sync"
+	"time"
+)
+
+// maxSessions 缓存的会话数上限，超过时先清理过期项，仍然超过则整体清空
+const maxSessions = 10000
+
+// SessionRepo 在 SessionRepo 外加一层进程内缓存
+// 每个带 JWT 的请求都要确认会话没有被撤销，这里把 FindSessionByID 的结果缓存 ttl，避免每个请求都查库；
+// 本进程内的撤销会立即清掉对应缓存，其他副本上的撤销最多 ttl 后生效
+type SessionRepo struct {
+	repository.SessionRepo
+
+	ttl time.Duration
+
+	mu       sync.Mutex
+	sessions map[int64]*cachedSession
+}
+
+type cachedSession struct {
+	session   entity.Session
+	expiresAt time.Time
+}
+
+func NewSessionRepo(repo repository.SessionRepo, ttl time.Duration) repository.SessionRepo {
+	return &SessionRepo{
+		SessionRepo: repo,
+		ttl:         ttl,
+		sessions:    make(map[int64]*cachedSession),
+	}
+}
+
+func (r *SessionRepo) FindSessionByID(ctx context.Context, id int64) (*entity.Session, error) {
+	now := time.Now()
+
+	// 1. 命中且未过期直接返回副本
+	r.mu.Lock()
+	if cached, ok := r.sessions[id]; ok && now.Before(cached.expiresAt) {
+		cp := cached.session
+		r.mu.Unlock()
+		return &cp, nil
+	}
+	r.mu.Unlock()
+
+	// 2. 回源，查不到的不缓存
+	session, err := r.SessionRepo.FindSessionByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.sessions) >= maxSessions {
+		r.evictExpired(now)
+	}
+	r.sessions[id] = &cachedSession{session: *session, expiresAt: now.Add(r.ttl)}
+	return session, nil
+}
+
+func (r *SessionRepo) RevokeSession(ctx context.Context, id int64) error {
+	err := r.SessionRepo.RevokeSession(ctx, id)
+
+	r.mu.Lock()
+	delete(r.sessions, id)
+	r.mu.Unlock()
+	return err
+}
+
+func (r *SessionRepo) RevokeUserSessions(ctx context.Context, userID int64) error {
+	err := r.SessionRepo.RevokeUserSessions(ctx, userID)
+
+	r.mu.Lock()
+	for id, cached := range r.sessions {
+		if cached.session.UserID == userID {
+			delete(r.sessions, id)
+		}
+	}
+	r.mu.Unlock()
+	return err
+}
+
+func (r *SessionRepo) TouchSession(ctx context.Context, id int64, at time.Time) error {
+	if err := r.SessionRepo.TouchSession(ctx, id, at); err != nil {
+		return err
+	}
+
+	// 同步更新缓存，避免缓存有效期内每个请求都判断为需要更新
+	r.mu.Lock()
+	if cached, ok := r.sessions[id]; ok {
+		cached.session.LastSeenAt = at
+	}
+	r.mu.Unlock()
+	return nil
+}
+
+// evictExpired 清理过期项，调用方持有锁
+func (r *SessionRepo) evictExpired(now time.Time) {
+	for id, cached := range r.sessions {
+		if !now.Before(cached.expiresAt) {
+			delete(r.sessions, id)
+		}
+	}
+	if len(r.sessions) >= maxSessions {
+		r.sessions = make(map[int64]*cachedSession)
+	}
+}
@@ -12,6 +12,9 @@ type CommentRepo interface {
 	// ListByArticle 获取文章下所有评论（按创建时间正序）
 	ListByArticle(ctx context.Context, articleID int64) ([]*entity.Comment, error)
 
+	// ListByAuthor 用户发表的评论（按创建时间正序），同时返回总数
+	ListByAuthor(ctx context.Context, authorID int64, limit int, offset int) ([]*entity.Comment, int64, error)
+
 	// FindByID 根据 comment id 查找
 	FindByID(ctx context.Context, id int64) (*entity.Comment, error)
 
@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"context"
+	"github/CiroLong/realworld-gin/internal/model/entity"
+	"time"
+)
+
+type DataExportRepo interface {
+	// Create 创建导出任务
+	Create(ctx context.Context, export *entity.DataExport) error
+
+	// FindByID 不存在时返回 common.ErrNotFound
+	FindByID(ctx context.Context, id int64) (*entity.DataExport, error)
+
+	// FindLatestByUser 用户最近一次创建、在 now 时仍未过期的导出，没有时返回 common.ErrNotFound
+	FindLatestByUser(ctx context.Context, userID int64, now time.Time) (*entity.DataExport, error)
+
+	// Finish 写入生成结果（status / data / error / completed_at）
+	Finish(ctx context.Context, export *entity.DataExport) error
+
+	// DeleteExpired 清理 before 之前过期的导出，返回删除的条数
+	DeleteExpired(ctx context.Context, before time.Time) (int64, error)
+}
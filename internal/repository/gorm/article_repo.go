@@ -3,15 +3,13 @@ package gorm
 import (
 	"context"
 	"errors"
-	"fmt"
 	"github/CiroLong/realworld-gin/internal/model/entity"
 	"github/CiroLong/realworld-gin/internal/pkg/common"
-	"github/CiroLong/realworld-gin/internal/pkg/utils"
 	"github/CiroLong/realworld-gin/internal/repository"
-	"strings"
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type articleRepo struct {
@@ -31,41 +29,243 @@ func (a articleRepo) FindBySlug(ctx context.Context, slug string) (*entity.Artic
 	return &article, err
 }
 
+func (a articleRepo) FindByIDs(ctx context.Context, ids []int64) ([]*entity.Article, error) {
+	var articles []*entity.Article
+	if len(ids) == 0 {
+		return articles, nil
+	}
+	err := a.db.WithContext(ctx).Where("id IN ?", ids).Find(&articles).Error
+	return articles, err
+}
+
 func (a articleRepo) Create(ctx context.Context, article *entity.Article) error {
 	now := time.Now()
 	article.CreatedAt = now
 	article.UpdatedAt = now
+	article.Version = 1
 
-	article.Slug = utils.GenerateSlug(article.Title)
-	const maxRetry = 3
+	return createArticle(a.db.WithContext(ctx), article)
+}
 
-	for i := 0; i < maxRetry; i++ {
-		// 尝试创建
-		err := a.db.WithContext(ctx).Create(article).Error
-		if err == nil {
-			return nil
+func (a articleRepo) CreateWithRevisionAndTags(ctx context.Context, article *entity.Article, revision *entity.ArticleRevision, tags []string) error {
+	now := time.Now()
+	article.CreatedAt = now
+	article.UpdatedAt = now
+	article.Version = 1
+
+	err := a.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 1. 文章
+		if err := createArticle(tx, article); err != nil {
+			return err
 		}
-		// 检查是否是 slug 冲突
-		if errors.Is(err, gorm.ErrDuplicatedKey) ||
-			strings.Contains(err.Error(), "Duplicate entry") && strings.Contains(err.Error(), "slug") {
-			// 生成新的 slug 并重试
-			article.Slug = utils.GenerateSlug(article.Title)
-			continue
+
+		// 2. 第一版修订
+		revision.ArticleID = article.ID
+		revision.CreatedAt = now
+		if err := tx.Create(revision).Error; err != nil {
+			return err
 		}
-		// 其他错误直接返回
-		return err
+
+		// 3. 标签
+		rows, err := getOrCreateTags(tx, tags)
+		if err != nil {
+			return err
+		}
+		return replaceArticleTags(tx, article.ID, rows)
+	})
+	if err != nil {
+		// 事务已回滚，文章没有创建成功
+		article.ID = 0
 	}
-	return fmt.Errorf("failed to create article after %d retries due to slug conflict", maxRetry)
+	return err
+}
 
+// createArticle 插入文章，slug 冲突（articles 表上只有 slug 一个唯一索引，由方言翻译为 ErrDuplicatedKey）时返回 ErrSlugTaken
+func createArticle(tx *gorm.DB, article *entity.Article) error {
+	err := tx.Create(article).Error
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return common.ErrSlugTaken
+	}
+	return err
 }
 
 func (a articleRepo) Update(ctx context.Context, article *entity.Article) error {
 	article.UpdatedAt = time.Now()
-	return a.db.WithContext(ctx).Save(article).Error
+	return a.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return updateArticle(tx, article)
+	})
+}
+
+func (a articleRepo) Delete(ctx context.Context, articleID int64, version int64) error {
+	return a.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 修订里有文章的全部历史内容，随文章一起删除；旧 slug 也不再指向任何文章
+		for _, model := range []interface{}{&entity.ArticleRevision{}, &entity.ArticleSlug{}} {
+			if err := tx.Where("article_id = ?", articleID).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+		res := tx.Where("version = ?", version).Delete(&entity.Article{}, articleID)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return common.ErrVersionMismatch
+		}
+		return nil
+	})
+}
+
+func (a articleRepo) UpdateWithRevision(ctx context.Context, article *entity.Article, revision *entity.ArticleRevision) error {
+	now := time.Now()
+	article.UpdatedAt = now
+	return a.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := updateArticle(tx, article); err != nil {
+			return err
+		}
+		revision.ArticleID = article.ID
+		revision.CreatedAt = now
+		return tx.Create(revision).Error
+	})
+}
+
+func (a articleRepo) UpdateWithTags(ctx context.Context, article *entity.Article, revision *entity.ArticleRevision, tags []string) error {
+	now := time.Now()
+	article.UpdatedAt = now
+	return a.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 1. 文章，版本不对时整个事务回滚
+		if err := updateArticle(tx, article); err != nil {
+			return err
+		}
+
+		// 2. 修订
+		if revision != nil {
+			revision.ArticleID = article.ID
+			revision.CreatedAt = now
+			if err := tx.Create(revision).Error; err != nil {
+				return err
+			}
+		}
+
+		// 3. 标签
+		rows, err := getOrCreateTags(tx, tags)
+		if err != nil {
+			return err
+		}
+		return replaceArticleTags(tx, article.ID, rows)
+	})
+}
+
+// updateArticle 把 UPDATE 的条件限定在读到的版本上并把版本加一，两个人同时编辑时后提交的那个匹配不到行
+// favorites_count 由收藏接口原子增减，这里不写回，避免用读到的旧值覆盖；需要在事务里调用
+func updateArticle(tx *gorm.DB, article *entity.Article) error {
+	// 1. 更新前的 slug
+	var oldSlugs []string
+	if err := tx.Model(&entity.Article{}).Where("id = ?", article.ID).Pluck("slug", &oldSlugs).Error; err != nil {
+		return err
+	}
+
+	// 2. 条件更新
+	version := article.Version
+	article.Version++
+	res := tx.Model(article).
+		Where("version = ?", version).
+		Select("*").
+		Omit("id", "created_at", "favorites_count").
+		Updates(article)
+	if res.Error == nil && res.RowsAffected == 0 {
+		res.Error = common.ErrVersionMismatch
+	}
+	if errors.Is(res.Error, gorm.ErrDuplicatedKey) {
+		res.Error = common.ErrSlugTaken
+	}
+	if res.Error != nil {
+		article.Version = version
+		return res.Error
+	}
+
+	// 3. slug 变了：旧 slug 记入历史；改回用过的 slug 时把它从历史里去掉
+	if len(oldSlugs) == 0 || oldSlugs[0] == article.Slug {
+		return nil
+	}
+	if err := tx.Where("article_id = ? AND slug = ?", article.ID, article.Slug).Delete(&entity.ArticleSlug{}).Error; err != nil {
+		return err
+	}
+	return tx.Create(&entity.ArticleSlug{ArticleID: article.ID, Slug: oldSlugs[0], CreatedAt: article.UpdatedAt}).Error
+}
+
+func (a articleRepo) FindByOldSlug(ctx context.Context, slug string) (*entity.Article, error) {
+	var article entity.Article
+	err := a.db.WithContext(ctx).
+		Joins("JOIN article_slugs s ON s.article_id = articles.id").
+		Where("s.slug = ?", slug).
+		First(&article).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, common.ErrNotFound
+	}
+	return &article, err
+}
+
+func (a articleRepo) SlugTaken(ctx context.Context, slug string, exceptArticleID int64) (bool, error) {
+	var count int64
+	if err := a.db.WithContext(ctx).Model(&entity.Article{}).
+		Where("slug = ? AND id <> ?", slug, exceptArticleID).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	if count > 0 {
+		return true, nil
+	}
+	err := a.db.WithContext(ctx).Model(&entity.ArticleSlug{}).
+		Where("slug = ? AND article_id <> ?", slug, exceptArticleID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (a articleRepo) ListRevisions(ctx context.Context, articleID int64, limit, offset int) ([]*entity.ArticleRevision, int64, error) {
+	db := a.db.WithContext(ctx).Model(&entity.ArticleRevision{}).Where("article_id = ?", articleID)
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var revisions []*entity.ArticleRevision
+	if err := db.
+		Omit("body").
+		Order("id DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&revisions).Error; err != nil {
+		return nil, 0, err
+	}
+	return revisions, total, nil
 }
 
-func (a articleRepo) Delete(ctx context.Context, articleID int64) error {
-	return a.db.WithContext(ctx).Delete(&entity.Article{}, articleID).Error
+func (a articleRepo) FindRevision(ctx context.Context, articleID, revisionID int64) (*entity.ArticleRevision, error) {
+	var revision entity.ArticleRevision
+	err := a.db.WithContext(ctx).Where("id = ? AND article_id = ?", revisionID, articleID).First(&revision).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, common.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &revision, nil
+}
+
+func (a articleRepo) PreviousRevision(ctx context.Context, articleID, revisionID int64) (*entity.ArticleRevision, error) {
+	var revision entity.ArticleRevision
+	err := a.db.WithContext(ctx).
+		Where("article_id = ? AND id < ?", articleID, revisionID).
+		Order("id DESC").
+		First(&revision).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, common.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &revision, nil
 }
 
 func (a articleRepo) GetTagsByArticleID(ctx context.Context, articleID int64) ([]*entity.Tag, error) {
@@ -78,47 +278,108 @@ func (a articleRepo) GetTagsByArticleID(ctx context.Context, articleID int64) ([
 	return tags, err
 }
 
-// GetOrCreateTags 获取或创建具有给定名称的标签列表，确保每个标签的唯一性。
+// GetOrCreateTags 获取或创建具有给定名称的标签列表，按 names 的顺序返回
 func (a articleRepo) GetOrCreateTags(ctx context.Context, names []string) ([]*entity.Tag, error) {
+	return getOrCreateTags(a.db.WithContext(ctx), names)
+}
+
+// getOrCreateTags 一次查出已有的标签，缺的批量插入；并发请求抢先创建了同名标签时唯一索引冲突被忽略，再查一次拿到它
+// 可以在事务里调用：再查时用加锁读，MySQL 可重复读隔离级别下也能看到别的事务刚提交的标签
+func getOrCreateTags(db *gorm.DB, names []string) ([]*entity.Tag, error) {
 	if len(names) == 0 {
 		return []*entity.Tag{}, nil
 	}
 
-	tags := make([]*entity.Tag, 0, len(names))
+	// 1. 查已有的标签
+	byName := make(map[string]*entity.Tag, len(names))
+	if err := findTags(db, names, byName); err != nil {
+		return nil, err
+	}
+
+	// 2. 批量插入缺少的标签，冲突的行跳过
+	var missing []*entity.Tag
 	for _, name := range names {
-		var tag entity.Tag
-		// 使用 FirstOrCreate 保证唯一性
-		err := a.db.WithContext(ctx).Where("name = ?", name).FirstOrCreate(&tag, entity.Tag{Name: name}).Error
-		if err != nil {
+		if byName[name] == nil {
+			missing = append(missing, &entity.Tag{Name: name})
+		}
+	}
+	locked := db.Clauses(clause.Locking{Strength: clause.LockingStrengthShare})
+	if len(missing) > 0 {
+		if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&missing).Error; err != nil {
 			return nil, err
 		}
-		tags = append(tags, &tag)
+		// 不依赖回填的 id：被跳过的行没有 id，有的驱动批量插入也回填不了
+		missingNames := make([]string, len(missing))
+		for i, tag := range missing {
+			missingNames[i] = tag.Name
+		}
+		if err := findTags(locked, missingNames, byName); err != nil {
+			return nil, err
+		}
+	}
+
+	// 3. 按顺序组装；排序规则不区分大小写的数据库（MySQL 默认）里，已有的标签可能和 name 不完全相同
+	tags := make([]*entity.Tag, 0, len(names))
+	for _, name := range names {
+		tag := byName[name]
+		if tag == nil {
+			tag = &entity.Tag{}
+			if err := locked.Where("name = ?", name).First(tag).Error; err != nil {
+				return nil, err
+			}
+		}
+		tags = append(tags, tag)
 	}
 
 	return tags, nil
 }
 
+// findTags 按名称查标签，结果放进 byName
+func findTags(db *gorm.DB, names []string, byName map[string]*entity.Tag) error {
+	var tags []*entity.Tag
+	if err := db.Where("name IN ?", names).Find(&tags).Error; err != nil {
+		return err
+	}
+	for _, tag := range tags {
+		byName[tag.Name] = tag
+	}
+	return nil
+}
+
 // ReplaceArticleTags 替换文章的标签，使用事务确保删除旧标签和添加新标签操作的原子性。
 func (a articleRepo) ReplaceArticleTags(ctx context.Context, articleID int64, tags []*entity.Tag) error {
 	// 使用gorm的事务操作保证原子性
 	return a.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		// 1️. 删除旧的关联
-		if err := tx.Where("article_id = ?", articleID).Delete(&entity.ArticleTag{}).Error; err != nil {
-			return err
-		}
-
-		// 2. 插入新的关联
-		if len(tags) == 0 {
-			return nil
-		}
-		articleTags := make([]*entity.ArticleTag, len(tags))
-		for i, tag := range tags {
-			articleTags[i] = &entity.ArticleTag{ArticleID: articleID, TagID: tag.ID}
-		}
-		return tx.Create(&articleTags).Error
+		return replaceArticleTags(tx, articleID, tags)
 	})
 }
 
+// replaceArticleTags 把文章的标签改成 tags：只删除不再需要的关联、插入新增的关联，已有的保持不动；需要在事务里调用
+func replaceArticleTags(tx *gorm.DB, articleID int64, tags []*entity.Tag) error {
+	// 1. 删除移除的关联
+	tagIDs := make([]int64, len(tags))
+	for i, tag := range tags {
+		tagIDs[i] = tag.ID
+	}
+	remove := tx.Where("article_id = ?", articleID)
+	if len(tagIDs) > 0 {
+		remove = remove.Where("tag_id NOT IN ?", tagIDs)
+	}
+	if err := remove.Delete(&entity.ArticleTag{}).Error; err != nil {
+		return err
+	}
+
+	// 2. 插入新增的关联，已经存在的跳过
+	if len(tags) == 0 {
+		return nil
+	}
+	articleTags := make([]*entity.ArticleTag, len(tags))
+	for i, tag := range tags {
+		articleTags[i] = &entity.ArticleTag{ArticleID: articleID, TagID: tag.ID}
+	}
+	return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&articleTags).Error
+}
+
 func (a articleRepo) IsFavorited(ctx context.Context, userID, articleID int64) (bool, error) {
 	var count int64
 	err := a.db.WithContext(ctx).
@@ -170,46 +431,62 @@ func (a articleRepo) CountFavorites(ctx context.Context, articleID int64) (int,
 }
 
 func (a articleRepo) List(ctx context.Context, query repository.ListArticlesFilter) ([]*entity.Article, int64, error) {
-	db := a.db.WithContext(ctx).Model(&entity.Article{})
+	// sub 用来构造子查询，和主查询使用同一个 ctx，请求取消时一起中止
+	sub := a.db.WithContext(ctx)
+	db := sub.Model(&entity.Article{})
+
+	// 过滤条件都写成子查询，不 JOIN，每篇文章只出现一次，不需要 DISTINCT
+	// （Postgres 不允许 SELECT DISTINCT 按不在选择列表里的表达式排序）
 
 	// --- tag 过滤 ---
 	if query.Tag != nil {
-		db = db.Joins(
-			"JOIN article_tags at ON at.article_id = articles.id").
-			Joins(
-				"JOIN tags t ON t.id = at.tag_id").
-			Where("t.name = ?", *query.Tag)
+		db = db.Where("articles.id IN (?)", sub.
+			Table("article_tags at").
+			Select("at.article_id").
+			Joins("JOIN tags t ON t.id = at.tag_id").
+			Where("t.name = ?", *query.Tag))
 	}
 
 	// --- author 过滤 ---
 	if query.Author != nil {
-		db = db.Joins(
-			"JOIN users u ON u.id = articles.author_id").
-			Where("u.username = ?", *query.Author)
+		db = db.Where("articles.author_id IN (?)", sub.
+			Model(&entity.User{}).
+			Select("id").
+			Where(equalFold(a.db, "username"), *query.Author))
 	}
 
 	// --- favorited 过滤 ---
 	if query.FavoritedBy != nil {
-		db = db.Joins(
-			"JOIN favorites f ON f.article_id = articles.id").
-			Joins(
-				"JOIN users u2 ON u2.id = f.user_id").
-			Where("u2.username = ?", *query.FavoritedBy)
+		db = db.Where("articles.id IN (?)", sub.
+			Table("favorites f").
+			Select("f.article_id").
+			Joins("JOIN users u ON u.id = f.user_id").
+			Where(equalFold(a.db, "u.username"), *query.FavoritedBy))
+	}
+
+	// --- 停用账号 ---
+	if query.ExcludeSuspendedAuthors {
+		db = db.Where("articles.author_id NOT IN (?)", suspendedUserIDs(sub))
+	}
+
+	// --- 状态 ---
+	if len(query.Statuses) > 0 {
+		db = db.Where("articles.status IN ?", query.Statuses)
+	} else {
+		db = db.Where("articles.status = ?", entity.ArticleStatusPublished)
 	}
 
 	// --- 统计总数 ---
+	// 在新的 Session 上统计，不影响后面的分页查询
 	var total int64
-	if err := db.
-		Distinct("articles.id").
-		Count(&total).Error; err != nil {
+	if err := db.Session(&gorm.Session{}).Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
 	// --- 查询文章 ---
 	var articles []*entity.Article
 	if err := db.
-		Select("articles.*").
-		Order("articles.created_at DESC").
+		Order(publishedOrder).
 		Limit(query.Limit).
 		Offset(query.Offset).
 		Find(&articles).Error; err != nil {
@@ -219,12 +496,16 @@ func (a articleRepo) List(ctx context.Context, query repository.ListArticlesFilt
 	return articles, total, nil
 }
 
-func (a articleRepo) Feed(ctx context.Context, userID int64, limit, offset int) ([]*entity.Article, int64, error) {
-	baseQuery := a.db.WithContext(ctx).
+func (a articleRepo) Feed(ctx context.Context, userID int64, query repository.FeedFilter) ([]*entity.Article, int64, error) {
+	sub := a.db.WithContext(ctx)
+	baseQuery := sub.
 		Model(&entity.Article{}).
 		Joins(
 			"JOIN follows f ON f.following_id = articles.author_id").
-		Where("f.follower_id = ?", userID)
+		Where("f.follower_id = ? AND articles.status = ?", userID, entity.ArticleStatusPublished)
+	if query.ExcludeSuspendedAuthors {
+		baseQuery = baseQuery.Where("articles.author_id NOT IN (?)", suspendedUserIDs(sub))
+	}
 
 	// 总数 - 使用临时变量，避免影响后续查询
 	var total int64
@@ -236,24 +517,101 @@ func (a articleRepo) Feed(ctx context.Context, userID int64, limit, offset int)
 	// 查询文章列表
 	var articles []*entity.Article
 	if err := baseQuery.
-		Order("articles.created_at DESC").
+		Order(publishedOrder).
+		Limit(query.Limit).
+		Offset(query.Offset).
+		Find(&articles).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return articles, total, nil
+}
+
+func (a articleRepo) ListScheduled(ctx context.Context, authorID int64, limit, offset int) ([]*entity.Article, int64, error) {
+	db := a.db.WithContext(ctx).
+		Model(&entity.Article{}).
+		Where("author_id = ? AND status = ?", authorID, entity.ArticleStatusScheduled)
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var articles []*entity.Article
+	if err := db.
+		Order("publish_at ASC, id ASC").
 		Limit(limit).
 		Offset(offset).
 		Find(&articles).Error; err != nil {
 		return nil, 0, err
 	}
-
 	return articles, total, nil
 }
 
+func (a articleRepo) PublishDue(ctx context.Context, now time.Time, limit int) ([]*entity.Article, error) {
+	// 1. 找出到期的文章
+	var due []*entity.Article
+	if err := a.db.WithContext(ctx).
+		Where("status = ? AND publish_at <= ?", entity.ArticleStatusScheduled, now).
+		Order("publish_at ASC, id ASC").
+		Limit(limit).
+		Find(&due).Error; err != nil {
+		return nil, err
+	}
+
+	// 2. 逐篇认领：条件更新只有一个副本能成功，作者在这期间改了发布时间或状态也不会误发
+	// 发布时间取计划时间而不是实际执行时间，之前发布过的文章保留第一次的发布时间
+	published := make([]*entity.Article, 0, len(due))
+	for _, article := range due {
+		res := a.db.WithContext(ctx).
+			Model(&entity.Article{}).
+			Where("id = ? AND status = ? AND publish_at <= ?", article.ID, entity.ArticleStatusScheduled, now).
+			Updates(map[string]any{
+				"status":       entity.ArticleStatusPublished,
+				"published_at": gorm.Expr("COALESCE(published_at, publish_at)"),
+				"publish_at":   nil,
+				"updated_at":   now,
+				"version":      gorm.Expr("version + 1"),
+			})
+		if res.Error != nil {
+			return published, res.Error
+		}
+		if res.RowsAffected == 0 {
+			continue
+		}
+
+		if article.PublishedAt == nil {
+			article.PublishedAt = article.PublishAt
+		}
+		article.Status = entity.ArticleStatusPublished
+		article.PublishAt = nil
+		article.UpdatedAt = now
+		article.Version++
+		published = append(published, article)
+	}
+	return published, nil
+}
+
+// publishedOrder 按发布时间倒序，草稿没有发布时间，按创建时间排
+const publishedOrder = "COALESCE(articles.published_at, articles.created_at) DESC, articles.id DESC"
+
+// suspendedUserIDs 被停用账号的 id 子查询，db 需要带上调用方的 ctx
+func suspendedUserIDs(db *gorm.DB) *gorm.DB {
+	return db.Model(&entity.User{}).Select("id").Where("suspended_at IS NOT NULL")
+}
+
 func (a articleRepo) ListTags(ctx context.Context) ([]string, error) {
 	var tags []string
 
+	// 草稿上的标签不能泄露出去
 	if err := a.db.WithContext(ctx).
 		Model(&entity.Tag{}).
-		Distinct("name").
-		Order("name ASC").
-		Pluck("name", &tags).Error; err != nil {
+		Joins("JOIN article_tags at ON at.tag_id = tags.id").
+		Joins("JOIN articles ON articles.id = at.article_id").
+		Where("articles.status = ?", entity.ArticleStatusPublished).
+		Distinct("tags.name").
+		Order("tags.name ASC").
+		Pluck("tags.name", &tags).Error; err != nil {
 		return nil, err
 	}
 
@@ -0,0 +1,432 @@
+package gorm
+
+import (
+	"context"
+	"errors"
+	"github/CiroLong/realworld-gin/internal/model/entity"
+	"github/CiroLong/realworld-gin/internal/pkg/common"
+	"github/CiroLong/realworld-gin/internal/repository"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// 仓储测试在每种方言上各跑一遍：sqlite 总是运行；mysql / postgres 需要用环境变量给出专用测试库的 DSN，未设置时跳过
+//
+//	TEST_MYSQL_DSN="realworld:realworld@tcp(127.0.0.1:3306)/realworld_test?charset=utf8mb4&parseTime=True&loc=Local"
+//	TEST_POSTGRES_DSN="host=127.0.0.1 user=realworld password=realworld dbname=realworld_test port=5432 sslmode=disable TimeZone=UTC"
+//
+// 测试前后会回滚全部迁移，库里的表和数据都会被删除
+var testDialects = []struct {
+	driver string
+	env    string
+	open   func(dsn string) gorm.Dialector
+}{
+	{DriverSQLite, "", sqlite.Open},
+	{DriverMySQL, "TEST_MYSQL_DSN", mysql.Open},
+	{DriverPostgres, "TEST_POSTGRES_DSN", postgres.Open},
+}
+
+// forEachDialect 对每种可用的方言打开一个迁移到最新版本的空库，执行 fn
+func forEachDialect(t *testing.T, fn func(t *testing.T, db *gorm.DB)) {
+	for _, d := range testDialects {
+		t.Run(d.driver, func(t *testing.T) {
+			dsn := filepath.Join(t.TempDir(), "test.db") + "?_pragma=busy_timeout(5000)"
+			if d.env != "" {
+				if dsn = os.Getenv(d.env); dsn == "" {
+					t.Skipf("%s not set", d.env)
+				}
+			}
+			db, err := gorm.Open(d.open(dsn), &gorm.Config{
+				Logger:         logger.Default.LogMode(logger.Silent),
+				TranslateError: true,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			sqlDB, err := db.DB()
+			if err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(func() { sqlDB.Close() })
+
+			migrator, err := NewMigrator(db)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ctx := context.Background()
+			reset := func() {
+				if _, err := migrator.Down(ctx, len(migrator.migrations)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			reset()
+			t.Cleanup(reset)
+			if _, err := migrator.Up(ctx); err != nil {
+				t.Fatal(err)
+			}
+
+			fn(t, db)
+		})
+	}
+}
+
+func TestArticleRepoList(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, db *gorm.DB) {
+		ctx := context.Background()
+		users := NewUserRepo(db)
+		articles := NewArticleRepo(db)
+
+		// 1. jake 写了两篇已发布的文章和一篇草稿，anna 写了一篇；anna 收藏了 jake 的两篇
+		jake := &entity.User{Username: "jake", Email: "jake@example.com", Password: "x"}
+		anna := &entity.User{Username: "anna", Email: "anna@example.com", Password: "x"}
+		for _, u := range []*entity.User{jake, anna} {
+			if err := users.Create(ctx, u); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+		newArticle := func(slug string, author *entity.User, status string, published int, tags ...string) *entity.Article {
+			t.Helper()
+			a := &entity.Article{Slug: slug, Title: slug, Description: "d", Body: "b", AuthorID: author.ID, Status: status}
+			if status == entity.ArticleStatusPublished {
+				at := base.Add(time.Duration(published) * time.Hour)
+				a.PublishedAt = &at
+			}
+			if err := articles.Create(ctx, a); err != nil {
+				t.Fatal(err)
+			}
+			tagRows, err := articles.GetOrCreateTags(ctx, tags)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := articles.ReplaceArticleTags(ctx, a.ID, tagRows); err != nil {
+				t.Fatal(err)
+			}
+			return a
+		}
+		first := newArticle("first", jake, entity.ArticleStatusPublished, 1, "go", "web")
+		second := newArticle("second", jake, entity.ArticleStatusPublished, 2, "go")
+		newArticle("draft", jake, entity.ArticleStatusDraft, 0, "go", "web")
+		annas := newArticle("annas", anna, entity.ArticleStatusPublished, 3, "web")
+
+		for _, a := range []*entity.Article{first, second} {
+			if err := articles.AddFavorite(ctx, anna.ID, a.ID); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := articles.AddFavorite(ctx, jake.ID, annas.ID); err != nil {
+			t.Fatal(err)
+		}
+
+		// 2. 各种过滤条件的总数和分页；按发布时间倒序，草稿按创建时间（即现在）排在最前
+		str := func(s string) *string { return &s }
+		tests := []struct {
+			name   string
+			filter repository.ListArticlesFilter
+			total  int64
+			slugs  []string
+		}{
+			{"all", repository.ListArticlesFilter{Limit: 10}, 3, []string{"annas", "second", "first"}},
+			{"tag", repository.ListArticlesFilter{Tag: str("go"), Limit: 10}, 2, []string{"second", "first"}},
+			{"tag page", repository.ListArticlesFilter{Tag: str("web"), Limit: 1, Offset: 1}, 2, []string{"first"}},
+			{"tag with drafts", repository.ListArticlesFilter{Tag: str("web"), Statuses: entity.ArticleStatuses, Limit: 10}, 3, []string{"draft", "annas", "first"}},
+			{"author", repository.ListArticlesFilter{Author: str("JAKE"), Limit: 10}, 2, []string{"second", "first"}},
+			{"favorited", repository.ListArticlesFilter{FavoritedBy: str("Anna"), Limit: 10}, 2, []string{"second", "first"}},
+			{"favorited page", repository.ListArticlesFilter{FavoritedBy: str("anna"), Limit: 1}, 2, []string{"second"}},
+			{"tag and favorited", repository.ListArticlesFilter{Tag: str("web"), FavoritedBy: str("anna"), Limit: 10}, 1, []string{"first"}},
+			{"tag and author", repository.ListArticlesFilter{Tag: str("web"), Author: str("anna"), Limit: 10}, 1, []string{"annas"}},
+			{"no match", repository.ListArticlesFilter{Tag: str("rust"), Limit: 10}, 0, nil},
+		}
+		for _, tc := range tests {
+			got, total, err := articles.List(ctx, tc.filter)
+			if err != nil {
+				t.Fatalf("%s: %v", tc.name, err)
+			}
+			if total != tc.total {
+				t.Errorf("%s: total = %d, want %d", tc.name, total, tc.total)
+			}
+			var slugs []string
+			for _, a := range got {
+				slugs = append(slugs, a.Slug)
+			}
+			if !slices.Equal(slugs, tc.slugs) {
+				t.Errorf("%s: slugs = %v, want %v", tc.name, slugs, tc.slugs)
+			}
+		}
+	})
+}
+
+func TestArticleRepoCreateWithRevisionAndTags(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, db *gorm.DB) {
+		ctx := context.Background()
+		users := NewUserRepo(db)
+		articles := NewArticleRepo(db)
+
+		jake := &entity.User{Username: "jake", Email: "jake@example.com", Password: "x"}
+		if err := users.Create(ctx, jake); err != nil {
+			t.Fatal(err)
+		}
+		newArticle := func(slug string) (*entity.Article, *entity.ArticleRevision) {
+			a := &entity.Article{Slug: slug, Title: slug, Description: "d", Body: "b", AuthorID: jake.ID, Status: entity.ArticleStatusPublished}
+			return a, &entity.ArticleRevision{EditorID: jake.ID, Title: a.Title, Description: a.Description, Body: a.Body}
+		}
+
+		// 1. 文章、第一版修订和标签一起写入
+		article, revision := newArticle("post")
+		if err := articles.CreateWithRevisionAndTags(ctx, article, revision, []string{"go", "web"}); err != nil {
+			t.Fatal(err)
+		}
+		if revision.ArticleID != article.ID || !revision.CreatedAt.Equal(article.CreatedAt) {
+			t.Errorf("revision = %+v, article id %d", revision, article.ID)
+		}
+		tags, err := articles.GetTagsByArticleIDs(ctx, []int64{article.ID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := tags[article.ID]
+		slices.Sort(got)
+		if !slices.Equal(got, []string{"go", "web"}) {
+			t.Errorf("tags = %v", got)
+		}
+
+		// 2. slug 冲突
+		dup, dupRevision := newArticle("post")
+		if err := articles.CreateWithRevisionAndTags(ctx, dup, dupRevision, nil); !errors.Is(err, common.ErrSlugTaken) {
+			t.Errorf("duplicate slug: %v", err)
+		}
+
+		// 3. 最后一步写入失败时文章、修订和新建的标签都不留下
+		fail := errors.New("article_tags insert failed")
+		if err := db.Callback().Create().Before("gorm:create").Register("test:fail_article_tags", func(tx *gorm.DB) {
+			if tx.Statement.Table == "article_tags" {
+				tx.AddError(fail)
+			}
+		}); err != nil {
+			t.Fatal(err)
+		}
+		broken, brokenRevision := newArticle("broken")
+		if err := articles.CreateWithRevisionAndTags(ctx, broken, brokenRevision, []string{"rust"}); !errors.Is(err, fail) {
+			t.Fatalf("create with failing article_tags: %v", err)
+		}
+		if broken.ID != 0 {
+			t.Errorf("article id = %d after rollback", broken.ID)
+		}
+		if _, err := articles.FindBySlug(ctx, "broken"); !errors.Is(err, common.ErrNotFound) {
+			t.Errorf("find rolled back article: %v", err)
+		}
+		var revisions, rust int64
+		if err := db.Model(&entity.ArticleRevision{}).Count(&revisions).Error; err != nil {
+			t.Fatal(err)
+		}
+		if err := db.Model(&entity.Tag{}).Where("name = ?", "rust").Count(&rust).Error; err != nil {
+			t.Fatal(err)
+		}
+		if revisions != 1 || rust != 0 {
+			t.Errorf("after rollback: %d revisions, %d rust tags", revisions, rust)
+		}
+	})
+}
+
+func TestArticleRepoUpdateWithTags(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, db *gorm.DB) {
+		ctx := context.Background()
+		users := NewUserRepo(db)
+		articles := NewArticleRepo(db)
+
+		jake := &entity.User{Username: "jake", Email: "jake@example.com", Password: "x"}
+		if err := users.Create(ctx, jake); err != nil {
+			t.Fatal(err)
+		}
+		article := &entity.Article{Slug: "post", Title: "Post", Description: "d", Body: "b", AuthorID: jake.ID, Status: entity.ArticleStatusPublished}
+		if err := articles.Create(ctx, article); err != nil {
+			t.Fatal(err)
+		}
+		tagNames := func() []string {
+			t.Helper()
+			tags, err := articles.GetTagsByArticleID(ctx, article.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var names []string
+			for _, tag := range tags {
+				names = append(names, tag.Name)
+			}
+			slices.Sort(names)
+			return names
+		}
+
+		// 1. 标签和文章一起更新
+		article.Title = "Post 2"
+		if err := articles.UpdateWithTags(ctx, article, nil, []string{"go", "web"}); err != nil {
+			t.Fatal(err)
+		}
+		if got := tagNames(); !slices.Equal(got, []string{"go", "web"}) {
+			t.Fatalf("tags = %v", got)
+		}
+
+		// 2. 版本不对时文章和标签都不变
+		stale := *article
+		stale.Version--
+		stale.Title = "Stale"
+		if err := articles.UpdateWithTags(ctx, &stale, nil, []string{"rust"}); !errors.Is(err, common.ErrVersionMismatch) {
+			t.Fatalf("stale update: %v", err)
+		}
+		if got := tagNames(); !slices.Equal(got, []string{"go", "web"}) {
+			t.Errorf("tags after stale update = %v", got)
+		}
+		current, err := articles.FindBySlug(ctx, "post")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if current.Title != "Post 2" {
+			t.Errorf("title after stale update = %q", current.Title)
+		}
+
+		// 3. 只增删有变化的关联，空列表清空
+		if err := articles.UpdateWithTags(ctx, article, nil, []string{"web", "rust"}); err != nil {
+			t.Fatal(err)
+		}
+		if got := tagNames(); !slices.Equal(got, []string{"rust", "web"}) {
+			t.Errorf("tags = %v", got)
+		}
+		if err := articles.UpdateWithTags(ctx, article, nil, nil); err != nil {
+			t.Fatal(err)
+		}
+		if got := tagNames(); len(got) != 0 {
+			t.Errorf("tags after clear = %v", got)
+		}
+	})
+}
+
+func TestArticleRepoGetOrCreateTagsConcurrently(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, db *gorm.DB) {
+		ctx := context.Background()
+		articles := NewArticleRepo(db)
+		if _, err := articles.GetOrCreateTags(ctx, []string{"b"}); err != nil {
+			t.Fatal(err)
+		}
+
+		// 多个请求同时创建同一批标签，都成功，并且拿到同样的 id，顺序和传入的一致
+		names := []string{"c", "a", "b", "d"}
+		results := make([][]*entity.Tag, 8)
+		errs := make([]error, len(results))
+		var wg sync.WaitGroup
+		for i := range results {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				results[i], errs[i] = articles.GetOrCreateTags(ctx, names)
+			}(i)
+		}
+		wg.Wait()
+
+		for i, tags := range results {
+			if errs[i] != nil {
+				t.Fatalf("call %d: %v", i, errs[i])
+			}
+			for j, tag := range tags {
+				if tag.Name != names[j] || tag.ID == 0 || tag.ID != results[0][j].ID {
+					t.Errorf("call %d: tag %d = %+v, want %q with id %d", i, j, tag, names[j], results[0][j].ID)
+				}
+			}
+		}
+		var count int64
+		if err := db.Model(&entity.Tag{}).Count(&count).Error; err != nil {
+			t.Fatal(err)
+		}
+		if count != 4 {
+			t.Errorf("tags in table = %d, want 4", count)
+		}
+	})
+}
+
+func TestNormalizeTagsMigration(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, db *gorm.DB) {
+		ctx := context.Background()
+		migrator, err := NewMigrator(db)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// 回滚到 0018 之前
+		steps := 0
+		for _, mig := range migrator.migrations {
+			if mig.Version >= 18 {
+				steps++
+			}
+		}
+		if _, err := migrator.Down(ctx, steps); err != nil {
+			t.Fatal(err)
+		}
+
+		// 1. 迁移前按原样保存的标签：两种写法的 "web dev"，一个大写带空格的 rust，一个全是空白的
+		jake := &entity.User{Username: "jake", Email: "jake@example.com", Password: "x"}
+		if err := db.Create(jake).Error; err != nil {
+			t.Fatal(err)
+		}
+		var posts []*entity.Article
+		for _, slug := range []string{"both", "old", "other"} {
+			a := &entity.Article{Slug: slug, Title: slug, Description: "d", Body: "b", AuthorID: jake.ID, Status: entity.ArticleStatusPublished}
+			if err := db.Create(a).Error; err != nil {
+				t.Fatal(err)
+			}
+			posts = append(posts, a)
+		}
+		tags := []*entity.Tag{{Name: "web dev"}, {Name: "Web  Dev"}, {Name: "RUST "}, {Name: "   "}}
+		if err := db.Create(&tags).Error; err != nil {
+			t.Fatal(err)
+		}
+		links := []entity.ArticleTag{
+			{ArticleID: posts[0].ID, TagID: tags[0].ID},
+			{ArticleID: posts[0].ID, TagID: tags[1].ID},
+			{ArticleID: posts[1].ID, TagID: tags[1].ID},
+			{ArticleID: posts[2].ID, TagID: tags[2].ID},
+			{ArticleID: posts[2].ID, TagID: tags[3].ID},
+		}
+		if err := db.Create(&links).Error; err != nil {
+			t.Fatal(err)
+		}
+
+		// 2. 迁移后同名的合并到规范写法上，空白标签被删除
+		if _, err := migrator.Up(ctx); err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		if err := db.Model(&entity.Tag{}).Order("name").Pluck("name", &names).Error; err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(names, []string{"rust", "web dev"}) {
+			t.Errorf("tags = %v", names)
+		}
+
+		articles := NewArticleRepo(db)
+		want := map[string][]string{"both": {"web dev"}, "old": {"web dev"}, "other": {"rust"}}
+		for _, a := range posts {
+			tags, err := articles.GetTagsByArticleID(ctx, a.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, tag := range tags {
+				got = append(got, tag.Name)
+			}
+			if !slices.Equal(got, want[a.Slug]) {
+				t.Errorf("%s: tags = %v, want %v", a.Slug, got, want[a.Slug])
+			}
+		}
+		filter := repository.ListArticlesFilter{Tag: &names[1], Limit: 10}
+		if _, total, err := articles.List(ctx, filter); err != nil || total != 2 {
+			t.Errorf("list by %q: total = %d, err = %v", names[1], total, err)
+		}
+	})
+}
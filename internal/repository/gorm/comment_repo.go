@@ -36,6 +36,26 @@ func (c CommentRepo) ListByArticle(ctx context.Context, articleID int64) ([]*ent
 	return comments, nil
 }
 
+func (c CommentRepo) ListByAuthor(ctx context.Context, authorID int64, limit int, offset int) ([]*entity.Comment, int64, error) {
+	db := c.db.WithContext(ctx).Model(&entity.Comment{}).Where("author_id = ?", authorID)
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var comments []*entity.Comment
+	if err := db.
+		Order("created_at ASC, id ASC").
+		Limit(limit).
+		Offset(offset).
+		Find(&comments).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return comments, total, nil
+}
+
 func (c CommentRepo) FindByID(ctx context.Context, id int64) (*entity.Comment, error) {
 	var comment entity.Comment
 
@@ -0,0 +1,72 @@
+package gorm
+
+import (
+	"context"
+	"errors"
+	"github/CiroLong/realworld-gin/internal/model/entity"
+	"github/CiroLong/realworld-gin/internal/pkg/common"
+	"github/CiroLong/realworld-gin/internal/repository"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type DataExportRepo struct {
+	db *gorm.DB
+}
+
+func NewDataExportRepo(db *gorm.DB) repository.DataExportRepo {
+	return &DataExportRepo{db: db}
+}
+
+func (r *DataExportRepo) Create(ctx context.Context, export *entity.DataExport) error {
+	return r.db.WithContext(ctx).Create(export).Error
+}
+
+func (r *DataExportRepo) FindByID(ctx context.Context, id int64) (*entity.DataExport, error) {
+	var export entity.DataExport
+	err := r.db.WithContext(ctx).First(&export, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, common.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &export, nil
+}
+
+func (r *DataExportRepo) FindLatestByUser(ctx context.Context, userID int64, now time.Time) (*entity.DataExport, error) {
+	var export entity.DataExport
+	// 只看状态，不读 ZIP 内容
+	err := r.db.WithContext(ctx).
+		Omit("data").
+		Where("user_id = ? AND expires_at > ?", userID, now).
+		Order("id DESC").
+		First(&export).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, common.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &export, nil
+}
+
+func (r *DataExportRepo) Finish(ctx context.Context, export *entity.DataExport) error {
+	return r.db.WithContext(ctx).
+		Model(&entity.DataExport{}).
+		Where("id = ?", export.ID).
+		Updates(map[string]interface{}{
+			"status":       export.Status,
+			"data":         export.Data,
+			"error":        export.Error,
+			"completed_at": export.CompletedAt,
+		}).Error
+}
+
+func (r *DataExportRepo) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	res := r.db.WithContext(ctx).
+		Where("expires_at <= ?", before).
+		Delete(&entity.DataExport{})
+	return res.RowsAffected, res.Error
+}
@@ -0,0 +1,29 @@
+package gorm
+
+import "testing"
+
+func TestRedactDSN(t *testing.T) {
+	tests := []struct {
+		driver string
+		dsn    string
+		want   string
+	}{
+		{DriverMySQL, "realworld:s3cret@tcp(127.0.0.1:3306)/realworld?charset=utf8mb4&parseTime=True", "realworld:***@tcp(127.0.0.1:3306)/realworld?charset=utf8mb4&parseTime=True"},
+		{"", "root:p@ss:w0rd@tcp(db:3306)/realworld", "root:***@tcp(db:3306)/realworld"},
+		{DriverMySQL, "root@tcp(db:3306)/realworld", "root@tcp(db:3306)/realworld"},
+		{DriverMySQL, "/realworld", "/realworld"},
+		{DriverPostgres, "host=db user=realworld password=s3cret dbname=realworld port=5432 sslmode=disable", "host=db user=realworld password=*** dbname=realworld port=5432 sslmode=disable"},
+		{DriverPostgres, "host=db password = 'a b\\'c' dbname=realworld", "host=db password = *** dbname=realworld"},
+		{DriverPostgres, "host=db user=realworld dbname=realworld", "host=db user=realworld dbname=realworld"},
+		{DriverPostgres, "postgres://realworld:s3cret@db:5432/realworld?sslmode=disable", "postgres://realworld:xxxxx@db:5432/realworld?sslmode=disable"},
+		{DriverPostgres, "postgresql://realworld@db/realworld", "postgresql://realworld@db/realworld"},
+		{DriverSQLite, "file:realworld.db?_pragma=busy_timeout(5000)", "file:realworld.db?_pragma=busy_timeout(5000)"},
+		{"memory", "", ""},
+		{"unknown", "user:secret@somewhere", "***"},
+	}
+	for _, tc := range tests {
+		if got := RedactDSN(tc.driver, tc.dsn); got != tc.want {
+			t.Errorf("RedactDSN(%q, %q) = %q, want %q", tc.driver, tc.dsn, got, tc.want)
+		}
+	}
+}
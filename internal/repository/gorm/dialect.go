@@ -0,0 +1,19 @@
+package gorm
+
+import "gorm.io/gorm"
+
+// 这里放各数据库方言之间的差异，repo 里的查询尽量只写标准 SQL，确实不一样的地方收敛到这里
+
+// equalFold 返回一个大小写不敏感的等值条件，如 "LOWER(email) = LOWER(?)"
+// mysql 默认的 utf8mb4_unicode_ci 本身就不区分大小写，直接比较还能走唯一索引；
+// postgres / sqlite 默认区分大小写，需要显式 LOWER，和 users 表上按 LOWER 建的唯一索引一致
+//
+// 注意 sqlite 内置的 LOWER 只转换 ASCII 字母：É 和 é 被当作不同的字符。唯一索引用的是同一个 LOWER，
+// 所以查询和唯一约束的结果始终一致（不会有两行同时匹配），只是非 ASCII 字母的大小写变体算作不同的邮箱 / 用户名；
+// postgres 的 LOWER 按数据库的 LC_CTYPE 转换，C locale 下同样只转换 ASCII
+func equalFold(db *gorm.DB, column string) string {
+	if db.Dialector.Name() == DriverMySQL {
+		return column + " = ?"
+	}
+	return "LOWER(" + column + ") = LOWER(?)"
+}
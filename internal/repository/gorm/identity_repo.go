@@ -0,0 +1,94 @@
+package gorm
+
+import (
+	"context"
+	"errors"
+	"github/CiroLong/realworld-gin/internal/model/entity"
+	"github/CiroLong/realworld-gin/internal/pkg/common"
+	"github/CiroLong/realworld-gin/internal/repository"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type IdentityRepo struct {
+	db *gorm.DB
+}
+
+func NewIdentityRepo(db *gorm.DB) repository.IdentityRepo {
+	return &IdentityRepo{db: db}
+}
+
+func (r *IdentityRepo) Create(ctx context.Context, identity *entity.UserIdentity) error {
+	err := r.db.WithContext(ctx).Create(identity).Error
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return common.ErrIdentityAlreadyLinked
+	}
+	return err
+}
+
+func (r *IdentityRepo) FindByProviderSubject(ctx context.Context, provider, subject string) (*entity.UserIdentity, error) {
+	var identity entity.UserIdentity
+	err := r.db.WithContext(ctx).
+		Where("provider = ? AND subject = ?", provider, subject).
+		First(&identity).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, common.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &identity, nil
+}
+
+func (r *IdentityRepo) ListByUser(ctx context.Context, userID int64) ([]*entity.UserIdentity, error) {
+	var identities []*entity.UserIdentity
+	err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("provider").
+		Find(&identities).Error
+	return identities, err
+}
+
+func (r *IdentityRepo) Delete(ctx context.Context, userID int64, provider string) error {
+	res := r.db.WithContext(ctx).
+		Where("user_id = ? AND provider = ?", userID, provider).
+		Delete(&entity.UserIdentity{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return common.ErrNotFound
+	}
+	return nil
+}
+
+func (r *IdentityRepo) CreateState(ctx context.Context, state *entity.OIDCState) error {
+	db := r.db.WithContext(ctx)
+	if err := db.Where("expires_at < ?", time.Now()).Delete(&entity.OIDCState{}).Error; err != nil {
+		return err
+	}
+	return db.Create(state).Error
+}
+
+func (r *IdentityRepo) ConsumeState(ctx context.Context, stateHash string, now time.Time) (*entity.OIDCState, error) {
+	// 1. 查询
+	var state entity.OIDCState
+	err := r.db.WithContext(ctx).Where("state_hash = ?", stateHash).First(&state).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, common.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. 删除，并发回调时只有删除成功的那个请求能继续
+	res := r.db.WithContext(ctx).Where("id = ?", state.ID).Delete(&entity.OIDCState{})
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if res.RowsAffected == 0 || now.After(state.ExpiresAt) {
+		return nil, common.ErrNotFound
+	}
+	return &state, nil
+}
@@ -0,0 +1,62 @@
+package gorm
+
+import (
+	"context"
+	"errors"
+	"github/CiroLong/realworld-gin/internal/model/entity"
+	"github/CiroLong/realworld-gin/internal/pkg/common"
+	"github/CiroLong/realworld-gin/internal/repository"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type LoginAttemptRepo struct {
+	db *gorm.DB
+}
+
+func NewLoginAttemptRepo(db *gorm.DB) repository.LoginAttemptRepo {
+	return &LoginAttemptRepo{db: db}
+}
+
+func (r *LoginAttemptRepo) Get(ctx context.Context, subject string) (*entity.LoginAttempt, error) {
+	var attempt entity.LoginAttempt
+	err := r.db.WithContext(ctx).Where("subject = ?", subject).First(&attempt).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, common.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &attempt, nil
+}
+
+func (r *LoginAttemptRepo) RecordFailure(ctx context.Context, subject string, at time.Time) (*entity.LoginAttempt, error) {
+	// upsert + failures = failures + 1，多个副本同时写也不会丢计数
+	err := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "subject"}},
+			DoUpdates: clause.Set{
+				{Column: clause.Column{Name: "failures"}, Value: gorm.Expr("login_attempts.failures + 1")},
+				{Column: clause.Column{Name: "last_failed_at"}, Value: at},
+				{Column: clause.Column{Name: "updated_at"}, Value: at},
+			},
+		}).
+		Create(&entity.LoginAttempt{Subject: subject, Failures: 1, LastFailedAt: at, UpdatedAt: at}).Error
+	if err != nil {
+		return nil, err
+	}
+	return r.Get(ctx, subject)
+}
+
+func (r *LoginAttemptRepo) Lock(ctx context.Context, subject string, until time.Time) error {
+	return r.db.WithContext(ctx).
+		Model(&entity.LoginAttempt{}).
+		Where("subject = ?", subject).
+		Update("locked_until", until).Error
+}
+
+func (r *LoginAttemptRepo) Reset(ctx context.Context, subject string) error {
+	return r.db.WithContext(ctx).Where("subject = ?", subject).Delete(&entity.LoginAttempt{}).Error
+}
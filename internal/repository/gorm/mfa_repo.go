@@ -0,0 +1,102 @@
+package gorm
+
+import (
+	"context"
+	"errors"
+	"github/CiroLong/realworld-gin/internal/model/entity"
+	"github/CiroLong/realworld-gin/internal/pkg/common"
+	"github/CiroLong/realworld-gin/internal/repository"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type MFARepo struct {
+	db *gorm.DB
+}
+
+func NewMFARepo(db *gorm.DB) repository.MFARepo {
+	return &MFARepo{db: db}
+}
+
+func (r *MFARepo) FindByUserID(ctx context.Context, userID int64) (*entity.UserMFA, error) {
+	var mfa entity.UserMFA
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).First(&mfa).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, common.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &mfa, nil
+}
+
+func (r *MFARepo) Save(ctx context.Context, mfa *entity.UserMFA) error {
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"secret", "enabled_at", "last_used_step", "updated_at"}),
+		}).
+		Create(mfa).Error
+}
+
+func (r *MFARepo) Enable(ctx context.Context, userID int64, enabledAt time.Time) error {
+	return r.db.WithContext(ctx).
+		Model(&entity.UserMFA{}).
+		Where("user_id = ?", userID).
+		Update("enabled_at", enabledAt).Error
+}
+
+func (r *MFARepo) Delete(ctx context.Context, userID int64) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&entity.MFARecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&entity.UserMFA{}).Error
+	})
+}
+
+func (r *MFARepo) AdvanceStep(ctx context.Context, userID int64, step int64) (bool, error) {
+	// 条件更新：并发提交同一个验证码时只有一个能成功
+	res := r.db.WithContext(ctx).
+		Model(&entity.UserMFA{}).
+		Where("user_id = ? AND last_used_step < ?", userID, step).
+		Update("last_used_step", step)
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected == 1, nil
+}
+
+func (r *MFARepo) ReplaceRecoveryCodes(ctx context.Context, userID int64, codes []*entity.MFARecoveryCode) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&entity.MFARecoveryCode{}).Error; err != nil {
+			return err
+		}
+		if len(codes) == 0 {
+			return nil
+		}
+		return tx.Create(&codes).Error
+	})
+}
+
+func (r *MFARepo) UseRecoveryCode(ctx context.Context, userID int64, hash string) (bool, error) {
+	res := r.db.WithContext(ctx).
+		Model(&entity.MFARecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, hash).
+		Update("used_at", time.Now())
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected == 1, nil
+}
+
+func (r *MFARepo) CountRecoveryCodes(ctx context.Context, userID int64) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&entity.MFARecoveryCode{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Count(&count).Error
+	return count, err
+}
@@ -0,0 +1,101 @@
+package gorm
+
+// 需要用 Go 代码完成的数据迁移
+// 对应版本的 .up.sql 执行完后，在同一个事务里、写 schema_migrations 之前执行；
+// .down.sql 不会反向执行这些步骤
+
+import (
+	"github/CiroLong/realworld-gin/internal/model/entity"
+	"github/CiroLong/realworld-gin/internal/pkg/tagname"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+// dataMigrations 版本号 -> 数据迁移
+var dataMigrations = map[int64]func(tx *gorm.DB) error{
+	18: normalizeTags,
+}
+
+// normalizeTags 按 tagname.Normalize 规范化已有的标签名，规范化后同名的标签合并成一个
+// 1. 每组保留已经是规范写法的标签，没有的话保留 id 最小的，并改成规范写法（超长的截断到 tagname.MaxLength）
+// 2. 其他标签的文章关联转到保留的标签上，文章已经有保留的标签时直接删掉重复的关联，然后删除这些标签
+// 3. 规范化后为空的标签连同关联一起删除
+func normalizeTags(tx *gorm.DB) error {
+	var tags []*entity.Tag
+	if err := tx.Order("id").Find(&tags).Error; err != nil {
+		return err
+	}
+
+	groups := make(map[string][]*entity.Tag)
+	var names []string
+	for _, tag := range tags {
+		name := tagname.Normalize(tag.Name)
+		if utf8.RuneCountInString(name) > tagname.MaxLength {
+			name = string([]rune(name)[:tagname.MaxLength])
+		}
+		if _, ok := groups[name]; !ok {
+			names = append(names, name)
+		}
+		groups[name] = append(groups[name], tag)
+	}
+
+	for _, name := range names {
+		group := groups[name]
+		if name == "" {
+			for _, tag := range group {
+				if err := deleteTag(tx, tag.ID); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		keep := group[0]
+		for _, tag := range group {
+			if tag.Name == name {
+				keep = tag
+				break
+			}
+		}
+		for _, tag := range group {
+			if tag == keep {
+				continue
+			}
+			if err := mergeTag(tx, tag.ID, keep.ID); err != nil {
+				return err
+			}
+		}
+		if keep.Name != name {
+			if err := tx.Model(keep).Update("name", name).Error; err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// mergeTag 把 from 的文章关联转到 to 上并删除 from
+func mergeTag(tx *gorm.DB, from, to int64) error {
+	var articleIDs []int64
+	if err := tx.Model(&entity.ArticleTag{}).Where("tag_id = ?", to).Pluck("article_id", &articleIDs).Error; err != nil {
+		return err
+	}
+	if len(articleIDs) > 0 {
+		if err := tx.Where("tag_id = ? AND article_id IN ?", from, articleIDs).Delete(&entity.ArticleTag{}).Error; err != nil {
+			return err
+		}
+	}
+	if err := tx.Model(&entity.ArticleTag{}).Where("tag_id = ?", from).Update("tag_id", to).Error; err != nil {
+		return err
+	}
+	return tx.Delete(&entity.Tag{}, from).Error
+}
+
+// deleteTag 删除标签和它的文章关联
+func deleteTag(tx *gorm.DB, id int64) error {
+	if err := tx.Where("tag_id = ?", id).Delete(&entity.ArticleTag{}).Error; err != nil {
+		return err
+	}
+	return tx.Delete(&entity.Tag{}, id).Error
+}
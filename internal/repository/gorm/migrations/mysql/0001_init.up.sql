@@ -0,0 +1,69 @@
+-- 初始表结构，与此前 gorm AutoMigrate 生成的结构一致
+-- 使用 IF NOT EXISTS，已经由 AutoMigrate 建过表的库可以直接纳入版本管理
+
+CREATE TABLE IF NOT EXISTS users (
+    id BIGINT NOT NULL AUTO_INCREMENT,
+    email VARCHAR(255) NOT NULL,
+    username VARCHAR(50) NOT NULL,
+    password VARCHAR(255) NOT NULL,
+    bio TEXT,
+    image VARCHAR(255),
+    created_at DATETIME(3) NULL,
+    updated_at DATETIME(3) NULL,
+    PRIMARY KEY (id),
+    UNIQUE INDEX idx_users_email (email),
+    UNIQUE INDEX idx_users_username (username)
+);
+
+CREATE TABLE IF NOT EXISTS articles (
+    id BIGINT NOT NULL AUTO_INCREMENT,
+    slug VARCHAR(255) NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    description VARCHAR(255) NOT NULL,
+    body TEXT NOT NULL,
+    author_id BIGINT NOT NULL,
+    favorites_count BIGINT NOT NULL DEFAULT 0,
+    created_at DATETIME(3) NULL,
+    updated_at DATETIME(3) NULL,
+    PRIMARY KEY (id),
+    UNIQUE INDEX idx_articles_slug (slug),
+    INDEX idx_articles_author_id (author_id)
+);
+
+CREATE TABLE IF NOT EXISTS tags (
+    id BIGINT NOT NULL AUTO_INCREMENT,
+    name VARCHAR(50) NOT NULL,
+    PRIMARY KEY (id),
+    UNIQUE INDEX idx_tags_name (name)
+);
+
+CREATE TABLE IF NOT EXISTS article_tags (
+    article_id BIGINT NOT NULL,
+    tag_id BIGINT NOT NULL,
+    PRIMARY KEY (article_id, tag_id)
+);
+
+CREATE TABLE IF NOT EXISTS favorites (
+    user_id BIGINT NOT NULL,
+    article_id BIGINT NOT NULL,
+    PRIMARY KEY (user_id, article_id)
+);
+
+CREATE TABLE IF NOT EXISTS follows (
+    follower_id BIGINT NOT NULL,
+    following_id BIGINT NOT NULL,
+    created_at DATETIME(3) NULL,
+    PRIMARY KEY (follower_id, following_id)
+);
+
+CREATE TABLE IF NOT EXISTS comments (
+    id BIGINT NOT NULL AUTO_INCREMENT,
+    body TEXT NOT NULL,
+    article_id BIGINT NOT NULL,
+    author_id BIGINT NOT NULL,
+    created_at DATETIME(3) NULL,
+    updated_at DATETIME(3) NULL,
+    PRIMARY KEY (id),
+    INDEX idx_comments_article_id (article_id),
+    INDEX idx_comments_author_id (author_id)
+);
@@ -0,0 +1,12 @@
+CREATE TABLE one_time_tokens (
+    id BIGINT NOT NULL AUTO_INCREMENT,
+    user_id BIGINT NOT NULL,
+    purpose VARCHAR(32) NOT NULL,
+    token_hash VARCHAR(64) NOT NULL,
+    expires_at DATETIME(3) NOT NULL,
+    used_at DATETIME(3) NULL,
+    created_at DATETIME(3) NULL,
+    PRIMARY KEY (id),
+    UNIQUE INDEX idx_one_time_tokens_token_hash (token_hash),
+    INDEX idx_one_time_tokens_user_purpose (user_id, purpose)
+);
@@ -0,0 +1,19 @@
+CREATE TABLE user_mfa (
+    user_id BIGINT NOT NULL,
+    secret VARCHAR(255) NOT NULL,
+    enabled_at DATETIME(3) NULL,
+    last_used_step BIGINT NOT NULL DEFAULT 0,
+    created_at DATETIME(3) NULL,
+    updated_at DATETIME(3) NULL,
+    PRIMARY KEY (user_id)
+);
+
+CREATE TABLE mfa_recovery_codes (
+    id BIGINT NOT NULL AUTO_INCREMENT,
+    user_id BIGINT NOT NULL,
+    code_hash VARCHAR(64) NOT NULL,
+    used_at DATETIME(3) NULL,
+    created_at DATETIME(3) NULL,
+    PRIMARY KEY (id),
+    INDEX idx_mfa_recovery_codes_user_id (user_id)
+);
@@ -0,0 +1,25 @@
+CREATE TABLE user_identities (
+    id BIGINT NOT NULL AUTO_INCREMENT,
+    user_id BIGINT NOT NULL,
+    provider VARCHAR(50) NOT NULL,
+    subject VARCHAR(191) NOT NULL,
+    email VARCHAR(255) NOT NULL DEFAULT '',
+    created_at DATETIME(3) NULL,
+    PRIMARY KEY (id),
+    UNIQUE INDEX idx_user_identities_provider_subject (provider, subject),
+    UNIQUE INDEX idx_user_identities_user_provider (user_id, provider)
+);
+
+CREATE TABLE oidc_states (
+    id BIGINT NOT NULL AUTO_INCREMENT,
+    state_hash VARCHAR(64) NOT NULL,
+    provider VARCHAR(50) NOT NULL,
+    nonce VARCHAR(64) NOT NULL,
+    code_verifier VARCHAR(128) NOT NULL,
+    user_id BIGINT NOT NULL DEFAULT 0,
+    expires_at DATETIME(3) NOT NULL,
+    created_at DATETIME(3) NULL,
+    PRIMARY KEY (id),
+    UNIQUE INDEX idx_oidc_states_state_hash (state_hash),
+    INDEX idx_oidc_states_expires_at (expires_at)
+);
@@ -0,0 +1,6 @@
+ALTER TABLE sessions ADD COLUMN user_agent VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE sessions ADD COLUMN ip VARCHAR(64) NOT NULL DEFAULT '';
+ALTER TABLE sessions ADD COLUMN last_seen_at DATETIME(3) NULL;
+
+-- 已有会话没有访问记录，以创建时间代替
+UPDATE sessions SET last_seen_at = created_at;
@@ -0,0 +1,3 @@
+ALTER TABLE users DROP COLUMN role;
+
+DROP TABLE IF EXISTS roles;
@@ -0,0 +1,5 @@
+ALTER TABLE sessions DROP COLUMN impersonator_id;
+
+DROP INDEX idx_users_suspended_at ON users;
+ALTER TABLE users DROP COLUMN suspend_reason;
+ALTER TABLE users DROP COLUMN suspended_at;
@@ -0,0 +1,5 @@
+ALTER TABLE users ADD COLUMN suspended_at DATETIME(3) NULL;
+ALTER TABLE users ADD COLUMN suspend_reason VARCHAR(255) NOT NULL DEFAULT '';
+CREATE INDEX idx_users_suspended_at ON users (suspended_at);
+
+ALTER TABLE sessions ADD COLUMN impersonator_id BIGINT NOT NULL DEFAULT 0;
@@ -0,0 +1,12 @@
+CREATE TABLE data_exports (
+    id BIGINT NOT NULL AUTO_INCREMENT,
+    user_id BIGINT NOT NULL,
+    status VARCHAR(16) NOT NULL,
+    data LONGBLOB NULL,
+    error VARCHAR(255) NOT NULL DEFAULT '',
+    expires_at DATETIME(3) NOT NULL,
+    completed_at DATETIME(3) NULL,
+    created_at DATETIME(3) NULL,
+    PRIMARY KEY (id),
+    INDEX idx_data_exports_user_id (user_id)
+);
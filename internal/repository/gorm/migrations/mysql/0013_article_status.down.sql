@@ -0,0 +1,4 @@
+DROP INDEX idx_articles_published_at ON articles;
+DROP INDEX idx_articles_status ON articles;
+ALTER TABLE articles DROP COLUMN published_at;
+ALTER TABLE articles DROP COLUMN status;
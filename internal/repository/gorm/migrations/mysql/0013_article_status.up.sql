@@ -0,0 +1,5 @@
+ALTER TABLE articles ADD COLUMN status VARCHAR(16) NOT NULL DEFAULT 'published';
+ALTER TABLE articles ADD COLUMN published_at DATETIME(3) NULL;
+UPDATE articles SET published_at = created_at;
+CREATE INDEX idx_articles_status ON articles (status);
+CREATE INDEX idx_articles_published_at ON articles (published_at);
@@ -0,0 +1,2 @@
+DROP INDEX idx_articles_publish_at ON articles;
+ALTER TABLE articles DROP COLUMN publish_at;
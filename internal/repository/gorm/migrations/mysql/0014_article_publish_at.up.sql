@@ -0,0 +1,2 @@
+ALTER TABLE articles ADD COLUMN publish_at DATETIME(3) NULL;
+CREATE INDEX idx_articles_publish_at ON articles (publish_at);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS article_slugs;
+ALTER TABLE articles DROP COLUMN slug_locked;
@@ -0,0 +1,13 @@
+-- 作者自定义或锁定的 slug，修改标题时不再重新生成
+ALTER TABLE articles ADD COLUMN slug_locked BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- 文章用过的旧 slug，旧链接据此找到文章当前的 slug
+CREATE TABLE article_slugs (
+    id BIGINT NOT NULL AUTO_INCREMENT,
+    article_id BIGINT NOT NULL,
+    slug VARCHAR(255) NOT NULL,
+    created_at DATETIME(3) NULL,
+    PRIMARY KEY (id),
+    UNIQUE INDEX idx_article_slugs_slug (slug),
+    INDEX idx_article_slugs_article_id (article_id)
+);
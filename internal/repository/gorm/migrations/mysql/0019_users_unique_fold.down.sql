@@ -0,0 +1,2 @@
+-- 0019_users_unique_fold (mysql, down)
+-- 无法得知迁移前的排序规则，回滚不做任何修改
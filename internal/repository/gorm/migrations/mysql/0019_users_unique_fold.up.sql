@@ -0,0 +1,6 @@
+-- 邮箱和用户名的唯一索引需要不区分大小写，和 equalFold 的查询方式一致
+-- 显式指定 utf8mb4_unicode_ci，不依赖服务器的默认排序规则
+-- 已有仅大小写不同的重复数据时迁移会失败，需要先手工合并这些账号
+ALTER TABLE users
+    MODIFY email VARCHAR(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NOT NULL,
+    MODIFY username VARCHAR(50) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NOT NULL;
@@ -0,0 +1,2 @@
+-- 0018_normalize_tags (postgres, up)
+-- 规范化已有的标签名并合并同名标签，见 migrate_data.go 中的 normalizeTags
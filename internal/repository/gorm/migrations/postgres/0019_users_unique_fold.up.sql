@@ -0,0 +1,6 @@
+-- 邮箱和用户名按小写建唯一索引，和 equalFold 的查询方式一致，大小写不同的邮箱 / 用户名不能同时存在
+-- 已有仅大小写不同的重复数据时迁移会失败，需要先手工合并这些账号
+DROP INDEX IF EXISTS idx_users_email;
+DROP INDEX IF EXISTS idx_users_username;
+CREATE UNIQUE INDEX idx_users_email ON users (LOWER(email));
+CREATE UNIQUE INDEX idx_users_username ON users (LOWER(username));
@@ -0,0 +1,11 @@
+CREATE TABLE one_time_tokens (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id BIGINT NOT NULL,
+    purpose VARCHAR(32) NOT NULL,
+    token_hash VARCHAR(64) NOT NULL,
+    expires_at DATETIME NOT NULL,
+    used_at DATETIME,
+    created_at DATETIME
+);
+CREATE UNIQUE INDEX idx_one_time_tokens_token_hash ON one_time_tokens (token_hash);
+CREATE INDEX idx_one_time_tokens_user_purpose ON one_time_tokens (user_id, purpose);
@@ -0,0 +1,4 @@
+ALTER TABLE users ADD COLUMN email_verified_at DATETIME;
+
+-- 已有用户视为已验证，避免上线后老用户突然无法发文
+UPDATE users SET email_verified_at = COALESCE(created_at, CURRENT_TIMESTAMP);
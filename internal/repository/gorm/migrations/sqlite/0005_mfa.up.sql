@@ -0,0 +1,17 @@
+CREATE TABLE user_mfa (
+    user_id BIGINT PRIMARY KEY,
+    secret VARCHAR(255) NOT NULL,
+    enabled_at DATETIME,
+    last_used_step BIGINT NOT NULL DEFAULT 0,
+    created_at DATETIME,
+    updated_at DATETIME
+);
+
+CREATE TABLE mfa_recovery_codes (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id BIGINT NOT NULL,
+    code_hash VARCHAR(64) NOT NULL,
+    used_at DATETIME,
+    created_at DATETIME
+);
+CREATE INDEX idx_mfa_recovery_codes_user_id ON mfa_recovery_codes (user_id);
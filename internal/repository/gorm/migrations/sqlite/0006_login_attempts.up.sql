@@ -0,0 +1,7 @@
+CREATE TABLE login_attempts (
+    subject VARCHAR(191) PRIMARY KEY,
+    failures INT NOT NULL DEFAULT 0,
+    last_failed_at DATETIME NOT NULL,
+    locked_until DATETIME,
+    updated_at DATETIME
+);
@@ -0,0 +1,23 @@
+CREATE TABLE user_identities (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id BIGINT NOT NULL,
+    provider VARCHAR(50) NOT NULL,
+    subject VARCHAR(191) NOT NULL,
+    email VARCHAR(255) NOT NULL DEFAULT '',
+    created_at DATETIME
+);
+CREATE UNIQUE INDEX idx_user_identities_provider_subject ON user_identities (provider, subject);
+CREATE UNIQUE INDEX idx_user_identities_user_provider ON user_identities (user_id, provider);
+
+CREATE TABLE oidc_states (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    state_hash VARCHAR(64) NOT NULL,
+    provider VARCHAR(50) NOT NULL,
+    nonce VARCHAR(64) NOT NULL,
+    code_verifier VARCHAR(128) NOT NULL,
+    user_id BIGINT NOT NULL DEFAULT 0,
+    expires_at DATETIME NOT NULL,
+    created_at DATETIME
+);
+CREATE UNIQUE INDEX idx_oidc_states_state_hash ON oidc_states (state_hash);
+CREATE INDEX idx_oidc_states_expires_at ON oidc_states (expires_at);
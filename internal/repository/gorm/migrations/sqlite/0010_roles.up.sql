@@ -0,0 +1,11 @@
+CREATE TABLE roles (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name VARCHAR(50) NOT NULL,
+    description VARCHAR(255) NOT NULL DEFAULT '',
+    permissions VARCHAR(1000) NOT NULL DEFAULT '',
+    created_at DATETIME,
+    updated_at DATETIME
+);
+CREATE UNIQUE INDEX idx_roles_name ON roles (name);
+
+ALTER TABLE users ADD COLUMN role VARCHAR(50) NOT NULL DEFAULT 'user';
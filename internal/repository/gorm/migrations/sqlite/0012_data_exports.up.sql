@@ -0,0 +1,11 @@
+CREATE TABLE data_exports (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id BIGINT NOT NULL,
+    status VARCHAR(16) NOT NULL,
+    data BLOB,
+    error VARCHAR(255) NOT NULL DEFAULT '',
+    expires_at DATETIME NOT NULL,
+    completed_at DATETIME,
+    created_at DATETIME
+);
+CREATE INDEX idx_data_exports_user_id ON data_exports (user_id);
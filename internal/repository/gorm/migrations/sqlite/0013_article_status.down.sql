@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_articles_published_at;
+DROP INDEX IF EXISTS idx_articles_status;
+ALTER TABLE articles DROP COLUMN published_at;
+ALTER TABLE articles DROP COLUMN status;
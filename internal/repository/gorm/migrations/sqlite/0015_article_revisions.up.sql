@@ -0,0 +1,16 @@
+CREATE TABLE article_revisions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    article_id BIGINT NOT NULL,
+    editor_id BIGINT NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    description VARCHAR(255) NOT NULL,
+    body TEXT NOT NULL,
+    restored_from BIGINT NOT NULL DEFAULT 0,
+    created_at DATETIME
+);
+CREATE INDEX idx_article_revisions_article_id ON article_revisions (article_id);
+CREATE INDEX idx_article_revisions_editor_id ON article_revisions (editor_id);
+
+-- 已有文章以当前内容作为第一条修订
+INSERT INTO article_revisions (article_id, editor_id, title, description, body, created_at)
+SELECT id, author_id, title, description, body, updated_at FROM articles;
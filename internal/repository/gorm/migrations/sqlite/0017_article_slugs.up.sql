@@ -0,0 +1,12 @@
+-- 作者自定义或锁定的 slug，修改标题时不再重新生成
+ALTER TABLE articles ADD COLUMN slug_locked BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- 文章用过的旧 slug，旧链接据此找到文章当前的 slug
+CREATE TABLE article_slugs (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    article_id BIGINT NOT NULL,
+    slug VARCHAR(255) NOT NULL,
+    created_at DATETIME
+);
+CREATE UNIQUE INDEX idx_article_slugs_slug ON article_slugs (slug);
+CREATE INDEX idx_article_slugs_article_id ON article_slugs (article_id);
@@ -0,0 +1,2 @@
+-- 0018_normalize_tags (sqlite, down)
+-- 合并后的标签无法还原，回滚不做任何修改
@@ -0,0 +1,65 @@
+package gorm
+
+import (
+	"context"
+	"errors"
+	"github/CiroLong/realworld-gin/internal/model/entity"
+	"github/CiroLong/realworld-gin/internal/pkg/common"
+	"github/CiroLong/realworld-gin/internal/repository"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type OneTimeTokenRepo struct {
+	db *gorm.DB
+}
+
+func NewOneTimeTokenRepo(db *gorm.DB) repository.OneTimeTokenRepo {
+	return &OneTimeTokenRepo{db: db}
+}
+
+func (r *OneTimeTokenRepo) Create(ctx context.Context, token *entity.OneTimeToken) error {
+	return r.db.WithContext(ctx).Create(token).Error
+}
+
+func (r *OneTimeTokenRepo) FindByHash(ctx context.Context, purpose string, hash string) (*entity.OneTimeToken, error) {
+	var token entity.OneTimeToken
+	err := r.db.WithContext(ctx).
+		Where("token_hash = ? AND purpose = ?", hash, purpose).
+		First(&token).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, common.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (r *OneTimeTokenRepo) Consume(ctx context.Context, id int64) (bool, error) {
+	res := r.db.WithContext(ctx).
+		Model(&entity.OneTimeToken{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", time.Now())
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected == 1, nil
+}
+
+func (r *OneTimeTokenRepo) InvalidateUserTokens(ctx context.Context, userID int64, purpose string) error {
+	return r.db.WithContext(ctx).
+		Model(&entity.OneTimeToken{}).
+		Where("user_id = ? AND purpose = ? AND used_at IS NULL", userID, purpose).
+		Update("used_at", time.Now()).Error
+}
+
+func (r *OneTimeTokenRepo) CountIssuedSince(ctx context.Context, userID int64, purpose string, since time.Time) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&entity.OneTimeToken{}).
+		Where("user_id = ? AND purpose = ? AND created_at >= ?", userID, purpose, since).
+		Count(&count).Error
+	return count, err
+}
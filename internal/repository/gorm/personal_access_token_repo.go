@@ -0,0 +1,73 @@
+package gorm
+
+import (
+	"context"
+	"errors"
+	"github/CiroLong/realworld-gin/internal/model/entity"
+	"github/CiroLong/realworld-gin/internal/pkg/common"
+	"github/CiroLong/realworld-gin/internal/repository"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type PersonalAccessTokenRepo struct {
+	db *gorm.DB
+}
+
+func NewPersonalAccessTokenRepo(db *gorm.DB) repository.PersonalAccessTokenRepo {
+	return &PersonalAccessTokenRepo{db: db}
+}
+
+func (r *PersonalAccessTokenRepo) Create(ctx context.Context, token *entity.PersonalAccessToken) error {
+	return r.db.WithContext(ctx).Create(token).Error
+}
+
+func (r *PersonalAccessTokenRepo) FindByHash(ctx context.Context, hash string) (*entity.PersonalAccessToken, error) {
+	var token entity.PersonalAccessToken
+	err := r.db.WithContext(ctx).Where("token_hash = ?", hash).First(&token).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, common.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (r *PersonalAccessTokenRepo) ListByUser(ctx context.Context, userID int64) ([]*entity.PersonalAccessToken, error) {
+	var tokens []*entity.PersonalAccessToken
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Order("created_at DESC, id DESC").
+		Find(&tokens).Error
+	return tokens, err
+}
+
+func (r *PersonalAccessTokenRepo) Revoke(ctx context.Context, userID int64, id int64) error {
+	res := r.db.WithContext(ctx).
+		Model(&entity.PersonalAccessToken{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", id, userID).
+		Update("revoked_at", time.Now())
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return common.ErrNotFound
+	}
+	return nil
+}
+
+func (r *PersonalAccessTokenRepo) RevokeUserTokens(ctx context.Context, userID int64) error {
+	return r.db.WithContext(ctx).
+		Model(&entity.PersonalAccessToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
+
+func (r *PersonalAccessTokenRepo) TouchLastUsed(ctx context.Context, id int64, at time.Time) error {
+	return r.db.WithContext(ctx).
+		Model(&entity.PersonalAccessToken{}).
+		Where("id = ?", id).
+		Update("last_used_at", at).Error
+}
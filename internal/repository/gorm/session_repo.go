@@ -0,0 +1,97 @@
+package gorm
+
+import (
+	"context"
+	"errors"
+	"github/CiroLong/realworld-gin/internal/model/entity"
+	"github/CiroLong/realworld-gin/internal/pkg/common"
+	"github/CiroLong/realworld-gin/internal/repository"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type SessionRepo struct {
+	db *gorm.DB
+}
+
+func NewSessionRepo(db *gorm.DB) repository.SessionRepo {
+	return &SessionRepo{db: db}
+}
+
+func (r *SessionRepo) CreateSession(ctx context.Context, session *entity.Session) error {
+	return r.db.WithContext(ctx).Create(session).Error
+}
+
+func (r *SessionRepo) FindSessionByID(ctx context.Context, id int64) (*entity.Session, error) {
+	var session entity.Session
+	err := r.db.WithContext(ctx).First(&session, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, common.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (r *SessionRepo) RevokeSession(ctx context.Context, id int64) error {
+	return r.db.WithContext(ctx).
+		Model(&entity.Session{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now()).Error
+}
+
+func (r *SessionRepo) RevokeUserSessions(ctx context.Context, userID int64) error {
+	return r.db.WithContext(ctx).
+		Model(&entity.Session{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
+
+func (r *SessionRepo) ListActiveSessions(ctx context.Context, userID int64, since time.Time) ([]*entity.Session, error) {
+	var sessions []*entity.Session
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND revoked_at IS NULL AND last_seen_at >= ?", userID, since).
+		Order("last_seen_at DESC").
+		Find(&sessions).Error
+	if err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+func (r *SessionRepo) TouchSession(ctx context.Context, id int64, at time.Time) error {
+	return r.db.WithContext(ctx).
+		Model(&entity.Session{}).
+		Where("id = ?", id).
+		Update("last_seen_at", at).Error
+}
+
+func (r *SessionRepo) CreateRefreshToken(ctx context.Context, token *entity.RefreshToken) error {
+	return r.db.WithContext(ctx).Create(token).Error
+}
+
+func (r *SessionRepo) FindRefreshTokenByHash(ctx context.Context, hash string) (*entity.RefreshToken, error) {
+	var token entity.RefreshToken
+	err := r.db.WithContext(ctx).Where("token_hash = ?", hash).First(&token).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, common.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (r *SessionRepo) MarkRefreshTokenUsed(ctx context.Context, id int64) (bool, error) {
+	// 条件更新：只有 used_at 为空时才能置位，并发刷新时只有一个请求能成功
+	res := r.db.WithContext(ctx).
+		Model(&entity.RefreshToken{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", time.Now())
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected == 1, nil
+}
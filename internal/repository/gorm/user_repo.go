@@ -6,6 +6,8 @@ import (
 	"github/CiroLong/realworld-gin/internal/model/entity"
 	"github/CiroLong/realworld-gin/internal/pkg/common"
 	"github/CiroLong/realworld-gin/internal/repository"
+	"strings"
+	"time"
 
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
@@ -20,6 +22,7 @@ func NewUserRepo(db *gorm.DB) repository.UserRepo {
 }
 
 func (r *UserRepo) Create(ctx context.Context, user *entity.User) error {
+	user.Version = 1
 	err := r.db.WithContext(ctx).Create(user).Error
 	if err == nil {
 		return nil
@@ -37,7 +40,7 @@ func (r *UserRepo) FindByEmail(ctx context.Context, email string) (*entity.User,
 	var user entity.User
 
 	err := r.db.WithContext(ctx).
-		Where("email = ?", email).
+		Where(equalFold(r.db, "email"), email).
 		First(&user).Error
 
 	if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -55,7 +58,7 @@ func (r *UserRepo) FindByUsername(ctx context.Context, username string) (*entity
 	var user entity.User
 
 	err := r.db.WithContext(ctx).
-		Where("username = ?", username).
+		Where(equalFold(r.db, "username"), username).
 		First(&user).Error
 
 	if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -85,22 +88,118 @@ func (r *UserRepo) FindByID(ctx context.Context, id int64) (*entity.User, error)
 }
 
 func (r *UserRepo) Update(ctx context.Context, user *entity.User) error {
+	// 1. 以读到的版本为条件更新，同时把版本加一
+	version := user.Version
+	user.Version++
 	res := r.db.WithContext(ctx).
 		Model(&entity.User{}).
-		Where("id = ?", user.ID).
+		Where("id = ? AND version = ?", user.ID, version).
 		Updates(user)
 
 	if res.Error != nil {
+		user.Version = version
+		// 改成了别人已在用的 email / username（唯一索引不区分大小写）
+		if errors.Is(res.Error, gorm.ErrDuplicatedKey) {
+			return common.ErrUserAlreadyExist
+		}
 		return res.Error
 	}
 
+	// 2. 没有更新到行：用户不存在，或者期间被别人改过
+	if res.RowsAffected == 0 {
+		user.Version = version
+		var count int64
+		if err := r.db.WithContext(ctx).Model(&entity.User{}).Where("id = ?", user.ID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			return common.ErrUserNotFound
+		}
+		return common.ErrVersionMismatch
+	}
+
+	return nil
+}
+
+func (r *UserRepo) SetEmailVerified(ctx context.Context, id int64, verifiedAt *time.Time) error {
+	// Updates(struct) 会跳过零值，这里单独更新才能把字段置空
+	res := r.db.WithContext(ctx).
+		Model(&entity.User{}).
+		Where("id = ?", id).
+		Update("email_verified_at", verifiedAt)
+	if res.Error != nil {
+		return res.Error
+	}
 	if res.RowsAffected == 0 {
 		return common.ErrUserNotFound
 	}
+	return nil
+}
 
+func (r *UserRepo) RehashPassword(ctx context.Context, id int64, oldHash string, newHash string) error {
+	// 用户看来什么都没改，不走 Update，免得客户端手里的版本号失效
+	res := r.db.WithContext(ctx).
+		Model(&entity.User{}).
+		Where("id = ? AND password = ?", id, oldHash).
+		UpdateColumn("password", newHash)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return common.ErrVersionMismatch
+	}
+	return nil
+}
+
+func (r *UserRepo) SetSuspended(ctx context.Context, id int64, suspendedAt *time.Time, reason string) error {
+	// 同 SetEmailVerified，用 map 才能把字段置空
+	res := r.db.WithContext(ctx).
+		Model(&entity.User{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{"suspended_at": suspendedAt, "suspend_reason": reason})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return common.ErrUserNotFound
+	}
 	return nil
 }
 
+func (r *UserRepo) List(ctx context.Context, filter repository.ListUsersFilter) ([]*entity.User, int64, error) {
+	db := r.db.WithContext(ctx).Model(&entity.User{})
+
+	// --- 邮箱 / 用户名模糊匹配 ---
+	if filter.Query != "" {
+		pattern := "%" + strings.ToLower(filter.Query) + "%"
+		db = db.Where("LOWER(email) LIKE ? OR LOWER(username) LIKE ?", pattern, pattern)
+	}
+
+	// --- 停用状态 ---
+	if filter.Suspended != nil {
+		if *filter.Suspended {
+			db = db.Where("suspended_at IS NOT NULL")
+		} else {
+			db = db.Where("suspended_at IS NULL")
+		}
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var users []*entity.User
+	if err := db.
+		Order("id DESC").
+		Limit(filter.Limit).
+		Offset(filter.Offset).
+		Find(&users).Error; err != nil {
+		return nil, 0, err
+	}
+	return users, total, nil
+}
+
 func (r *UserRepo) IsFollowing(ctx context.Context, followerID int64, followingID int64) (bool, error) {
 	var count int64
 	err := r.db.WithContext(ctx).
@@ -137,3 +236,100 @@ func (r *UserRepo) UnFollow(ctx context.Context, followerID int64, followingID i
 
 	return err
 }
+
+func (r *UserRepo) ListFollowing(ctx context.Context, userID int64) ([]*entity.User, error) {
+	var users []*entity.User
+	err := r.db.WithContext(ctx).
+		Joins("JOIN follows f ON f.following_id = users.id").
+		Where("f.follower_id = ?", userID).
+		Order("f.created_at ASC, users.id ASC").
+		Find(&users).Error
+	return users, err
+}
+
+func (r *UserRepo) ListFollowers(ctx context.Context, userID int64) ([]*entity.User, error) {
+	var users []*entity.User
+	err := r.db.WithContext(ctx).
+		Joins("JOIN follows f ON f.follower_id = users.id").
+		Where("f.following_id = ?", userID).
+		Order("f.created_at ASC, users.id ASC").
+		Find(&users).Error
+	return users, err
+}
+
+func (r *UserRepo) Delete(ctx context.Context, id int64, reassignTo int64) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 1. 收藏：先把被收藏文章的计数减回去，再删收藏记录
+		favorited := tx.Model(&entity.Favorite{}).Select("article_id").Where("user_id = ?", id)
+		if err := tx.Model(&entity.Article{}).
+			Where("id IN (?) AND favorites_count > 0", favorited).
+			UpdateColumn("favorites_count", gorm.Expr("favorites_count - ?", 1)).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", id).Delete(&entity.Favorite{}).Error; err != nil {
+			return err
+		}
+
+		// 2. 关注和被关注
+		if err := tx.Where("follower_id = ? OR following_id = ?", id, id).Delete(&entity.Follow{}).Error; err != nil {
+			return err
+		}
+
+		// 3. 文章和评论：转给占位账号（不改 updated_at），或者连同文章下别人的评论、收藏一起删
+		if reassignTo != 0 {
+			if err := tx.Model(&entity.Article{}).Where("author_id = ?", id).UpdateColumn("author_id", reassignTo).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&entity.Comment{}).Where("author_id = ?", id).UpdateColumn("author_id", reassignTo).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&entity.ArticleRevision{}).Where("editor_id = ?", id).UpdateColumn("editor_id", reassignTo).Error; err != nil {
+				return err
+			}
+		} else {
+			articles := tx.Model(&entity.Article{}).Select("id").Where("author_id = ?", id)
+			for _, model := range []interface{}{&entity.Favorite{}, &entity.Comment{}, &entity.ArticleTag{}, &entity.ArticleRevision{}, &entity.ArticleSlug{}} {
+				if err := tx.Where("article_id IN (?)", articles).Delete(model).Error; err != nil {
+					return err
+				}
+			}
+			if err := tx.Where("author_id = ?", id).Delete(&entity.Comment{}).Error; err != nil {
+				return err
+			}
+			// 在别人文章上的修订也是该用户写的内容
+			if err := tx.Where("editor_id = ?", id).Delete(&entity.ArticleRevision{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("author_id = ?", id).Delete(&entity.Article{}).Error; err != nil {
+				return err
+			}
+		}
+
+		// 4. 登录凭证和账号相关的其他数据
+		for _, model := range []interface{}{
+			&entity.RefreshToken{},
+			&entity.Session{},
+			&entity.PersonalAccessToken{},
+			&entity.UserIdentity{},
+			&entity.OIDCState{},
+			&entity.UserMFA{},
+			&entity.MFARecoveryCode{},
+			&entity.OneTimeToken{},
+			&entity.DataExport{},
+		} {
+			if err := tx.Where("user_id = ?", id).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+
+		// 5. 用户本身
+		res := tx.Delete(&entity.User{}, id)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return common.ErrUserNotFound
+		}
+		return nil
+	})
+}
@@ -0,0 +1,121 @@
+package gorm
+
+import (
+	"context"
+	"errors"
+	"github/CiroLong/realworld-gin/internal/model/entity"
+	"github/CiroLong/realworld-gin/internal/pkg/common"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func TestUserRepoUniqueIgnoresCase(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, db *gorm.DB) {
+		ctx := context.Background()
+		users := NewUserRepo(db)
+
+		alice := &entity.User{Username: "alice", Email: "alice@example.com", Password: "x"}
+		if err := users.Create(ctx, alice); err != nil {
+			t.Fatal(err)
+		}
+
+		// 1. 仅大小写不同的邮箱 / 用户名不能注册
+		for _, u := range []*entity.User{
+			{Username: "bob", Email: "Alice@Example.com", Password: "x"},
+			{Username: "ALICE", Email: "bob@example.com", Password: "x"},
+		} {
+			if err := users.Create(ctx, u); !errors.Is(err, common.ErrUserAlreadyExist) {
+				t.Errorf("create %s <%s>: err = %v", u.Username, u.Email, err)
+			}
+		}
+
+		// 2. 也不能改成别人邮箱 / 用户名的大小写变体
+		bob := &entity.User{Username: "bob", Email: "bob@example.com", Password: "x"}
+		if err := users.Create(ctx, bob); err != nil {
+			t.Fatal(err)
+		}
+		bob.Email = "ALICE@example.com"
+		if err := users.Update(ctx, bob); !errors.Is(err, common.ErrUserAlreadyExist) {
+			t.Errorf("update email: err = %v", err)
+		}
+		bob.Email, bob.Username = "bob@example.com", "Alice"
+		if err := users.Update(ctx, bob); !errors.Is(err, common.ErrUserAlreadyExist) {
+			t.Errorf("update username: err = %v", err)
+		}
+
+		// 3. 非 ASCII 字母：mysql 的排序规则不区分大小写；sqlite 的 LOWER 只转换 ASCII，É 和 é 算不同的邮箱，
+		// 但查询和唯一索引用的是同一个 LOWER，按邮箱总是只查到一个用户。postgres 取决于数据库的 locale，不检查
+		emile := &entity.User{Username: "emile", Email: "émile@example.com", Password: "x"}
+		if err := users.Create(ctx, emile); err != nil {
+			t.Fatal(err)
+		}
+		upper := &entity.User{Username: "emile2", Email: "ÉMILE@example.com", Password: "x"}
+		err := users.Create(ctx, upper)
+		switch db.Dialector.Name() {
+		case DriverMySQL:
+			if !errors.Is(err, common.ErrUserAlreadyExist) {
+				t.Errorf("create non-ASCII case variant: err = %v", err)
+			}
+		case DriverSQLite:
+			if err != nil {
+				t.Fatalf("create non-ASCII case variant: %v", err)
+			}
+			for _, u := range []*entity.User{emile, upper} {
+				got, err := users.FindByEmail(ctx, u.Email)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got.ID != u.ID {
+					t.Errorf("FindByEmail(%q) = user %d, want %d", u.Email, got.ID, u.ID)
+				}
+			}
+		}
+
+		// 4. 按任意大小写查到的都是同一个用户
+		got, err := users.FindByEmail(ctx, "ALICE@EXAMPLE.COM")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.ID != alice.ID {
+			t.Errorf("FindByEmail = user %d, want %d", got.ID, alice.ID)
+		}
+	})
+}
+
+func TestUserRepoRehashPassword(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, db *gorm.DB) {
+		ctx := context.Background()
+		users := NewUserRepo(db)
+
+		alice := &entity.User{Username: "alice", Email: "alice@example.com", Password: "old-hash"}
+		if err := users.Create(ctx, alice); err != nil {
+			t.Fatal(err)
+		}
+		before, err := users.FindByID(ctx, alice.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// 1. 换哈希不改 version 和 updated_at
+		if err := users.RehashPassword(ctx, alice.ID, "old-hash", "new-hash"); err != nil {
+			t.Fatal(err)
+		}
+		after, err := users.FindByID(ctx, alice.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if after.Password != "new-hash" || after.Version != before.Version || !after.UpdatedAt.Equal(before.UpdatedAt) {
+			t.Errorf("after rehash: password %q, version %d -> %d, updated_at %v -> %v",
+				after.Password, before.Version, after.Version, before.UpdatedAt, after.UpdatedAt)
+		}
+
+		// 2. 哈希已被改过（期间改了密码）时不覆盖
+		if err := users.RehashPassword(ctx, alice.ID, "old-hash", "stale-hash"); !errors.Is(err, common.ErrVersionMismatch) {
+			t.Errorf("rehash a changed password: err = %v", err)
+		}
+		if u, err := users.FindByID(ctx, alice.ID); err != nil || u.Password != "new-hash" {
+			t.Errorf("password after stale rehash = %q, %v", u.Password, err)
+		}
+	})
+}
@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"context"
+	"github/CiroLong/realworld-gin/internal/model/entity"
+	"time"
+)
+
+type IdentityRepo interface {
+	// Create 绑定第三方账号，已被绑定（或该用户已绑定过这个 provider）时返回 common.ErrIdentityAlreadyLinked
+	Create(ctx context.Context, identity *entity.UserIdentity) error
+
+	// FindByProviderSubject 根据第三方账号查绑定关系
+	FindByProviderSubject(ctx context.Context, provider, subject string) (*entity.UserIdentity, error)
+
+	// ListByUser 用户绑定的所有第三方账号
+	ListByUser(ctx context.Context, userID int64) ([]*entity.UserIdentity, error)
+
+	// Delete 解绑，未绑定时返回 common.ErrNotFound
+	Delete(ctx context.Context, userID int64, provider string) error
+
+	// CreateState 保存授权请求上下文，顺便清理已过期的
+	CreateState(ctx context.Context, state *entity.OIDCState) error
+
+	// ConsumeState 取出并删除授权请求上下文，保证只能用一次；不存在时返回 common.ErrNotFound
+	ConsumeState(ctx context.Context, stateHash string, now time.Time) (*entity.OIDCState, error)
+}
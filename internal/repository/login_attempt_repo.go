@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"context"
+	"github/CiroLong/realworld-gin/internal/model/entity"
+	"time"
+)
+
+// LoginAttemptRepo 登录失败计数
+// 多副本部署时需要用共享的实现（SQL），内存实现只在单个进程内有效
+type LoginAttemptRepo interface {
+	// Get 查询计数，没有记录时返回 common.ErrNotFound
+	Get(ctx context.Context, subject string) (*entity.LoginAttempt, error)
+
+	// RecordFailure 失败次数原子加一并返回最新记录
+	RecordFailure(ctx context.Context, subject string, at time.Time) (*entity.LoginAttempt, error)
+
+	// Lock 锁定到 until
+	Lock(ctx context.Context, subject string, until time.Time) error
+
+	// Reset 清空计数和锁定（幂等）
+	Reset(ctx context.Context, subject string) error
+}
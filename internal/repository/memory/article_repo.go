@@ -0,0 +1,625 @@
+package memory
+
+import (
+	"context"
+	"github/CiroLong/realworld-gin/internal/model/entity"
+	"github/CiroLong/realworld-gin/internal/pkg/common"
+	"github/CiroLong/realworld-gin/internal/repository"
+	"sort"
+	"strings"
+	"time"
+)
+
+type articleRepo struct {
+	s *Store
+}
+
+func NewArticleRepo(s *Store) repository.ArticleRepo {
+	return &articleRepo{s: s}
+}
+
+func (a articleRepo) Create(ctx context.Context, article *entity.Article) error {
+	a.s.mu.Lock()
+	defer a.s.mu.Unlock()
+
+	now := time.Now()
+	article.CreatedAt = now
+	article.UpdatedAt = now
+	article.Version = 1
+	if article.Status == "" {
+		article.Status = entity.ArticleStatusPublished
+	}
+
+	if a.s.articleBySlug(article.Slug) != nil {
+		return common.ErrSlugTaken
+	}
+	a.s.createArticle(article)
+	return nil
+}
+
+func (a articleRepo) CreateWithRevisionAndTags(ctx context.Context, article *entity.Article, revision *entity.ArticleRevision, tags []string) error {
+	a.s.mu.Lock()
+	defer a.s.mu.Unlock()
+
+	now := time.Now()
+	article.CreatedAt = now
+	article.UpdatedAt = now
+	article.Version = 1
+	if article.Status == "" {
+		article.Status = entity.ArticleStatusPublished
+	}
+
+	if a.s.articleBySlug(article.Slug) != nil {
+		return common.ErrSlugTaken
+	}
+	a.s.createArticle(article)
+
+	revision.ArticleID = article.ID
+	revision.CreatedAt = now
+	a.s.createRevision(revision)
+	a.s.replaceArticleTags(article.ID, a.s.getOrCreateTags(tags))
+	return nil
+}
+
+func (a articleRepo) FindBySlug(ctx context.Context, slug string) (*entity.Article, error) {
+	a.s.mu.RLock()
+	defer a.s.mu.RUnlock()
+
+	article := a.s.articleBySlug(slug)
+	if article == nil {
+		return nil, common.ErrNotFound
+	}
+	cp := *article
+	return &cp, nil
+}
+
+func (a articleRepo) FindByIDs(ctx context.Context, ids []int64) ([]*entity.Article, error) {
+	a.s.mu.RLock()
+	defer a.s.mu.RUnlock()
+
+	articles := make([]*entity.Article, 0, len(ids))
+	for _, id := range ids {
+		if article, ok := a.s.articles[id]; ok {
+			cp := *article
+			articles = append(articles, &cp)
+		}
+	}
+	return articles, nil
+}
+
+func (a articleRepo) Update(ctx context.Context, article *entity.Article) error {
+	a.s.mu.Lock()
+	defer a.s.mu.Unlock()
+
+	article.UpdatedAt = time.Now()
+	return a.s.updateArticle(article)
+}
+
+func (a articleRepo) Delete(ctx context.Context, articleID int64, version int64) error {
+	a.s.mu.Lock()
+	defer a.s.mu.Unlock()
+
+	if old, ok := a.s.articles[articleID]; !ok || old.Version != version {
+		return common.ErrVersionMismatch
+	}
+	deleteWhere(a.s.revisions, func(rev *entity.ArticleRevision) bool { return rev.ArticleID == articleID })
+	deleteWhere(a.s.articleSlugs, func(old *entity.ArticleSlug) bool { return old.ArticleID == articleID })
+	delete(a.s.articles, articleID)
+	return nil
+}
+
+func (a articleRepo) UpdateWithRevision(ctx context.Context, article *entity.Article, revision *entity.ArticleRevision) error {
+	a.s.mu.Lock()
+	defer a.s.mu.Unlock()
+
+	now := time.Now()
+	article.UpdatedAt = now
+	if err := a.s.updateArticle(article); err != nil {
+		return err
+	}
+
+	revision.ArticleID = article.ID
+	revision.CreatedAt = now
+	a.s.createRevision(revision)
+	return nil
+}
+
+func (a articleRepo) UpdateWithTags(ctx context.Context, article *entity.Article, revision *entity.ArticleRevision, tags []string) error {
+	a.s.mu.Lock()
+	defer a.s.mu.Unlock()
+
+	now := time.Now()
+	article.UpdatedAt = now
+	if err := a.s.updateArticle(article); err != nil {
+		return err
+	}
+
+	if revision != nil {
+		revision.ArticleID = article.ID
+		revision.CreatedAt = now
+		a.s.createRevision(revision)
+	}
+	a.s.replaceArticleTags(article.ID, a.s.getOrCreateTags(tags))
+	return nil
+}
+
+func (a articleRepo) ListRevisions(ctx context.Context, articleID int64, limit, offset int) ([]*entity.ArticleRevision, int64, error) {
+	a.s.mu.RLock()
+	defer a.s.mu.RUnlock()
+
+	revisions := a.s.articleRevisions(articleID)
+	sort.Slice(revisions, func(i, j int) bool { return revisions[i].ID > revisions[j].ID })
+
+	page := paginate(revisions, limit, offset)
+	result := make([]*entity.ArticleRevision, 0, len(page))
+	for _, revision := range page {
+		cp := *revision
+		cp.Body = "" // 与 gorm 实现一致，列表不返回正文
+		result = append(result, &cp)
+	}
+	return result, int64(len(revisions)), nil
+}
+
+func (a articleRepo) FindRevision(ctx context.Context, articleID, revisionID int64) (*entity.ArticleRevision, error) {
+	a.s.mu.RLock()
+	defer a.s.mu.RUnlock()
+
+	revision, ok := a.s.revisions[revisionID]
+	if !ok || revision.ArticleID != articleID {
+		return nil, common.ErrNotFound
+	}
+	cp := *revision
+	return &cp, nil
+}
+
+func (a articleRepo) PreviousRevision(ctx context.Context, articleID, revisionID int64) (*entity.ArticleRevision, error) {
+	a.s.mu.RLock()
+	defer a.s.mu.RUnlock()
+
+	var prev *entity.ArticleRevision
+	for _, revision := range a.s.articleRevisions(articleID) {
+		if revision.ID < revisionID && (prev == nil || revision.ID > prev.ID) {
+			prev = revision
+		}
+	}
+	if prev == nil {
+		return nil, common.ErrNotFound
+	}
+	cp := *prev
+	return &cp, nil
+}
+
+func (a articleRepo) List(ctx context.Context, query repository.ListArticlesFilter) ([]*entity.Article, int64, error) {
+	a.s.mu.RLock()
+	defer a.s.mu.RUnlock()
+
+	var matched []*entity.Article
+	for _, article := range a.s.articles {
+		// --- tag 过滤 ---
+		if query.Tag != nil && !a.s.hasTag(article.ID, *query.Tag) {
+			continue
+		}
+
+		// --- author 过滤 ---
+		if query.Author != nil {
+			author, ok := a.s.users[article.AuthorID]
+			if !ok || !strings.EqualFold(author.Username, *query.Author) {
+				continue
+			}
+		}
+
+		// --- favorited 过滤 ---
+		if query.FavoritedBy != nil {
+			u := a.s.userByUsername(*query.FavoritedBy)
+			if u == nil {
+				continue
+			}
+			if _, ok := a.s.favorites[favoriteKey{UserID: u.ID, ArticleID: article.ID}]; !ok {
+				continue
+			}
+		}
+
+		// --- 停用账号 ---
+		if query.ExcludeSuspendedAuthors && a.s.authorSuspended(article) {
+			continue
+		}
+
+		// --- 状态 ---
+		if !statusMatches(article.Status, query.Statuses) {
+			continue
+		}
+
+		matched = append(matched, article)
+	}
+
+	return a.s.pageArticles(matched, query.Limit, query.Offset), int64(len(matched)), nil
+}
+
+func (a articleRepo) Feed(ctx context.Context, userID int64, query repository.FeedFilter) ([]*entity.Article, int64, error) {
+	a.s.mu.RLock()
+	defer a.s.mu.RUnlock()
+
+	var matched []*entity.Article
+	for _, article := range a.s.articles {
+		if _, ok := a.s.follows[followKey{FollowerID: userID, FollowingID: article.AuthorID}]; !ok {
+			continue
+		}
+		if article.Status != entity.ArticleStatusPublished {
+			continue
+		}
+		if query.ExcludeSuspendedAuthors && a.s.authorSuspended(article) {
+			continue
+		}
+		matched = append(matched, article)
+	}
+
+	return a.s.pageArticles(matched, query.Limit, query.Offset), int64(len(matched)), nil
+}
+
+func (a articleRepo) ListScheduled(ctx context.Context, authorID int64, limit, offset int) ([]*entity.Article, int64, error) {
+	a.s.mu.RLock()
+	defer a.s.mu.RUnlock()
+
+	var matched []*entity.Article
+	for _, article := range a.s.articles {
+		if article.AuthorID == authorID && article.Status == entity.ArticleStatusScheduled {
+			matched = append(matched, article)
+		}
+	}
+	sortByPublishAt(matched)
+
+	page := paginate(matched, limit, offset)
+	result := make([]*entity.Article, 0, len(page))
+	for _, article := range page {
+		cp := *article
+		result = append(result, &cp)
+	}
+	return result, int64(len(matched)), nil
+}
+
+func (a articleRepo) FindByOldSlug(ctx context.Context, slug string) (*entity.Article, error) {
+	a.s.mu.RLock()
+	defer a.s.mu.RUnlock()
+
+	old, ok := a.s.articleSlugs[slug]
+	if !ok {
+		return nil, common.ErrNotFound
+	}
+	article, ok := a.s.articles[old.ArticleID]
+	if !ok {
+		return nil, common.ErrNotFound
+	}
+	cp := *article
+	return &cp, nil
+}
+
+func (a articleRepo) SlugTaken(ctx context.Context, slug string, exceptArticleID int64) (bool, error) {
+	a.s.mu.RLock()
+	defer a.s.mu.RUnlock()
+
+	if article := a.s.articleBySlug(slug); article != nil && article.ID != exceptArticleID {
+		return true, nil
+	}
+	old, ok := a.s.articleSlugs[slug]
+	return ok && old.ArticleID != exceptArticleID, nil
+}
+
+func (a articleRepo) PublishDue(ctx context.Context, now time.Time, limit int) ([]*entity.Article, error) {
+	a.s.mu.Lock()
+	defer a.s.mu.Unlock()
+
+	var due []*entity.Article
+	for _, article := range a.s.articles {
+		if article.Status == entity.ArticleStatusScheduled && article.PublishAt != nil && !article.PublishAt.After(now) {
+			due = append(due, article)
+		}
+	}
+	sortByPublishAt(due)
+
+	published := make([]*entity.Article, 0, limit)
+	for _, article := range paginate(due, limit, 0) {
+		// 发布时间取计划时间，之前发布过的文章保留第一次的发布时间
+		if article.PublishedAt == nil {
+			article.PublishedAt = article.PublishAt
+		}
+		article.Status = entity.ArticleStatusPublished
+		article.PublishAt = nil
+		article.UpdatedAt = now
+		article.Version++
+		cp := *article
+		published = append(published, &cp)
+	}
+	return published, nil
+}
+
+func (a articleRepo) GetOrCreateTags(ctx context.Context, names []string) ([]*entity.Tag, error) {
+	a.s.mu.Lock()
+	defer a.s.mu.Unlock()
+
+	return a.s.getOrCreateTags(names), nil
+}
+
+func (a articleRepo) ReplaceArticleTags(ctx context.Context, articleID int64, tags []*entity.Tag) error {
+	a.s.mu.Lock()
+	defer a.s.mu.Unlock()
+
+	a.s.replaceArticleTags(articleID, tags)
+	return nil
+}
+
+func (a articleRepo) GetTagsByArticleID(ctx context.Context, articleID int64) ([]*entity.Tag, error) {
+	a.s.mu.RLock()
+	defer a.s.mu.RUnlock()
+
+	var tags []*entity.Tag
+	for _, tagID := range a.s.articleTags[articleID] {
+		if tag, ok := a.s.tags[tagID]; ok {
+			cp := *tag
+			tags = append(tags, &cp)
+		}
+	}
+	return tags, nil
+}
+
+func (a articleRepo) ListTags(ctx context.Context) ([]string, error) {
+	a.s.mu.RLock()
+	defer a.s.mu.RUnlock()
+
+	// 草稿上的标签不能泄露出去
+	names := make(map[string]struct{})
+	for _, article := range a.s.articles {
+		if article.Status != entity.ArticleStatusPublished {
+			continue
+		}
+		for _, tagID := range a.s.articleTags[article.ID] {
+			if tag, ok := a.s.tags[tagID]; ok {
+				names[tag.Name] = struct{}{}
+			}
+		}
+	}
+
+	tags := make([]string, 0, len(names))
+	for name := range names {
+		tags = append(tags, name)
+	}
+	sort.Strings(tags)
+	return tags, nil
+}
+
+func (a articleRepo) GetTagsByArticleIDs(ctx context.Context, articleIDs []int64) (map[int64][]string, error) {
+	a.s.mu.RLock()
+	defer a.s.mu.RUnlock()
+
+	result := make(map[int64][]string, len(articleIDs))
+	for _, articleID := range articleIDs {
+		for _, tagID := range a.s.articleTags[articleID] {
+			if tag, ok := a.s.tags[tagID]; ok {
+				result[articleID] = append(result[articleID], tag.Name)
+			}
+		}
+	}
+	return result, nil
+}
+
+func (a articleRepo) IsFavorited(ctx context.Context, userID, articleID int64) (bool, error) {
+	a.s.mu.RLock()
+	defer a.s.mu.RUnlock()
+
+	_, ok := a.s.favorites[favoriteKey{UserID: userID, ArticleID: articleID}]
+	return ok, nil
+}
+
+func (a articleRepo) AddFavorite(ctx context.Context, userID, articleID int64) error {
+	a.s.mu.Lock()
+	defer a.s.mu.Unlock()
+
+	key := favoriteKey{UserID: userID, ArticleID: articleID}
+	if _, ok := a.s.favorites[key]; ok {
+		// 已存在，不操作计数
+		return nil
+	}
+	a.s.favorites[key] = struct{}{}
+	if article, ok := a.s.articles[articleID]; ok {
+		article.FavoritesCount++
+	}
+	return nil
+}
+
+func (a articleRepo) RemoveFavorite(ctx context.Context, userID, articleID int64) error {
+	a.s.mu.Lock()
+	defer a.s.mu.Unlock()
+
+	key := favoriteKey{UserID: userID, ArticleID: articleID}
+	if _, ok := a.s.favorites[key]; !ok {
+		return nil
+	}
+	delete(a.s.favorites, key)
+	if article, ok := a.s.articles[articleID]; ok && article.FavoritesCount > 0 {
+		article.FavoritesCount--
+	}
+	return nil
+}
+
+func (a articleRepo) CountFavorites(ctx context.Context, articleID int64) (int, error) {
+	a.s.mu.RLock()
+	defer a.s.mu.RUnlock()
+
+	article, ok := a.s.articles[articleID]
+	if !ok {
+		return 0, common.ErrNotFound
+	}
+	return article.FavoritesCount, nil
+}
+
+// 下面是 Store 上的辅助查询，调用方需持有锁
+
+func (s *Store) articleBySlug(slug string) *entity.Article {
+	for _, article := range s.articles {
+		if article.Slug == slug {
+			return article
+		}
+	}
+	return nil
+}
+
+// createArticle 分配 id 并保存，调用方需持有写锁
+func (s *Store) createArticle(article *entity.Article) {
+	s.nextArticleID++
+	article.ID = s.nextArticleID
+	cp := *article
+	s.articles[cp.ID] = &cp
+}
+
+// updateArticle 模拟以版本为条件的 UPDATE，收藏数保留存储里的值；slug 变化时旧 slug 记入历史
+func (s *Store) updateArticle(article *entity.Article) error {
+	old, ok := s.articles[article.ID]
+	if !ok || old.Version != article.Version {
+		return common.ErrVersionMismatch
+	}
+	if other := s.articleBySlug(article.Slug); other != nil && other.ID != article.ID {
+		return common.ErrSlugTaken
+	}
+
+	if old.Slug != article.Slug {
+		if prev, ok := s.articleSlugs[article.Slug]; ok && prev.ArticleID == article.ID {
+			delete(s.articleSlugs, article.Slug)
+		}
+		s.nextArticleSlugID++
+		s.articleSlugs[old.Slug] = &entity.ArticleSlug{
+			ID:        s.nextArticleSlugID,
+			ArticleID: article.ID,
+			Slug:      old.Slug,
+			CreatedAt: article.UpdatedAt,
+		}
+	}
+
+	article.Version++
+	article.FavoritesCount = old.FavoritesCount
+	article.CreatedAt = old.CreatedAt
+	cp := *article
+	s.articles[cp.ID] = &cp
+	return nil
+}
+
+func (s *Store) tagByName(name string) *entity.Tag {
+	for _, tag := range s.tags {
+		if tag.Name == name {
+			return tag
+		}
+	}
+	return nil
+}
+
+// createRevision 分配 id 并保存修订，调用方需持有写锁
+func (s *Store) createRevision(revision *entity.ArticleRevision) {
+	s.nextRevisionID++
+	revision.ID = s.nextRevisionID
+	cp := *revision
+	s.revisions[cp.ID] = &cp
+}
+
+// articleRevisions 文章的全部修订，调用方需持有锁
+func (s *Store) articleRevisions(articleID int64) []*entity.ArticleRevision {
+	var revisions []*entity.ArticleRevision
+	for _, revision := range s.revisions {
+		if revision.ArticleID == articleID {
+			revisions = append(revisions, revision)
+		}
+	}
+	return revisions
+}
+
+// authorSuspended 文章作者是否被停用，调用方需持有锁
+func (s *Store) authorSuspended(article *entity.Article) bool {
+	author, ok := s.users[article.AuthorID]
+	return ok && author.SuspendedAt != nil
+}
+
+// getOrCreateTags 按名称查标签，不存在的创建，返回副本，调用方需持有写锁
+func (s *Store) getOrCreateTags(names []string) []*entity.Tag {
+	tags := make([]*entity.Tag, 0, len(names))
+	for _, name := range names {
+		tag := s.tagByName(name)
+		if tag == nil {
+			s.nextTagID++
+			tag = &entity.Tag{ID: s.nextTagID, Name: name}
+			s.tags[tag.ID] = tag
+		}
+		cp := *tag
+		tags = append(tags, &cp)
+	}
+	return tags
+}
+
+// replaceArticleTags 把文章的标签改成 tags，调用方需持有写锁
+func (s *Store) replaceArticleTags(articleID int64, tags []*entity.Tag) {
+	if len(tags) == 0 {
+		delete(s.articleTags, articleID)
+		return
+	}
+	tagIDs := make([]int64, 0, len(tags))
+	for _, tag := range tags {
+		tagIDs = append(tagIDs, tag.ID)
+	}
+	s.articleTags[articleID] = tagIDs
+}
+
+func (s *Store) hasTag(articleID int64, name string) bool {
+	for _, tagID := range s.articleTags[articleID] {
+		if tag, ok := s.tags[tagID]; ok && tag.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// statusMatches 文章状态是否在 statuses 中，statuses 为空时只匹配已发布
+func statusMatches(status string, statuses []string) bool {
+	if len(statuses) == 0 {
+		return status == entity.ArticleStatusPublished
+	}
+	for _, s := range statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// sortTime 排序用的时间：发布时间，草稿没有发布时间时用创建时间
+func sortTime(article *entity.Article) time.Time {
+	if article.PublishedAt != nil {
+		return *article.PublishedAt
+	}
+	return article.CreatedAt
+}
+
+// sortByPublishAt 按定时发布时间先后排序
+func sortByPublishAt(articles []*entity.Article) {
+	sort.Slice(articles, func(i, j int) bool {
+		ti, tj := *articles[i].PublishAt, *articles[j].PublishAt
+		if ti.Equal(tj) {
+			return articles[i].ID < articles[j].ID
+		}
+		return ti.Before(tj)
+	})
+}
+
+// pageArticles 按发布时间倒序排序后分页，返回副本
+func (s *Store) pageArticles(articles []*entity.Article, limit, offset int) []*entity.Article {
+	sort.Slice(articles, func(i, j int) bool {
+		ti, tj := sortTime(articles[i]), sortTime(articles[j])
+		if ti.Equal(tj) {
+			return articles[i].ID > articles[j].ID
+		}
+		return ti.After(tj)
+	})
+
+	page := paginate(articles, limit, offset)
+	result := make([]*entity.Article, 0, len(page))
+	for _, article := range page {
+		cp := *article
+		result = append(result, &cp)
+	}
+	return result
+}
@@ -0,0 +1,103 @@
+package memory
+
+import (
+	"context"
+	"github/CiroLong/realworld-gin/internal/model/entity"
+	"github/CiroLong/realworld-gin/internal/pkg/common"
+	"github/CiroLong/realworld-gin/internal/repository"
+	"sort"
+	"time"
+)
+
+type CommentRepo struct {
+	s *Store
+}
+
+func NewCommentRepo(s *Store) repository.CommentRepo {
+	return &CommentRepo{s: s}
+}
+
+func (c CommentRepo) Create(ctx context.Context, comment *entity.Comment) error {
+	c.s.mu.Lock()
+	defer c.s.mu.Unlock()
+
+	now := time.Now()
+	c.s.nextCommentID++
+	comment.ID = c.s.nextCommentID
+	comment.CreatedAt = now
+	comment.UpdatedAt = now
+
+	cp := *comment
+	c.s.comments[cp.ID] = &cp
+	return nil
+}
+
+func (c CommentRepo) ListByArticle(ctx context.Context, articleID int64) ([]*entity.Comment, error) {
+	c.s.mu.RLock()
+	defer c.s.mu.RUnlock()
+
+	comments := make([]*entity.Comment, 0)
+	for _, comment := range c.s.comments {
+		if comment.ArticleID == articleID {
+			cp := *comment
+			comments = append(comments, &cp)
+		}
+	}
+
+	// 按创建时间正序
+	sort.Slice(comments, func(i, j int) bool {
+		if comments[i].CreatedAt.Equal(comments[j].CreatedAt) {
+			return comments[i].ID < comments[j].ID
+		}
+		return comments[i].CreatedAt.Before(comments[j].CreatedAt)
+	})
+	return comments, nil
+}
+
+func (c CommentRepo) ListByAuthor(ctx context.Context, authorID int64, limit int, offset int) ([]*entity.Comment, int64, error) {
+	c.s.mu.RLock()
+	defer c.s.mu.RUnlock()
+
+	var comments []*entity.Comment
+	for _, comment := range c.s.comments {
+		if comment.AuthorID == authorID {
+			comments = append(comments, comment)
+		}
+	}
+
+	// 按创建时间正序
+	sort.Slice(comments, func(i, j int) bool {
+		if comments[i].CreatedAt.Equal(comments[j].CreatedAt) {
+			return comments[i].ID < comments[j].ID
+		}
+		return comments[i].CreatedAt.Before(comments[j].CreatedAt)
+	})
+
+	page := paginate(comments, limit, offset)
+	result := make([]*entity.Comment, 0, len(page))
+	for _, comment := range page {
+		cp := *comment
+		result = append(result, &cp)
+	}
+	return result, int64(len(comments)), nil
+}
+
+func (c CommentRepo) FindByID(ctx context.Context, id int64) (*entity.Comment, error) {
+	c.s.mu.RLock()
+	defer c.s.mu.RUnlock()
+
+	comment, ok := c.s.comments[id]
+	if !ok {
+		return nil, common.ErrNotFound
+	}
+	cp := *comment
+	return &cp, nil
+}
+
+func (c CommentRepo) Delete(ctx context.Context, id int64) error {
+	c.s.mu.Lock()
+	defer c.s.mu.Unlock()
+
+	delete(c.s.comments, id)
+	return nil
+}
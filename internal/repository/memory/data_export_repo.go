@@ -0,0 +1,92 @@
+package memory
+
+import (
+	"context"
+	"github/CiroLong/realworld-gin/internal/model/entity"
+	"github/CiroLong/realworld-gin/internal/pkg/common"
+	"github/CiroLong/realworld-gin/internal/repository"
+	"time"
+)
+
+type DataExportRepo struct {
+	s *Store
+}
+
+func NewDataExportRepo(s *Store) repository.DataExportRepo {
+	return &DataExportRepo{s: s}
+}
+
+func (r *DataExportRepo) Create(ctx context.Context, export *entity.DataExport) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	r.s.nextDataExportID++
+	export.ID = r.s.nextDataExportID
+	export.CreatedAt = time.Now()
+
+	cp := *export
+	r.s.dataExports[cp.ID] = &cp
+	return nil
+}
+
+func (r *DataExportRepo) FindByID(ctx context.Context, id int64) (*entity.DataExport, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	export, ok := r.s.dataExports[id]
+	if !ok {
+		return nil, common.ErrNotFound
+	}
+	cp := *export
+	return &cp, nil
+}
+
+func (r *DataExportRepo) FindLatestByUser(ctx context.Context, userID int64, now time.Time) (*entity.DataExport, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var latest *entity.DataExport
+	for _, export := range r.s.dataExports {
+		if export.UserID != userID || !export.ExpiresAt.After(now) {
+			continue
+		}
+		if latest == nil || export.ID > latest.ID {
+			latest = export
+		}
+	}
+	if latest == nil {
+		return nil, common.ErrNotFound
+	}
+	cp := *latest
+	cp.Data = nil
+	return &cp, nil
+}
+
+func (r *DataExportRepo) Finish(ctx context.Context, export *entity.DataExport) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	stored, ok := r.s.dataExports[export.ID]
+	if !ok {
+		return nil
+	}
+	stored.Status = export.Status
+	stored.Data = export.Data
+	stored.Error = export.Error
+	stored.CompletedAt = export.CompletedAt
+	return nil
+}
+
+func (r *DataExportRepo) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var n int64
+	for id, export := range r.s.dataExports {
+		if !export.ExpiresAt.After(before) {
+			delete(r.s.dataExports, id)
+			n++
+		}
+	}
+	return n, nil
+}
@@ -0,0 +1,119 @@
+package memory
+
+import (
+	"context"
+	"github/CiroLong/realworld-gin/internal/model/entity"
+	"github/CiroLong/realworld-gin/internal/pkg/common"
+	"github/CiroLong/realworld-gin/internal/repository"
+	"sort"
+	"time"
+)
+
+type IdentityRepo struct {
+	s *Store
+}
+
+func NewIdentityRepo(s *Store) repository.IdentityRepo {
+	return &IdentityRepo{s: s}
+}
+
+func (r *IdentityRepo) Create(ctx context.Context, identity *entity.UserIdentity) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, existing := range r.s.identities {
+		if existing.Provider != identity.Provider {
+			continue
+		}
+		if existing.Subject == identity.Subject || existing.UserID == identity.UserID {
+			return common.ErrIdentityAlreadyLinked
+		}
+	}
+
+	r.s.nextIdentityID++
+	identity.ID = r.s.nextIdentityID
+	identity.CreatedAt = time.Now()
+
+	cp := *identity
+	r.s.identities[cp.ID] = &cp
+	return nil
+}
+
+func (r *IdentityRepo) FindByProviderSubject(ctx context.Context, provider, subject string) (*entity.UserIdentity, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	for _, identity := range r.s.identities {
+		if identity.Provider == provider && identity.Subject == subject {
+			cp := *identity
+			return &cp, nil
+		}
+	}
+	return nil, common.ErrNotFound
+}
+
+func (r *IdentityRepo) ListByUser(ctx context.Context, userID int64) ([]*entity.UserIdentity, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var identities []*entity.UserIdentity
+	for _, identity := range r.s.identities {
+		if identity.UserID == userID {
+			cp := *identity
+			identities = append(identities, &cp)
+		}
+	}
+	sort.Slice(identities, func(i, j int) bool {
+		return identities[i].Provider < identities[j].Provider
+	})
+	return identities, nil
+}
+
+func (r *IdentityRepo) Delete(ctx context.Context, userID int64, provider string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for id, identity := range r.s.identities {
+		if identity.UserID == userID && identity.Provider == provider {
+			delete(r.s.identities, id)
+			return nil
+		}
+	}
+	return common.ErrNotFound
+}
+
+func (r *IdentityRepo) CreateState(ctx context.Context, state *entity.OIDCState) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	now := time.Now()
+	for hash, existing := range r.s.oidcStates {
+		if now.After(existing.ExpiresAt) {
+			delete(r.s.oidcStates, hash)
+		}
+	}
+
+	r.s.nextOIDCStateID++
+	state.ID = r.s.nextOIDCStateID
+	state.CreatedAt = now
+
+	cp := *state
+	r.s.oidcStates[cp.StateHash] = &cp
+	return nil
+}
+
+func (r *IdentityRepo) ConsumeState(ctx context.Context, stateHash string, now time.Time) (*entity.OIDCState, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	state, ok := r.s.oidcStates[stateHash]
+	if !ok {
+		return nil, common.ErrNotFound
+	}
+	delete(r.s.oidcStates, stateHash)
+	if now.After(state.ExpiresAt) {
+		return nil, common.ErrNotFound
+	}
+	cp := *state
+	return &cp, nil
+}
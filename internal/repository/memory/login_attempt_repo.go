@@ -0,0 +1,65 @@
+package memory
+
+import (
+	"context"
+	"github/CiroLong/realworld-gin/internal/model/entity"
+	"github/CiroLong/realworld-gin/internal/pkg/common"
+	"github/CiroLong/realworld-gin/internal/repository"
+	"time"
+)
+
+type LoginAttemptRepo struct {
+	s *Store
+}
+
+func NewLoginAttemptRepo(s *Store) repository.LoginAttemptRepo {
+	return &LoginAttemptRepo{s: s}
+}
+
+func (r *LoginAttemptRepo) Get(ctx context.Context, subject string) (*entity.LoginAttempt, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	attempt, ok := r.s.loginAttempts[subject]
+	if !ok {
+		return nil, common.ErrNotFound
+	}
+	cp := *attempt
+	return &cp, nil
+}
+
+func (r *LoginAttemptRepo) RecordFailure(ctx context.Context, subject string, at time.Time) (*entity.LoginAttempt, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	attempt, ok := r.s.loginAttempts[subject]
+	if !ok {
+		attempt = &entity.LoginAttempt{Subject: subject}
+		r.s.loginAttempts[subject] = attempt
+	}
+	attempt.Failures++
+	attempt.LastFailedAt = at
+	attempt.UpdatedAt = at
+
+	cp := *attempt
+	return &cp, nil
+}
+
+func (r *LoginAttemptRepo) Lock(ctx context.Context, subject string, until time.Time) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if attempt, ok := r.s.loginAttempts[subject]; ok {
+		attempt.LockedUntil = &until
+		attempt.UpdatedAt = time.Now()
+	}
+	return nil
+}
+
+func (r *LoginAttemptRepo) Reset(ctx context.Context, subject string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	delete(r.s.loginAttempts, subject)
+	return nil
+}
@@ -0,0 +1,133 @@
+package memory
+
+import (
+	"context"
+	"github/CiroLong/realworld-gin/internal/model/entity"
+	"github/CiroLong/realworld-gin/internal/pkg/common"
+	"github/CiroLong/realworld-gin/internal/repository"
+	"time"
+)
+
+type MFARepo struct {
+	s *Store
+}
+
+func NewMFARepo(s *Store) repository.MFARepo {
+	return &MFARepo{s: s}
+}
+
+func (r *MFARepo) FindByUserID(ctx context.Context, userID int64) (*entity.UserMFA, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	mfa, ok := r.s.mfa[userID]
+	if !ok {
+		return nil, common.ErrNotFound
+	}
+	cp := *mfa
+	return &cp, nil
+}
+
+func (r *MFARepo) Save(ctx context.Context, mfa *entity.UserMFA) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	now := time.Now()
+	if old, ok := r.s.mfa[mfa.UserID]; ok {
+		mfa.CreatedAt = old.CreatedAt
+	} else {
+		mfa.CreatedAt = now
+	}
+	mfa.UpdatedAt = now
+
+	cp := *mfa
+	r.s.mfa[cp.UserID] = &cp
+	return nil
+}
+
+func (r *MFARepo) Enable(ctx context.Context, userID int64, enabledAt time.Time) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if mfa, ok := r.s.mfa[userID]; ok {
+		mfa.EnabledAt = &enabledAt
+		mfa.UpdatedAt = time.Now()
+	}
+	return nil
+}
+
+func (r *MFARepo) Delete(ctx context.Context, userID int64) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	delete(r.s.mfa, userID)
+	r.s.deleteRecoveryCodes(userID)
+	return nil
+}
+
+func (r *MFARepo) AdvanceStep(ctx context.Context, userID int64, step int64) (bool, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	mfa, ok := r.s.mfa[userID]
+	if !ok || mfa.LastUsedStep >= step {
+		return false, nil
+	}
+	mfa.LastUsedStep = step
+	return true, nil
+}
+
+func (r *MFARepo) ReplaceRecoveryCodes(ctx context.Context, userID int64, codes []*entity.MFARecoveryCode) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	r.s.deleteRecoveryCodes(userID)
+
+	now := time.Now()
+	for _, code := range codes {
+		r.s.nextRecoveryCodeID++
+		code.ID = r.s.nextRecoveryCodeID
+		code.UserID = userID
+		code.CreatedAt = now
+
+		cp := *code
+		r.s.recoveryCodes[cp.ID] = &cp
+	}
+	return nil
+}
+
+func (r *MFARepo) UseRecoveryCode(ctx context.Context, userID int64, hash string) (bool, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, code := range r.s.recoveryCodes {
+		if code.UserID == userID && code.CodeHash == hash && code.UsedAt == nil {
+			now := time.Now()
+			code.UsedAt = &now
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *MFARepo) CountRecoveryCodes(ctx context.Context, userID int64) (int64, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var count int64
+	for _, code := range r.s.recoveryCodes {
+		if code.UserID == userID && code.UsedAt == nil {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// deleteRecoveryCodes 调用方需持有写锁
+func (s *Store) deleteRecoveryCodes(userID int64) {
+	for id, code := range s.recoveryCodes {
+		if code.UserID == userID {
+			delete(s.recoveryCodes, id)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package memory
+
+import (
+	"context"
+	"github/CiroLong/realworld-gin/internal/model/entity"
+	"github/CiroLong/realworld-gin/internal/pkg/common"
+	"github/CiroLong/realworld-gin/internal/repository"
+	"time"
+)
+
+type OneTimeTokenRepo struct {
+	s *Store
+}
+
+func NewOneTimeTokenRepo(s *Store) repository.OneTimeTokenRepo {
+	return &OneTimeTokenRepo{s: s}
+}
+
+func (r *OneTimeTokenRepo) Create(ctx context.Context, token *entity.OneTimeToken) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	r.s.nextOneTimeTokenID++
+	token.ID = r.s.nextOneTimeTokenID
+	token.CreatedAt = time.Now()
+
+	cp := *token
+	r.s.oneTimeTokens[cp.ID] = &cp
+	return nil
+}
+
+func (r *OneTimeTokenRepo) FindByHash(ctx context.Context, purpose string, hash string) (*entity.OneTimeToken, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	for _, token := range r.s.oneTimeTokens {
+		if token.TokenHash == hash && token.Purpose == purpose {
+			cp := *token
+			return &cp, nil
+		}
+	}
+	return nil, common.ErrNotFound
+}
+
+func (r *OneTimeTokenRepo) Consume(ctx context.Context, id int64) (bool, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	token, ok := r.s.oneTimeTokens[id]
+	if !ok || token.UsedAt != nil {
+		return false, nil
+	}
+	now := time.Now()
+	token.UsedAt = &now
+	return true, nil
+}
+
+func (r *OneTimeTokenRepo) InvalidateUserTokens(ctx context.Context, userID int64, purpose string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	now := time.Now()
+	for _, token := range r.s.oneTimeTokens {
+		if token.UserID == userID && token.Purpose == purpose && token.UsedAt == nil {
+			token.UsedAt = &now
+		}
+	}
+	return nil
+}
+
+func (r *OneTimeTokenRepo) CountIssuedSince(ctx context.Context, userID int64, purpose string, since time.Time) (int64, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var count int64
+	for _, token := range r.s.oneTimeTokens {
+		if token.UserID == userID && token.Purpose == purpose && !token.CreatedAt.Before(since) {
+			count++
+		}
+	}
+	return count, nil
+}
@@ -0,0 +1,97 @@
+package memory
+
+import (
+	"context"
+	"github/CiroLong/realworld-gin/internal/model/entity"
+	"github/CiroLong/realworld-gin/internal/pkg/common"
+	"github/CiroLong/realworld-gin/internal/repository"
+	"sort"
+	"time"
+)
+
+type PersonalAccessTokenRepo struct {
+	s *Store
+}
+
+func NewPersonalAccessTokenRepo(s *Store) repository.PersonalAccessTokenRepo {
+	return &PersonalAccessTokenRepo{s: s}
+}
+
+func (r *PersonalAccessTokenRepo) Create(ctx context.Context, token *entity.PersonalAccessToken) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	r.s.nextPersonalAccessTokenID++
+	token.ID = r.s.nextPersonalAccessTokenID
+	token.CreatedAt = time.Now()
+
+	cp := *token
+	r.s.personalAccessTokens[cp.ID] = &cp
+	return nil
+}
+
+func (r *PersonalAccessTokenRepo) FindByHash(ctx context.Context, hash string) (*entity.PersonalAccessToken, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	for _, token := range r.s.personalAccessTokens {
+		if token.TokenHash == hash {
+			cp := *token
+			return &cp, nil
+		}
+	}
+	return nil, common.ErrNotFound
+}
+
+func (r *PersonalAccessTokenRepo) ListByUser(ctx context.Context, userID int64) ([]*entity.PersonalAccessToken, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var tokens []*entity.PersonalAccessToken
+	for _, token := range r.s.personalAccessTokens {
+		if token.UserID == userID && token.RevokedAt == nil {
+			cp := *token
+			tokens = append(tokens, &cp)
+		}
+	}
+	sort.Slice(tokens, func(i, j int) bool {
+		return tokens[i].ID > tokens[j].ID
+	})
+	return tokens, nil
+}
+
+func (r *PersonalAccessTokenRepo) Revoke(ctx context.Context, userID int64, id int64) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	token, ok := r.s.personalAccessTokens[id]
+	if !ok || token.UserID != userID || token.RevokedAt != nil {
+		return common.ErrNotFound
+	}
+	now := time.Now()
+	token.RevokedAt = &now
+	return nil
+}
+
+func (r *PersonalAccessTokenRepo) RevokeUserTokens(ctx context.Context, userID int64) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	now := time.Now()
+	for _, token := range r.s.personalAccessTokens {
+		if token.UserID == userID && token.RevokedAt == nil {
+			token.RevokedAt = &now
+		}
+	}
+	return nil
+}
+
+func (r *PersonalAccessTokenRepo) TouchLastUsed(ctx context.Context, id int64, at time.Time) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if token, ok := r.s.personalAccessTokens[id]; ok {
+		token.LastUsedAt = &at
+	}
+	return nil
+}
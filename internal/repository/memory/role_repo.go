@@ -0,0 +1,65 @@
+package memory
+
+import (
+	"context"
+	"github/CiroLong/realworld-gin/internal/model/entity"
+	"github/CiroLong/realworld-gin/internal/pkg/common"
+	"github/CiroLong/realworld-gin/internal/repository"
+	"sort"
+	"time"
+)
+
+type RoleRepo struct {
+	s *Store
+}
+
+func NewRoleRepo(s *Store) repository.RoleRepo {
+	return &RoleRepo{s: s}
+}
+
+func (r *RoleRepo) List(ctx context.Context) ([]*entity.Role, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	roles := make([]*entity.Role, 0, len(r.s.roles))
+	for _, role := range r.s.roles {
+		cp := *role
+		roles = append(roles, &cp)
+	}
+	sort.Slice(roles, func(i, j int) bool {
+		return roles[i].ID < roles[j].ID
+	})
+	return roles, nil
+}
+
+func (r *RoleRepo) FindByName(ctx context.Context, name string) (*entity.Role, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	role, ok := r.s.roles[name]
+	if !ok {
+		return nil, common.ErrNotFound
+	}
+	cp := *role
+	return &cp, nil
+}
+
+func (r *RoleRepo) Save(ctx context.Context, role *entity.Role) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	now := time.Now()
+	if old, ok := r.s.roles[role.Name]; ok {
+		role.ID = old.ID
+		role.CreatedAt = old.CreatedAt
+	} else {
+		r.s.nextRoleID++
+		role.ID = r.s.nextRoleID
+		role.CreatedAt = now
+	}
+	role.UpdatedAt = now
+
+	cp := *role
+	r.s.roles[cp.Name] = &cp
+	return nil
+}
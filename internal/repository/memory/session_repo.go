@@ -0,0 +1,139 @@
+package memory
+
+import (
+	"context"
+	"github/CiroLong/realworld-gin/internal/model/entity"
+	"github/CiroLong/realworld-gin/internal/pkg/common"
+	"github/CiroLong/realworld-gin/internal/repository"
+	"sort"
+	"time"
+)
+
+type SessionRepo struct {
+	s *Store
+}
+
+func NewSessionRepo(s *Store) repository.SessionRepo {
+	return &SessionRepo{s: s}
+}
+
+func (r *SessionRepo) CreateSession(ctx context.Context, session *entity.Session) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	r.s.nextSessionID++
+	session.ID = r.s.nextSessionID
+	session.CreatedAt = time.Now()
+	if session.LastSeenAt.IsZero() {
+		session.LastSeenAt = session.CreatedAt
+	}
+
+	cp := *session
+	r.s.sessions[cp.ID] = &cp
+	return nil
+}
+
+func (r *SessionRepo) FindSessionByID(ctx context.Context, id int64) (*entity.Session, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	session, ok := r.s.sessions[id]
+	if !ok {
+		return nil, common.ErrNotFound
+	}
+	cp := *session
+	return &cp, nil
+}
+
+func (r *SessionRepo) RevokeSession(ctx context.Context, id int64) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if session, ok := r.s.sessions[id]; ok && session.RevokedAt == nil {
+		now := time.Now()
+		session.RevokedAt = &now
+	}
+	return nil
+}
+
+func (r *SessionRepo) RevokeUserSessions(ctx context.Context, userID int64) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	now := time.Now()
+	for _, session := range r.s.sessions {
+		if session.UserID == userID && session.RevokedAt == nil {
+			session.RevokedAt = &now
+		}
+	}
+	return nil
+}
+
+func (r *SessionRepo) ListActiveSessions(ctx context.Context, userID int64, since time.Time) ([]*entity.Session, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var out []*entity.Session
+	for _, session := range r.s.sessions {
+		if session.UserID == userID && session.RevokedAt == nil && !session.LastSeenAt.Before(since) {
+			cp := *session
+			out = append(out, &cp)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].LastSeenAt.Equal(out[j].LastSeenAt) {
+			return out[i].LastSeenAt.After(out[j].LastSeenAt)
+		}
+		return out[i].ID > out[j].ID
+	})
+	return out, nil
+}
+
+func (r *SessionRepo) TouchSession(ctx context.Context, id int64, at time.Time) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if session, ok := r.s.sessions[id]; ok {
+		session.LastSeenAt = at
+	}
+	return nil
+}
+
+func (r *SessionRepo) CreateRefreshToken(ctx context.Context, token *entity.RefreshToken) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	r.s.nextRefreshTokenID++
+	token.ID = r.s.nextRefreshTokenID
+	token.CreatedAt = time.Now()
+
+	cp := *token
+	r.s.refreshTokens[cp.ID] = &cp
+	return nil
+}
+
+func (r *SessionRepo) FindRefreshTokenByHash(ctx context.Context, hash string) (*entity.RefreshToken, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	for _, token := range r.s.refreshTokens {
+		if token.TokenHash == hash {
+			cp := *token
+			return &cp, nil
+		}
+	}
+	return nil, common.ErrNotFound
+}
+
+func (r *SessionRepo) MarkRefreshTokenUsed(ctx context.Context, id int64) (bool, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	token, ok := r.s.refreshTokens[id]
+	if !ok || token.UsedAt != nil {
+		return false, nil
+	}
+	now := time.Now()
+	token.UsedAt = &now
+	return true, nil
+}
@@ -0,0 +1,137 @@
+package memory
+
+// memory 包是 repository 接口的纯内存实现
+// 不依赖任何数据库，用于演示环境和快速测试；进程退出后数据即丢失
+//
+// 所有 repo 共享同一个 Store，一把读写锁保护全部"表"，
+// 这样 List / Feed 这类跨表查询也能拿到一致的快照
+
+import (
+	"github/CiroLong/realworld-gin/internal/model/entity"
+	"sync"
+)
+
+// Driver 是 database.driver 配置中选择内存实现的取值
+const Driver = "memory"
+
+type favoriteKey struct {
+	UserID    int64
+	ArticleID int64
+}
+
+type followKey struct {
+	FollowerID  int64
+	FollowingID int64
+}
+
+// Store 保存所有数据，相当于一个内存数据库
+type Store struct {
+	mu sync.RWMutex
+
+	users    map[int64]*entity.User
+	articles map[int64]*entity.Article
+	tags     map[int64]*entity.Tag
+	comments map[int64]*entity.Comment
+
+	sessions      map[int64]*entity.Session
+	refreshTokens map[int64]*entity.RefreshToken
+	oneTimeTokens map[int64]*entity.OneTimeToken
+	mfa           map[int64]*entity.UserMFA // userID -> mfa
+	recoveryCodes map[int64]*entity.MFARecoveryCode
+	loginAttempts map[string]*entity.LoginAttempt // subject -> attempt
+
+	personalAccessTokens map[int64]*entity.PersonalAccessToken
+
+	identities map[int64]*entity.UserIdentity
+	oidcStates map[string]*entity.OIDCState // state_hash -> state
+
+	roles map[string]*entity.Role // name -> role
+
+	dataExports map[int64]*entity.DataExport
+
+	revisions    map[int64]*entity.ArticleRevision
+	articleSlugs map[string]*entity.ArticleSlug // slug -> 旧 slug 记录
+
+	// 关系表
+	articleTags map[int64][]int64 // articleID -> tagIDs（保持插入顺序）
+	favorites   map[favoriteKey]struct{}
+	follows     map[followKey]*entity.Follow
+
+	// 自增主键
+	nextUserID    int64
+	nextArticleID int64
+	nextTagID     int64
+	nextCommentID int64
+
+	nextSessionID      int64
+	nextRefreshTokenID int64
+	nextOneTimeTokenID int64
+	nextRecoveryCodeID int64
+
+	nextPersonalAccessTokenID int64
+
+	nextIdentityID  int64
+	nextOIDCStateID int64
+
+	nextRoleID int64
+
+	nextDataExportID int64
+
+	nextRevisionID    int64
+	nextArticleSlugID int64
+}
+
+func NewStore() *Store {
+	return &Store{
+		users:       make(map[int64]*entity.User),
+		articles:    make(map[int64]*entity.Article),
+		tags:        make(map[int64]*entity.Tag),
+		comments:    make(map[int64]*entity.Comment),
+		sessions:    make(map[int64]*entity.Session),
+		articleTags: make(map[int64][]int64),
+		favorites:   make(map[favoriteKey]struct{}),
+		follows:     make(map[followKey]*entity.Follow),
+
+		refreshTokens: make(map[int64]*entity.RefreshToken),
+		oneTimeTokens: make(map[int64]*entity.OneTimeToken),
+		mfa:           make(map[int64]*entity.UserMFA),
+		recoveryCodes: make(map[int64]*entity.MFARecoveryCode),
+		loginAttempts: make(map[string]*entity.LoginAttempt),
+
+		personalAccessTokens: make(map[int64]*entity.PersonalAccessToken),
+
+		identities: make(map[int64]*entity.UserIdentity),
+		oidcStates: make(map[string]*entity.OIDCState),
+
+		roles: make(map[string]*entity.Role),
+
+		dataExports: make(map[int64]*entity.DataExport),
+		revisions:   make(map[int64]*entity.ArticleRevision),
+
+		articleSlugs: make(map[string]*entity.ArticleSlug),
+	}
+}
+
+// paginate 对已排序的结果做 limit / offset，limit <= 0 表示不限制
+func paginate[T any](items []T, limit, offset int) []T {
+	if offset < 0 {
+		offset = 0
+	}
+	if offset >= len(items) {
+		return []T{}
+	}
+	items = items[offset:]
+	if limit > 0 && limit < len(items) {
+		items = items[:limit]
+	}
+	return items
+}
+
+// deleteWhere 删除 map 中满足条件的记录，调用方需持有写锁
+func deleteWhere[K comparable, V any](m map[K]V, match func(V) bool) {
+	for k, v := range m {
+		if match(v) {
+			delete(m, k)
+		}
+	}
+}
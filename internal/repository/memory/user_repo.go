@@ -0,0 +1,354 @@
+package memory
+
+import (
+	"context"
+	"github/CiroLong/realworld-gin/internal/model/entity"
+	"github/CiroLong/realworld-gin/internal/pkg/auth"
+	"github/CiroLong/realworld-gin/internal/pkg/common"
+	"github/CiroLong/realworld-gin/internal/repository"
+	"sort"
+	"strings"
+	"time"
+)
+
+type UserRepo struct {
+	s *Store
+}
+
+func NewUserRepo(s *Store) repository.UserRepo {
+	return &UserRepo{s: s}
+}
+
+func (r *UserRepo) Create(ctx context.Context, user *entity.User) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	// 模拟 email / username 唯一索引
+	if r.s.userByEmail(user.Email) != nil || r.s.userByUsername(user.Username) != nil {
+		return common.ErrUserAlreadyExist
+	}
+
+	// 模拟 role 列的默认值
+	if user.Role == "" {
+		user.Role = auth.RoleUser
+	}
+
+	now := time.Now()
+	r.s.nextUserID++
+	user.ID = r.s.nextUserID
+	user.CreatedAt = now
+	user.UpdatedAt = now
+	user.Version = 1
+
+	u := *user
+	r.s.users[u.ID] = &u
+	return nil
+}
+
+func (r *UserRepo) FindByEmail(ctx context.Context, email string) (*entity.User, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	u := r.s.userByEmail(email)
+	if u == nil {
+		return nil, common.ErrUserNotFound
+	}
+	cp := *u
+	return &cp, nil
+}
+
+func (r *UserRepo) FindByUsername(ctx context.Context, username string) (*entity.User, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	u := r.s.userByUsername(username)
+	if u == nil {
+		return nil, common.ErrUserNotFound
+	}
+	cp := *u
+	return &cp, nil
+}
+
+func (r *UserRepo) FindByID(ctx context.Context, id int64) (*entity.User, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	u, ok := r.s.users[id]
+	if !ok {
+		return nil, common.ErrUserNotFound
+	}
+	cp := *u
+	return &cp, nil
+}
+
+func (r *UserRepo) Update(ctx context.Context, user *entity.User) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	old, ok := r.s.users[user.ID]
+	if !ok {
+		return common.ErrUserNotFound
+	}
+	if old.Version != user.Version {
+		return common.ErrVersionMismatch
+	}
+
+	// 改 email / username 时同样要满足唯一约束
+	if u := r.s.userByEmail(user.Email); u != nil && u.ID != user.ID {
+		return common.ErrUserAlreadyExist
+	}
+	if u := r.s.userByUsername(user.Username); u != nil && u.ID != user.ID {
+		return common.ErrUserAlreadyExist
+	}
+
+	user.CreatedAt = old.CreatedAt
+	user.UpdatedAt = time.Now()
+	user.Version++
+
+	u := *user
+	r.s.users[u.ID] = &u
+	return nil
+}
+
+func (r *UserRepo) SetEmailVerified(ctx context.Context, id int64, verifiedAt *time.Time) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	u, ok := r.s.users[id]
+	if !ok {
+		return common.ErrUserNotFound
+	}
+	u.EmailVerifiedAt = verifiedAt
+	return nil
+}
+
+func (r *UserRepo) RehashPassword(ctx context.Context, id int64, oldHash string, newHash string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	u, ok := r.s.users[id]
+	if !ok || u.Password != oldHash {
+		return common.ErrVersionMismatch
+	}
+	u.Password = newHash
+	return nil
+}
+
+func (r *UserRepo) IsFollowing(ctx context.Context, followerID int64, followingID int64) (bool, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	_, ok := r.s.follows[followKey{FollowerID: followerID, FollowingID: followingID}]
+	return ok, nil
+}
+
+func (r *UserRepo) Follow(ctx context.Context, followerID int64, followingID int64) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	key := followKey{FollowerID: followerID, FollowingID: followingID}
+	if _, ok := r.s.follows[key]; ok {
+		// 幂等
+		return nil
+	}
+	r.s.follows[key] = &entity.Follow{
+		FollowerID:  followerID,
+		FollowingID: followingID,
+		CreatedAt:   time.Now(),
+	}
+	return nil
+}
+
+func (r *UserRepo) UnFollow(ctx context.Context, followerID int64, followingID int64) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	delete(r.s.follows, followKey{FollowerID: followerID, FollowingID: followingID})
+	return nil
+}
+
+func (r *UserRepo) SetSuspended(ctx context.Context, id int64, suspendedAt *time.Time, reason string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	u, ok := r.s.users[id]
+	if !ok {
+		return common.ErrUserNotFound
+	}
+	u.SuspendedAt = suspendedAt
+	u.SuspendReason = reason
+	return nil
+}
+
+func (r *UserRepo) List(ctx context.Context, filter repository.ListUsersFilter) ([]*entity.User, int64, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	query := strings.ToLower(filter.Query)
+	var matched []*entity.User
+	for _, u := range r.s.users {
+		// --- 邮箱 / 用户名模糊匹配 ---
+		if query != "" && !strings.Contains(strings.ToLower(u.Email), query) && !strings.Contains(strings.ToLower(u.Username), query) {
+			continue
+		}
+		// --- 停用状态 ---
+		if filter.Suspended != nil && *filter.Suspended != (u.SuspendedAt != nil) {
+			continue
+		}
+		cp := *u
+		matched = append(matched, &cp)
+	}
+
+	sort.Slice(matched, func(i, j int) bool {
+		return matched[i].ID > matched[j].ID
+	})
+	return paginate(matched, filter.Limit, filter.Offset), int64(len(matched)), nil
+}
+
+// userByEmail 大小写不敏感，与 gorm 实现的查询语义保持一致；调用方需持有锁
+func (s *Store) userByEmail(email string) *entity.User {
+	for _, u := range s.users {
+		if strings.EqualFold(u.Email, email) {
+			return u
+		}
+	}
+	return nil
+}
+
+// userByUsername 大小写不敏感；调用方需持有锁
+func (s *Store) userByUsername(username string) *entity.User {
+	for _, u := range s.users {
+		if strings.EqualFold(u.Username, username) {
+			return u
+		}
+	}
+	return nil
+}
+
+func (r *UserRepo) ListFollowing(ctx context.Context, userID int64) ([]*entity.User, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	return r.s.followUsers(func(f *entity.Follow) (int64, bool) {
+		return f.FollowingID, f.FollowerID == userID
+	}), nil
+}
+
+func (r *UserRepo) ListFollowers(ctx context.Context, userID int64) ([]*entity.User, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	return r.s.followUsers(func(f *entity.Follow) (int64, bool) {
+		return f.FollowerID, f.FollowingID == userID
+	}), nil
+}
+
+func (r *UserRepo) Delete(ctx context.Context, id int64, reassignTo int64) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.users[id]; !ok {
+		return common.ErrUserNotFound
+	}
+
+	// 1. 收藏：计数减回去
+	for key := range r.s.favorites {
+		if key.UserID != id {
+			continue
+		}
+		if article, ok := r.s.articles[key.ArticleID]; ok && article.FavoritesCount > 0 {
+			article.FavoritesCount--
+		}
+		delete(r.s.favorites, key)
+	}
+
+	// 2. 关注和被关注
+	for key := range r.s.follows {
+		if key.FollowerID == id || key.FollowingID == id {
+			delete(r.s.follows, key)
+		}
+	}
+
+	// 3. 文章和评论
+	if reassignTo != 0 {
+		for _, article := range r.s.articles {
+			if article.AuthorID == id {
+				article.AuthorID = reassignTo
+			}
+		}
+		for _, comment := range r.s.comments {
+			if comment.AuthorID == id {
+				comment.AuthorID = reassignTo
+			}
+		}
+		for _, revision := range r.s.revisions {
+			if revision.EditorID == id {
+				revision.EditorID = reassignTo
+			}
+		}
+	} else {
+		for articleID, article := range r.s.articles {
+			if article.AuthorID != id {
+				continue
+			}
+			for key := range r.s.favorites {
+				if key.ArticleID == articleID {
+					delete(r.s.favorites, key)
+				}
+			}
+			for commentID, comment := range r.s.comments {
+				if comment.ArticleID == articleID {
+					delete(r.s.comments, commentID)
+				}
+			}
+			delete(r.s.articleTags, articleID)
+			deleteWhere(r.s.revisions, func(rev *entity.ArticleRevision) bool { return rev.ArticleID == articleID })
+			deleteWhere(r.s.articleSlugs, func(old *entity.ArticleSlug) bool { return old.ArticleID == articleID })
+			delete(r.s.articles, articleID)
+		}
+		for commentID, comment := range r.s.comments {
+			if comment.AuthorID == id {
+				delete(r.s.comments, commentID)
+			}
+		}
+		deleteWhere(r.s.revisions, func(rev *entity.ArticleRevision) bool { return rev.EditorID == id })
+	}
+
+	// 4. 登录凭证和账号相关的其他数据
+	deleteWhere(r.s.refreshTokens, func(t *entity.RefreshToken) bool { return t.UserID == id })
+	deleteWhere(r.s.sessions, func(s *entity.Session) bool { return s.UserID == id })
+	deleteWhere(r.s.personalAccessTokens, func(t *entity.PersonalAccessToken) bool { return t.UserID == id })
+	deleteWhere(r.s.identities, func(i *entity.UserIdentity) bool { return i.UserID == id })
+	deleteWhere(r.s.oidcStates, func(st *entity.OIDCState) bool { return st.UserID == id })
+	deleteWhere(r.s.recoveryCodes, func(c *entity.MFARecoveryCode) bool { return c.UserID == id })
+	deleteWhere(r.s.oneTimeTokens, func(t *entity.OneTimeToken) bool { return t.UserID == id })
+	deleteWhere(r.s.dataExports, func(e *entity.DataExport) bool { return e.UserID == id })
+	delete(r.s.mfa, id)
+
+	// 5. 用户本身
+	delete(r.s.users, id)
+	return nil
+}
+
+// followUsers 按关注时间正序列出关系另一端的用户，match 返回另一端的 id 以及这条关系是否需要；调用方需持有锁
+func (s *Store) followUsers(match func(f *entity.Follow) (int64, bool)) []*entity.User {
+	var follows []*entity.Follow
+	for _, f := range s.follows {
+		if _, ok := match(f); ok {
+			follows = append(follows, f)
+		}
+	}
+	sort.Slice(follows, func(i, j int) bool {
+		return follows[i].CreatedAt.Before(follows[j].CreatedAt)
+	})
+
+	users := make([]*entity.User, 0, len(follows))
+	for _, f := range follows {
+		id, _ := match(f)
+		if u, ok := s.users[id]; ok {
+			cp := *u
+			users = append(users, &cp)
+		}
+	}
+	return users
+}
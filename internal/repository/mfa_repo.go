@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"context"
+	"github/CiroLong/realworld-gin/internal/model/entity"
+	"time"
+)
+
+type MFARepo interface {
+	// FindByUserID 查询用户的两步验证配置，未绑定时返回 common.ErrNotFound
+	FindByUserID(ctx context.Context, userID int64) (*entity.UserMFA, error)
+
+	// Save 新建或覆盖用户的两步验证配置（重新绑定时覆盖未生效的密钥）
+	Save(ctx context.Context, mfa *entity.UserMFA) error
+
+	// Enable 启用两步验证
+	Enable(ctx context.Context, userID int64, enabledAt time.Time) error
+
+	// Delete 删除两步验证配置和全部恢复码
+	Delete(ctx context.Context, userID int64) error
+
+	// AdvanceStep 记录验证通过的时间步，step 不大于已记录的值时返回 false（验证码被重放）
+	AdvanceStep(ctx context.Context, userID int64, step int64) (bool, error)
+
+	// ---- 恢复码 ----
+
+	// ReplaceRecoveryCodes 删除旧的恢复码并保存新的
+	ReplaceRecoveryCodes(ctx context.Context, userID int64, codes []*entity.MFARecoveryCode) error
+
+	// UseRecoveryCode 核销一个未使用的恢复码，不存在或已使用时返回 false
+	UseRecoveryCode(ctx context.Context, userID int64, hash string) (bool, error)
+
+	// CountRecoveryCodes 剩余可用的恢复码数量
+	CountRecoveryCodes(ctx context.Context, userID int64) (int64, error)
+}
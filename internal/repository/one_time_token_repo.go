@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"context"
+	"github/CiroLong/realworld-gin/internal/model/entity"
+	"time"
+)
+
+type OneTimeTokenRepo interface {
+	// Create 保存令牌（只存摘要）
+	Create(ctx context.Context, token *entity.OneTimeToken) error
+
+	// FindByHash 根据用途和摘要查询令牌
+	FindByHash(ctx context.Context, purpose string, hash string) (*entity.OneTimeToken, error)
+
+	// Consume 标记令牌已使用，返回 false 表示已被使用过（并发下只有一个请求能成功）
+	Consume(ctx context.Context, id int64) (bool, error)
+
+	// InvalidateUserTokens 作废用户某个用途下所有未使用的令牌
+	InvalidateUserTokens(ctx context.Context, userID int64, purpose string) error
+
+	// CountIssuedSince 用户某个用途下 since 之后签发的令牌数（含已使用 / 已作废的），用于限流
+	CountIssuedSince(ctx context.Context, userID int64, purpose string, since time.Time) (int64, error)
+}
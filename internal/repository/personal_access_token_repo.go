@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"context"
+	"github/CiroLong/realworld-gin/internal/model/entity"
+	"time"
+)
+
+type PersonalAccessTokenRepo interface {
+	// Create 保存令牌（只存摘要）
+	Create(ctx context.Context, token *entity.PersonalAccessToken) error
+
+	// FindByHash 根据摘要查询令牌
+	FindByHash(ctx context.Context, hash string) (*entity.PersonalAccessToken, error)
+
+	// ListByUser 用户未撤销的令牌，按创建时间倒序
+	ListByUser(ctx context.Context, userID int64) ([]*entity.PersonalAccessToken, error)
+
+	// Revoke 撤销用户自己的令牌，不存在或已撤销时返回 common.ErrNotFound
+	Revoke(ctx context.Context, userID int64, id int64) error
+
+	// RevokeUserTokens 撤销用户的所有令牌（重置密码等场景）
+	RevokeUserTokens(ctx context.Context, userID int64) error
+
+	// TouchLastUsed 记录最近使用时间
+	TouchLastUsed(ctx context.Context, id int64, at time.Time) error
+}
@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+	"github/CiroLong/realworld-gin/internal/model/entity"
+)
+
+type RoleRepo interface {
+	// List 所有角色，按 id 排序
+	List(ctx context.Context) ([]*entity.Role, error)
+
+	// FindByName 根据角色名查询，不存在时返回 common.ErrNotFound
+	FindByName(ctx context.Context, name string) (*entity.Role, error)
+
+	// Save 按角色名新增或覆盖描述和权限
+	Save(ctx context.Context, role *entity.Role) error
+}
@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"context"
+	"github/CiroLong/realworld-gin/internal/model/entity"
+	"time"
+)
+
+type SessionRepo interface {
+	// ---- Session 相关 ----
+
+	// CreateSession 创建会话
+	CreateSession(ctx context.Context, session *entity.Session) error
+	// FindSessionByID 根据 id 查询会话
+	FindSessionByID(ctx context.Context, id int64) (*entity.Session, error)
+	// RevokeSession 撤销会话（幂等），会话下的 refresh token 随之失效
+	RevokeSession(ctx context.Context, id int64) error
+	// RevokeUserSessions 撤销用户的所有会话（重置密码等场景）
+	RevokeUserSessions(ctx context.Context, userID int64) error
+	// ListActiveSessions 用户未撤销、且 since 之后还活跃过的会话，最近活跃的在前
+	ListActiveSessions(ctx context.Context, userID int64, since time.Time) ([]*entity.Session, error)
+	// TouchSession 更新会话的最近活跃时间
+	TouchSession(ctx context.Context, id int64, at time.Time) error
+
+	// ---- RefreshToken 相关 ----
+
+	// CreateRefreshToken 保存 refresh token（只存摘要）
+	CreateRefreshToken(ctx context.Context, token *entity.RefreshToken) error
+	// FindRefreshTokenByHash 根据摘要查询 refresh token
+	FindRefreshTokenByHash(ctx context.Context, hash string) (*entity.RefreshToken, error)
+	// MarkRefreshTokenUsed 标记 token 已轮换，返回 false 表示已被别人抢先使用过
+	MarkRefreshTokenUsed(ctx context.Context, id int64) (bool, error)
+}
@@ -3,8 +3,19 @@ package repository
 import (
 	"context"
 	"github/CiroLong/realworld-gin/internal/model/entity"
+	"time"
 )
 
+// ListUsersFilter 管理后台的用户列表
+type ListUsersFilter struct {
+	// Query 按邮箱或用户名模糊匹配（大小写不敏感）
+	Query     string
+	Suspended *bool
+
+	Limit  int
+	Offset int
+}
+
 // interface 接口与实现解耦
 // 换 DB, 写 mock, 单测 Service
 type UserRepo interface {
@@ -20,10 +31,31 @@ type UserRepo interface {
 	// FindByID 根据 id 查找用户
 	FindByID(ctx context.Context, id int64) (*entity.User, error)
 
-	// Update 更新用户信息（部分字段）
+	// Update 更新用户信息（部分字段），以 user.Version 为条件，成功后版本加一；期间被别人改过返回 ErrVersionMismatch
 	Update(ctx context.Context, user *entity.User) error
 
+	// SetEmailVerified 设置邮箱验证时间，传 nil 表示重新变为未验证
+	SetEmailVerified(ctx context.Context, id int64, verifiedAt *time.Time) error
+
+	// RehashPassword 把密码哈希从 oldHash 换成 newHash（同一个密码升级算法 / 参数），不改 version 和 updated_at；
+	// 期间密码已被改过返回 ErrVersionMismatch，不会覆盖新密码
+	RehashPassword(ctx context.Context, id int64, oldHash string, newHash string) error
+
+	// SetSuspended 停用账号，传 nil 表示恢复
+	SetSuspended(ctx context.Context, id int64, suspendedAt *time.Time, reason string) error
+
+	// List 按条件分页列出用户，按 id 倒序（新注册的在前），同时返回总数
+	List(ctx context.Context, filter ListUsersFilter) ([]*entity.User, int64, error)
+
+	// Delete 删除用户及其登录凭证、关注和收藏（被收藏文章的 favorites_count 同步减一），在一个事务里完成
+	// reassignTo 不为 0 时文章和评论转给该用户，否则连同文章下的评论、收藏一起删除
+	Delete(ctx context.Context, id int64, reassignTo int64) error
+
 	IsFollowing(ctx context.Context, followerID int64, followingID int64) (bool, error)
 	Follow(ctx context.Context, followerID int64, followingID int64) error
 	UnFollow(ctx context.Context, followerID int64, followingID int64) error
+
+	// ListFollowing 用户关注的人，ListFollowers 关注该用户的人，都按关注时间正序
+	ListFollowing(ctx context.Context, userID int64) ([]*entity.User, error)
+	ListFollowers(ctx context.Context, userID int64) ([]*entity.User, error)
 }
@@ -0,0 +1,297 @@
+package router
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"github/CiroLong/realworld-gin/internal/pkg/jwt"
+	"github/CiroLong/realworld-gin/internal/pkg/oidc"
+	"github/CiroLong/realworld-gin/internal/service"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	gojwt "github.com/golang-jwt/jwt/v5"
+)
+
+// testIdP 测试用的 OpenID Connect provider，和 cmd/oidc-stub 一样校验 client、授权码和 PKCE，
+// 但授权由测试直接调用 authorize 完成，ID token 的内容可以在签发前修改
+type testIdP struct {
+	t   *testing.T
+	srv *httptest.Server
+	key *jwt.Key
+
+	mu    sync.Mutex
+	codes map[string]*testGrant
+	next  int
+}
+
+// testGrant 一个授权码对应的授权
+type testGrant struct {
+	subject   string
+	email     string
+	nonce     string
+	challenge string
+}
+
+const (
+	testClientID     = "realworld"
+	testClientSecret = "realworld-secret"
+	testRedirectURL  = "http://app.test/oidc/callback"
+)
+
+func newTestIdP(t *testing.T) *testIdP {
+	t.Helper()
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &testIdP{
+		t:     t,
+		key:   &jwt.Key{ID: "test", Method: gojwt.SigningMethodRS256, Private: priv, Public: &priv.PublicKey},
+		codes: make(map[string]*testGrant),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		writeTestJSON(w, http.StatusOK, gin.H{
+			"issuer":                 p.srv.URL,
+			"authorization_endpoint": p.srv.URL + "/authorize",
+			"token_endpoint":         p.srv.URL + "/token",
+			"jwks_uri":               p.srv.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("GET /jwks", func(w http.ResponseWriter, r *http.Request) {
+		writeTestJSON(w, http.StatusOK, jwt.JWKS{Keys: []jwt.JWK{p.key.JWK()}})
+	})
+	mux.HandleFunc("POST /token", p.token)
+	p.srv = httptest.NewServer(mux)
+	t.Cleanup(p.srv.Close)
+	return p
+}
+
+// provider 指向这个 IdP 的 provider 配置
+func (p *testIdP) provider(name string) service.OIDCProviderConfig {
+	return service.OIDCProviderConfig{
+		Name:        name,
+		DisplayName: name,
+		Config: oidc.Config{
+			Issuer:       p.srv.URL,
+			ClientID:     testClientID,
+			ClientSecret: testClientSecret,
+			RedirectURL:  testRedirectURL,
+		},
+	}
+}
+
+// authorize 模拟用户在授权页面同意：记下授权地址上的 nonce 和 code_challenge，返回授权码
+// edit 不为 nil 时在保存前修改授权，模拟被篡改或不匹配的请求
+func (p *testIdP) authorize(authURL, subject, email string, edit func(g *testGrant)) string {
+	p.t.Helper()
+	u, err := url.Parse(authURL)
+	if err != nil {
+		p.t.Fatal(err)
+	}
+	q := u.Query()
+	if q.Get("client_id") != testClientID || q.Get("redirect_uri") != testRedirectURL || q.Get("code_challenge_method") != "S256" {
+		p.t.Fatalf("unexpected authorization url %s", authURL)
+	}
+
+	g := &testGrant{subject: subject, email: email, nonce: q.Get("nonce"), challenge: q.Get("code_challenge")}
+	if edit != nil {
+		edit(g)
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.next++
+	code := "code-" + strconv.Itoa(p.next)
+	p.codes[code] = g
+	return code
+}
+
+func (p *testIdP) token(w http.ResponseWriter, r *http.Request) {
+	// 1. client 认证，授权码只能用一次，PKCE
+	clientID, clientSecret, _ := r.BasicAuth()
+	if clientID != testClientID || clientSecret != testClientSecret || r.PostFormValue("redirect_uri") != testRedirectURL {
+		writeTestJSON(w, http.StatusUnauthorized, gin.H{"error": "invalid_client"})
+		return
+	}
+	p.mu.Lock()
+	g, ok := p.codes[r.PostFormValue("code")]
+	delete(p.codes, r.PostFormValue("code"))
+	p.mu.Unlock()
+	sum := sha256.Sum256([]byte(r.PostFormValue("code_verifier")))
+	if !ok || base64.RawURLEncoding.EncodeToString(sum[:]) != g.challenge {
+		writeTestJSON(w, http.StatusBadRequest, gin.H{"error": "invalid_grant"})
+		return
+	}
+
+	// 2. 签发 ID token
+	now := time.Now()
+	idToken := gojwt.NewWithClaims(gojwt.SigningMethodRS256, gojwt.MapClaims{
+		"iss":            p.srv.URL,
+		"sub":            g.subject,
+		"aud":            testClientID,
+		"iat":            now.Unix(),
+		"exp":            now.Add(5 * time.Minute).Unix(),
+		"nonce":          g.nonce,
+		"email":          g.email,
+		"email_verified": true,
+	})
+	idToken.Header["kid"] = p.key.ID
+	signed, err := idToken.SignedString(p.key.Private)
+	if err != nil {
+		writeTestJSON(w, http.StatusInternalServerError, gin.H{"error": "server_error"})
+		return
+	}
+	writeTestJSON(w, http.StatusOK, gin.H{"access_token": "x", "token_type": "Bearer", "id_token": signed})
+}
+
+func writeTestJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// startOIDC 发起登录（token 为空）或绑定，返回授权地址和 state
+func (s *testServer) startOIDC(provider, token string) (string, string) {
+	s.t.Helper()
+	var w *httptest.ResponseRecorder
+	if token == "" {
+		w = s.do(http.MethodGet, "/api/users/oidc/"+provider, "", nil)
+	} else {
+		w = s.do(http.MethodPost, "/api/user/identities/"+provider, token, nil)
+	}
+	var resp struct {
+		OIDC struct {
+			AuthorizationURL string `json:"authorizationUrl"`
+			State            string `json:"state"`
+		} `json:"oidc"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil || w.Code != http.StatusOK {
+		s.t.Fatalf("start oidc %s: %d %s", provider, w.Code, w.Body)
+	}
+	return resp.OIDC.AuthorizationURL, resp.OIDC.State
+}
+
+// oidcLogin 提交登录回调，成功时返回用户名
+func (s *testServer) oidcLogin(provider, code, state string) (int, string) {
+	s.t.Helper()
+	w := s.do(http.MethodPost, "/api/users/oidc/"+provider+"/callback", "", gin.H{"code": code, "state": state})
+	var resp struct {
+		User struct {
+			Username string `json:"username"`
+		} `json:"user"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	return w.Code, resp.User.Username
+}
+
+func TestOIDCLogin(t *testing.T) {
+	idp := newTestIdP(t)
+	s := newTestServer(t, idp.provider("idp"), idp.provider("other"))
+
+	// 1. 新用户自动注册并登录
+	authURL, state := s.startOIDC("idp", "")
+	code := idp.authorize(authURL, "sub-1", "new@example.com", nil)
+	status, username := s.oidcLogin("idp", code, state)
+	if status != http.StatusOK || username == "" {
+		t.Fatalf("first login: %d %q", status, username)
+	}
+
+	// 2. state 只能用一次
+	if status, _ := s.oidcLogin("idp", code, state); status != http.StatusBadRequest {
+		t.Errorf("replayed state: got %d, want 400", status)
+	}
+
+	// 3. 同一个第三方账号再次登录到同一个用户
+	authURL, state = s.startOIDC("idp", "")
+	if status, again := s.oidcLogin("idp", idp.authorize(authURL, "sub-1", "new@example.com", nil), state); status != http.StatusOK || again != username {
+		t.Errorf("second login: %d %q, want %q", status, again, username)
+	}
+
+	// 4. 为一个 provider 发起的登录不能在另一个 provider 的回调上完成
+	authURL, state = s.startOIDC("idp", "")
+	if status, _ := s.oidcLogin("other", idp.authorize(authURL, "sub-1", "new@example.com", nil), state); status != http.StatusBadRequest {
+		t.Errorf("provider mismatch: got %d, want 400", status)
+	}
+
+	// 5. ID token 的 nonce 不是这次登录的，或者 code_verifier 和授权时的 code_challenge 不匹配
+	tampered := map[string]func(g *testGrant){
+		"nonce": func(g *testGrant) { g.nonce = "another-login" },
+		"pkce":  func(g *testGrant) { g.challenge = base64.RawURLEncoding.EncodeToString(make([]byte, 32)) },
+	}
+	for name, edit := range tampered {
+		authURL, state = s.startOIDC("idp", "")
+		if status, _ := s.oidcLogin("idp", idp.authorize(authURL, "sub-1", "new@example.com", edit), state); status != http.StatusUnauthorized {
+			t.Errorf("%s mismatch: got %d, want 401", name, status)
+		}
+	}
+}
+
+func TestOIDCStateBoundToUser(t *testing.T) {
+	idp := newTestIdP(t)
+	s := newTestServer(t, idp.provider("idp"))
+	jakeToken := s.register("jake")
+	bobToken := s.register("bob")
+	link := func(token, code, state string) int {
+		t.Helper()
+		return s.do(http.MethodPost, "/api/user/identities/idp/callback", token, gin.H{"code": code, "state": state}).Code
+	}
+
+	// 1. 登录发起的 state 不能用来绑定
+	authURL, state := s.startOIDC("idp", "")
+	if status := link(jakeToken, idp.authorize(authURL, "sub-1", "x@example.com", nil), state); status != http.StatusBadRequest {
+		t.Errorf("login state on link callback: got %d, want 400", status)
+	}
+
+	// 2. jake 发起的绑定不能由 bob 完成，也不能用来登录
+	authURL, state = s.startOIDC("idp", jakeToken)
+	if status := link(bobToken, idp.authorize(authURL, "sub-1", "x@example.com", nil), state); status != http.StatusBadRequest {
+		t.Errorf("jake's state used by bob: got %d, want 400", status)
+	}
+	authURL, state = s.startOIDC("idp", jakeToken)
+	if status, _ := s.oidcLogin("idp", idp.authorize(authURL, "sub-1", "x@example.com", nil), state); status != http.StatusBadRequest {
+		t.Errorf("link state on login callback: got %d, want 400", status)
+	}
+
+	// 3. 第三方账号没有绑定到任何人
+	for _, token := range []string{jakeToken, bobToken} {
+		if w := s.do(http.MethodGet, "/api/user/identities", token, nil); w.Body.String() != `{"identities":[]}` {
+			t.Errorf("identities: %s", w.Body)
+		}
+	}
+}
+
+func TestOIDCLoginEmailInUse(t *testing.T) {
+	idp := newTestIdP(t)
+	s := newTestServer(t, idp.provider("idp"))
+	jakeToken := s.register("jake")
+
+	// 1. 第三方账号的邮箱和 jake 相同：不自动绑定，也不登录
+	authURL, state := s.startOIDC("idp", "")
+	if status, _ := s.oidcLogin("idp", idp.authorize(authURL, "sub-jake", "jake@example.com", nil), state); status != http.StatusConflict {
+		t.Fatalf("login with existing email: got %d, want 409", status)
+	}
+	if w := s.do(http.MethodGet, "/api/user/identities", jakeToken, nil); w.Body.String() != `{"identities":[]}` {
+		t.Errorf("identities after rejected login: %s", w.Body)
+	}
+
+	// 2. jake 登录后自己绑定，之后可以用第三方账号登录
+	authURL, state = s.startOIDC("idp", jakeToken)
+	w := s.do(http.MethodPost, "/api/user/identities/idp/callback", jakeToken, gin.H{"code": idp.authorize(authURL, "sub-jake", "jake@example.com", nil), "state": state})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("link: %d %s", w.Code, w.Body)
+	}
+	authURL, state = s.startOIDC("idp", "")
+	if status, username := s.oidcLogin("idp", idp.authorize(authURL, "sub-jake", "jake@example.com", nil), state); status != http.StatusOK || username != "jake" {
+		t.Errorf("login after link: %d %q", status, username)
+	}
+}
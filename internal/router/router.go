@@ -3,6 +3,7 @@ package router
 import (
 	"github/CiroLong/realworld-gin/internal/api"
 	"github/CiroLong/realworld-gin/internal/middleware"
+	"github/CiroLong/realworld-gin/internal/pkg/auth"
 	"github/CiroLong/realworld-gin/internal/pkg/jwt"
 	"github/CiroLong/realworld-gin/internal/service"
 
@@ -11,8 +12,18 @@ import (
 
 func NewRouter(
 	userService service.UserService,
+	accountService service.AccountService,
+	mfaService service.MFAService,
+	oidcService service.OIDCService,
 	articleService service.ArticleService,
 	commentService service.CommentService,
+	tokenService service.TokenService,
+	sessionService service.SessionService,
+	exportService service.ExportService,
+	roleService service.RoleService,
+	adminService service.AdminService,
+	policy service.Policy,
+	authenticator service.Authenticator,
 	jwtMgr jwt.Manager,
 ) *gin.Engine {
 	r := gin.New()
@@ -23,12 +34,29 @@ func NewRouter(
 
 	// handler
 	userHandler := api.NewUserHandler(userService)
+	accountHandler := api.NewAccountHandler(accountService)
+	mfaHandler := api.NewMFAHandler(mfaService)
+	oidcHandler := api.NewOIDCHandler(oidcService)
 	profileHandler := api.NewProfileHandler(userService)
 	articleHandler := api.NewArticleHandler(articleService)
 	commentHandler := api.NewCommentHandler(commentService)
+	tokenHandler := api.NewTokenHandler(tokenService)
+	sessionHandler := api.NewSessionHandler(sessionService)
+	exportHandler := api.NewExportHandler(exportService)
+	adminHandler := api.NewAdminHandler(roleService, adminService)
+	jwksHandler := api.NewJWKSHandler(jwtMgr)
 
 	// middleware
-	auth := middleware.AuthMiddleware(jwtMgr)
+	// 登录得到的 JWT 拥有全部权限；个人访问令牌可以访问所有只读接口，写操作按路由组要求对应的 scope
+	authn := middleware.AuthMiddleware(authenticator)
+	optionalAuthn := middleware.OptionalAuthMiddleware(authenticator) // 带不带 token 都能访问，带了会识别身份
+	session := middleware.RequireSession() // 账号安全相关操作不允许使用个人访问令牌
+	owner := middleware.RejectImpersonation() // 修改登录凭证、导出个人数据的操作不允许管理员模拟登录的会话
+	verified := middleware.RequireVerifiedEmail(accountService) // 是否生效由 account.require_verified_email 决定
+	slugs := middleware.ResolveSlug(articleService) // 旧 slug 改写为文章当前的 slug，放在认证之后
+
+	// 公开验签公钥
+	r.GET("/.well-known/jwks.json", jwksHandler.GetJWKS) // GET /.well-known/jwks.json - JWKS
 
 	apiGroup := r.Group("/api")
 
@@ -39,14 +67,62 @@ func NewRouter(
 		// 公开路由
 		usersGroup.POST("", userHandler.Register)         // POST /api/users - 注册
 		usersGroup.POST("/login", userHandler.Login)    // POST /api/users/login - 登录
+		usersGroup.POST("/login/2fa", userHandler.LoginMFA) // POST /api/users/login/2fa - 登录第二步（两步验证）
+		usersGroup.POST("/refresh", userHandler.Refresh) // POST /api/users/refresh - 刷新 token
+		usersGroup.POST("/password/forgot", accountHandler.ForgotPassword) // POST /api/users/password/forgot - 发送密码重置邮件
+		usersGroup.POST("/password/reset", accountHandler.ResetPassword)   // POST /api/users/password/reset - 重置密码
+		usersGroup.POST("/verify", accountHandler.VerifyEmail)             // POST /api/users/verify - 验证邮箱
+		usersGroup.POST("/magic-link", accountHandler.SendMagicLink)       // POST /api/users/magic-link - 发送免密登录链接
+		usersGroup.POST("/magic-link/redeem", userHandler.LoginMagicLink) // POST /api/users/magic-link/redeem - 免密登录
+
+		// 第三方登录（OIDC）
+		usersGroup.GET("/oidc", oidcHandler.ListProviders)                       // GET /api/users/oidc - 可用的第三方登录
+		usersGroup.GET("/oidc/:provider", oidcHandler.AuthorizeLogin)            // GET /api/users/oidc/:provider - 获取授权地址
+		usersGroup.POST("/oidc/:provider/callback", oidcHandler.LoginCallback)   // POST /api/users/oidc/:provider/callback - 回调登录
 	}
 
 	// 当前用户相关路由（需要认证）
 	userGroup := apiGroup.Group("/user")
-	userGroup.Use(auth)
+	userGroup.Use(authn)
 	{
 		userGroup.GET("", userHandler.GetCurrentUser)    // GET /api/user - 获取当前用户
-		userGroup.PUT("", userHandler.UpdateCurrentUser) // PUT /api/user - 更新当前用户
+	}
+
+	// 账号管理（只允许登录会话）
+	accountGroup := userGroup.Group("")
+	accountGroup.Use(session)
+	{
+		accountGroup.PUT("", userHandler.UpdateCurrentUser) // PUT /api/user - 更新当前用户
+		accountGroup.DELETE("", userHandler.DeleteCurrentUser) // DELETE /api/user - 注销账号
+		accountGroup.POST("/logout", userHandler.Logout)    // POST /api/user/logout - 登出（撤销当前会话）
+		accountGroup.POST("/verify/resend", accountHandler.ResendVerification) // POST /api/user/verify/resend - 重发验证邮件
+
+		// 两步验证
+		accountGroup.GET("/2fa", owner, mfaHandler.GetStatus)                             // GET /api/user/2fa - 两步验证状态
+		accountGroup.POST("/2fa/enroll", owner, mfaHandler.Enroll)                        // POST /api/user/2fa/enroll - 生成密钥
+		accountGroup.POST("/2fa/confirm", owner, mfaHandler.Confirm)                      // POST /api/user/2fa/confirm - 确认绑定，返回恢复码
+		accountGroup.POST("/2fa/disable", owner, mfaHandler.Disable)                      // POST /api/user/2fa/disable - 关闭两步验证
+		accountGroup.POST("/2fa/recovery-codes", owner, mfaHandler.RegenerateRecoveryCodes) // POST /api/user/2fa/recovery-codes - 重新生成恢复码
+
+		// 个人访问令牌
+		accountGroup.GET("/tokens", tokenHandler.ListTokens)          // GET /api/user/tokens - 令牌列表
+		accountGroup.POST("/tokens", owner, tokenHandler.CreateToken)        // POST /api/user/tokens - 创建令牌（明文只返回一次）
+		accountGroup.DELETE("/tokens/:id", owner, tokenHandler.RevokeToken)  // DELETE /api/user/tokens/:id - 撤销令牌
+
+		// 登录会话
+		accountGroup.GET("/sessions", sessionHandler.ListSessions)            // GET /api/user/sessions - 会话列表
+		accountGroup.DELETE("/sessions", owner, sessionHandler.RevokeAllSessions)    // DELETE /api/user/sessions - 在所有设备上登出
+		accountGroup.DELETE("/sessions/:id", owner, sessionHandler.RevokeSession)    // DELETE /api/user/sessions/:id - 撤销指定会话
+
+		// 个人数据导出
+		accountGroup.GET("/export", owner, exportHandler.Export)        // GET /api/user/export - 导出个人数据（ZIP，数据多时后台生成）
+		accountGroup.GET("/export/:id", owner, exportHandler.GetExport) // GET /api/user/export/:id - 查询 / 下载后台生成的导出
+
+		// 第三方账号绑定
+		accountGroup.GET("/identities", oidcHandler.ListIdentities)                      // GET /api/user/identities - 已绑定的第三方账号
+		accountGroup.POST("/identities/:provider", owner, oidcHandler.AuthorizeLink)            // POST /api/user/identities/:provider - 获取绑定用的授权地址
+		accountGroup.POST("/identities/:provider/callback", owner, oidcHandler.LinkCallback)    // POST /api/user/identities/:provider/callback - 回调绑定
+		accountGroup.DELETE("/identities/:provider", owner, oidcHandler.Unlink)                 // DELETE /api/user/identities/:provider - 解绑
 	}
 
 	// ==================== Profiles ====================
@@ -58,7 +134,7 @@ func NewRouter(
 
 		// 需要认证的路由
 		profilesAuthGroup := profilesGroup.Group("/:username")
-		profilesAuthGroup.Use(auth)
+		profilesAuthGroup.Use(authn, middleware.RequireScope(auth.ScopeProfilesWrite))
 		{
 			profilesAuthGroup.POST("/follow", profileHandler.Follow)       // POST /api/profiles/:username/follow - 关注用户
 			profilesAuthGroup.DELETE("/follow", profileHandler.Unfollow)    // DELETE /api/profiles/:username/follow - 取消关注
@@ -70,21 +146,29 @@ func NewRouter(
 	articlesGroup := apiGroup.Group("/articles")
 	{
 		// 公开路由
-		articlesGroup.GET("", articleHandler.ListArticles)         // GET /api/articles - 文章列表
+		articlesGroup.GET("", optionalAuthn, articleHandler.ListArticles)         // GET /api/articles - 文章列表
 
 		// Feed 路由（需要认证）- 必须放在 /:slug 前面，否则会被当作 slug 处理
-		articlesGroup.GET("/feed", auth, articleHandler.FeedArticles)   // GET /api/articles/feed - 文章Feed
+		articlesGroup.GET("/feed", authn, articleHandler.FeedArticles)   // GET /api/articles/feed - 文章Feed
+		articlesGroup.GET("/scheduled", authn, articleHandler.ScheduledArticles) // GET /api/articles/scheduled - 自己的定时发布文章
 
 		// 公开路由
-		articlesGroup.GET("/:slug", articleHandler.GetArticle)     // GET /api/articles/:slug - 获取文章详情
+		articlesGroup.GET("/:slug", optionalAuthn, slugs, articleHandler.GetArticle)     // GET /api/articles/:slug - 获取文章详情（草稿只有作者可见）
+
+		// 修订历史（需要认证，只有能编辑文章的人可以查看）
+		articlesGroup.GET("/:slug/revisions", authn, slugs, articleHandler.ListRevisions)          // GET /api/articles/:slug/revisions - 修订列表
+		articlesGroup.GET("/:slug/revisions/:id", authn, slugs, articleHandler.GetRevision)        // GET /api/articles/:slug/revisions/:id - 修订内容
+		articlesGroup.GET("/:slug/revisions/:id/diff", authn, slugs, articleHandler.DiffRevisions) // GET /api/articles/:slug/revisions/:id/diff?from= - 与另一条修订的 diff
 
 		// 需要认证的路由
 		articlesAuthGroup := articlesGroup.Group("")
-		articlesAuthGroup.Use(auth)
+		articlesAuthGroup.Use(authn, middleware.RequireScope(auth.ScopeArticlesWrite), slugs)
 		{
-			articlesAuthGroup.POST("", articleHandler.CreateArticle)                 // POST /api/articles - 创建文章
+			articlesAuthGroup.POST("", verified, articleHandler.CreateArticle)       // POST /api/articles - 创建文章（需验证邮箱）
 			articlesAuthGroup.PUT("/:slug", articleHandler.UpdateArticle)            // PUT /api/articles/:slug - 更新文章
 			articlesAuthGroup.DELETE("/:slug", articleHandler.DeleteArticle)         // DELETE /api/articles/:slug - 删除文章
+			articlesAuthGroup.POST("/:slug/publish", articleHandler.PublishArticle)  // POST /api/articles/:slug/publish - 发布文章
+			articlesAuthGroup.POST("/:slug/revisions/:id/restore", articleHandler.RestoreRevision) // POST /api/articles/:slug/revisions/:id/restore - 恢复到某条修订
 			articlesAuthGroup.POST("/:slug/favorite", articleHandler.FavoriteArticle)     // POST /api/articles/:slug/favorite - 收藏文章
 			articlesAuthGroup.DELETE("/:slug/favorite", articleHandler.UnfavoriteArticle) // DELETE /api/articles/:slug/favorite - 取消收藏
 		}
@@ -95,17 +179,34 @@ func NewRouter(
 	commentsGroup := apiGroup.Group("/articles/:slug/comments")
 	{
 		// 公开路由
-		commentsGroup.GET("", commentHandler.GetComments) // GET /api/articles/:slug/comments - 获取评论
+		commentsGroup.GET("", optionalAuthn, slugs, commentHandler.GetComments) // GET /api/articles/:slug/comments - 获取评论
 
 		// 需要认证的路由
 		commentsAuthGroup := commentsGroup.Group("")
-		commentsAuthGroup.Use(auth)
+		commentsAuthGroup.Use(authn, middleware.RequireScope(auth.ScopeCommentsWrite), slugs)
 		{
-			commentsAuthGroup.POST("", commentHandler.CreateComment)           // POST /api/articles/:slug/comments - 创建评论
+			commentsAuthGroup.POST("", verified, commentHandler.CreateComment) // POST /api/articles/:slug/comments - 创建评论（需验证邮箱）
 			commentsAuthGroup.DELETE("/:id", commentHandler.DeleteComment)     // DELETE /api/articles/:slug/comments/:id - 删除评论
 		}
 	}
 
+	// ==================== Admin ====================
+	// 管理接口：只允许登录会话，且角色需要拥有 users:manage 权限
+	adminGroup := apiGroup.Group("/admin")
+	adminGroup.Use(authn, session, middleware.RequirePermission(policy, auth.PermUsersManage))
+	{
+		adminGroup.GET("/roles", adminHandler.ListRoles) // GET /api/admin/roles - 角色及权限
+
+		adminGroup.GET("/users", adminHandler.ListUsers)                             // GET /api/admin/users?q=&suspended=&limit=&offset= - 搜索用户
+		adminGroup.GET("/users/:id", adminHandler.GetUser)                           // GET /api/admin/users/:id - 用户详情
+		adminGroup.PUT("/users/:id", adminHandler.UpdateUser)                        // PUT /api/admin/users/:id - 修改邮箱 / 用户名
+		adminGroup.POST("/users/:id/suspend", adminHandler.SuspendUser)              // POST /api/admin/users/:id/suspend - 停用账号
+		adminGroup.DELETE("/users/:id/suspend", adminHandler.UnsuspendUser)          // DELETE /api/admin/users/:id/suspend - 解除停用
+		adminGroup.POST("/users/:id/password-reset", adminHandler.ForcePasswordReset) // POST /api/admin/users/:id/password-reset - 强制重置密码
+		adminGroup.POST("/users/:id/unlock", adminHandler.UnlockUser)                // POST /api/admin/users/:id/unlock - 解除登录锁定
+		adminGroup.POST("/users/:id/impersonate", adminHandler.Impersonate)          // POST /api/admin/users/:id/impersonate - 以该用户身份登录
+	}
+
 	// ==================== Tags ====================
 	// 标签相关路由（公开）
 	apiGroup.GET("/tags", articleHandler.GetTags) // GET /api/tags - 获取标签列表
@@ -0,0 +1,359 @@
+package router
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github/CiroLong/realworld-gin/internal/pkg/auth"
+	"github/CiroLong/realworld-gin/internal/pkg/jwt"
+	"github/CiroLong/realworld-gin/internal/pkg/mailer"
+	"github/CiroLong/realworld-gin/internal/pkg/password"
+	"github/CiroLong/realworld-gin/internal/pkg/secretbox"
+	"github/CiroLong/realworld-gin/internal/pkg/slug"
+	"github/CiroLong/realworld-gin/internal/repository/memory"
+	"github/CiroLong/realworld-gin/internal/service"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// testServer 用内存 repo 组装完整的路由，依赖注入和 cmd/server 一致
+type testServer struct {
+	t      *testing.T
+	engine *gin.Engine
+	roles  service.RoleService
+}
+
+// newTestServer providers 为第三方登录的配置，不需要时不传
+func newTestServer(t *testing.T, providers ...service.OIDCProviderConfig) *testServer {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	store := memory.NewStore()
+	userRepo := memory.NewUserRepo(store)
+	sessionRepo := memory.NewSessionRepo(store)
+	articleRepo := memory.NewArticleRepo(store)
+	commentRepo := memory.NewCommentRepo(store)
+	patRepo := memory.NewPersonalAccessTokenRepo(store)
+	roleRepo := memory.NewRoleRepo(store)
+
+	jwtMgr := jwt.NewManager("test-secret", time.Hour)
+	hasher, err := password.NewHasher(password.Config{Algorithm: password.AlgorithmBcrypt, BcryptCost: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	box, err := secretbox.New("test-mfa-key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	slugs, err := slug.New(slug.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	accountService := service.NewAccountService(userRepo, sessionRepo, patRepo, memory.NewOneTimeTokenRepo(store), hasher, mailer.NewLogMailer("test@example.com"), service.AccountConfig{
+		PasswordResetTTL:     time.Hour,
+		EmailVerificationTTL: time.Hour,
+		MagicLinkTTL:         time.Hour,
+	})
+	mfaService := service.NewMFAService(memory.NewMFARepo(store), userRepo, jwtMgr, hasher, box, service.MFAConfig{
+		Issuer:     "test",
+		PendingTTL: time.Minute,
+	})
+	guard := service.NewLoginProtection(memory.NewLoginAttemptRepo(store), service.LoginProtectionConfig{})
+	userService := service.NewUserService(userRepo, sessionRepo, jwtMgr, hasher, time.Hour, accountService, mfaService, guard, service.UserConfig{})
+	policy := service.NewPolicy(userRepo, roleRepo)
+	roleService := service.NewRoleService(roleRepo, userRepo)
+	if _, err := roleService.Seed(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	articleService := service.NewArticleService(articleRepo, userRepo, policy, slugs, service.ArticleConfig{})
+	commentService := service.NewCommentService(commentRepo, articleRepo, userRepo, policy)
+	oidcService := service.NewOIDCService(memory.NewIdentityRepo(store), userRepo, sessionRepo, jwtMgr, time.Hour, accountService, mfaService, service.OIDCConfig{StateTTL: time.Minute, Providers: providers})
+	exportService := service.NewExportService(userRepo, articleRepo, commentRepo, memory.NewDataExportRepo(store), service.ExportConfig{})
+	adminService := service.NewAdminService(userRepo, sessionRepo, jwtMgr, accountService, guard, policy)
+	authenticator := service.NewAuthenticator(jwtMgr, patRepo, sessionRepo, userRepo)
+
+	engine := NewRouter(userService, accountService, mfaService, oidcService, articleService, commentService,
+		service.NewTokenService(patRepo), service.NewSessionService(sessionRepo, time.Hour), exportService,
+		roleService, adminService, policy, authenticator, jwtMgr)
+	return &testServer{t: t, engine: engine, roles: roleService}
+}
+
+// do 发送 JSON 请求，token 为空时不带 Authorization
+func (s *testServer) do(method, path, token string, body any) *httptest.ResponseRecorder {
+	s.t.Helper()
+	var buf bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&buf).Encode(body); err != nil {
+			s.t.Fatal(err)
+		}
+	}
+	req := httptest.NewRequest(method, path, &buf)
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Token "+token)
+	}
+	w := httptest.NewRecorder()
+	s.engine.ServeHTTP(w, req)
+	return w
+}
+
+// register 注册并返回 access token
+func (s *testServer) register(username string) string {
+	s.t.Helper()
+	w := s.do(http.MethodPost, "/api/users", "", gin.H{"user": gin.H{
+		"username": username,
+		"email":    username + "@example.com",
+		"password": username + "-password",
+	}})
+	if w.Code != http.StatusCreated && w.Code != http.StatusOK {
+		s.t.Fatalf("register %s: %d %s", username, w.Code, w.Body)
+	}
+	var resp struct {
+		User struct {
+			Token string `json:"token"`
+		} `json:"user"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		s.t.Fatal(err)
+	}
+	return resp.User.Token
+}
+
+// userID 通过管理接口按用户名查 id
+func (s *testServer) userID(adminToken, username string) int64 {
+	s.t.Helper()
+	w := s.do(http.MethodGet, "/api/admin/users?q="+username, adminToken, nil)
+	var resp struct {
+		Users []struct {
+			ID       int64  `json:"id"`
+			Username string `json:"username"`
+		} `json:"users"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		s.t.Fatalf("list users: %d %s", w.Code, w.Body)
+	}
+	for _, u := range resp.Users {
+		if u.Username == username {
+			return u.ID
+		}
+	}
+	s.t.Fatalf("user %s not found: %s", username, w.Body)
+	return 0
+}
+
+func TestImpersonatedSessionCannotTakeOverAccount(t *testing.T) {
+	s := newTestServer(t)
+	adminToken := s.register("alice")
+	s.register("bob")
+	if err := s.roles.Assign(context.Background(), "alice@example.com", auth.RoleAdmin); err != nil {
+		t.Fatal(err)
+	}
+
+	// 1. 管理员以 bob 的身份登录
+	w := s.do(http.MethodPost, fmt.Sprintf("/api/admin/users/%d/impersonate", s.userID(adminToken, "bob")), adminToken, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("impersonate: %d %s", w.Code, w.Body)
+	}
+	var resp struct {
+		User struct {
+			Token string `json:"token"`
+		} `json:"user"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	token := resp.User.Token
+
+	// 2. 修改登录凭证的接口全部拒绝
+	forbidden := []struct {
+		method string
+		path   string
+		body   any
+	}{
+		{http.MethodPut, "/api/user", gin.H{"user": gin.H{"password": "hijacked-password"}}},
+		{http.MethodPut, "/api/user", gin.H{"user": gin.H{"email": "mallory@example.com"}}},
+		{http.MethodDelete, "/api/user", gin.H{"user": gin.H{"password": "bob-password", "confirm": "bob"}}},
+		{http.MethodPost, "/api/user/tokens", gin.H{"token": gin.H{"name": "backdoor", "scopes": []string{auth.ScopeRead}}}},
+		{http.MethodDelete, "/api/user/tokens/1", nil},
+		{http.MethodGet, "/api/user/2fa", nil},
+		{http.MethodPost, "/api/user/2fa/enroll", nil},
+		{http.MethodPost, "/api/user/2fa/confirm", gin.H{"code": "123456"}},
+		{http.MethodPost, "/api/user/2fa/disable", gin.H{"code": "123456"}},
+		{http.MethodPost, "/api/user/2fa/recovery-codes", gin.H{"code": "123456"}},
+		{http.MethodPost, "/api/user/identities/github", nil},
+		{http.MethodPost, "/api/user/identities/github/callback", gin.H{"code": "x", "state": "y"}},
+		{http.MethodDelete, "/api/user/identities/github", nil},
+		{http.MethodDelete, "/api/user/sessions", nil},
+		{http.MethodDelete, "/api/user/sessions/1", nil},
+		{http.MethodGet, "/api/user/export", nil},
+		{http.MethodGet, "/api/user/export/1", nil},
+	}
+	for _, tc := range forbidden {
+		if w := s.do(tc.method, tc.path, token, tc.body); w.Code != http.StatusForbidden {
+			t.Errorf("%s %s: got %d %s, want 403", tc.method, tc.path, w.Code, w.Body)
+		}
+	}
+
+	// 3. 其他操作照常，排查问题时可以改资料
+	if w := s.do(http.MethodGet, "/api/user", token, nil); w.Code != http.StatusOK {
+		t.Errorf("GET /api/user: %d %s", w.Code, w.Body)
+	}
+	if w := s.do(http.MethodPut, "/api/user", token, gin.H{"user": gin.H{"bio": "hello"}}); w.Code != http.StatusOK {
+		t.Errorf("PUT /api/user bio: %d %s", w.Code, w.Body)
+	}
+
+	// 4. bob 仍然可以用原来的密码登录
+	w = s.do(http.MethodPost, "/api/users/login", "", gin.H{"user": gin.H{"email": "bob@example.com", "password": "bob-password"}})
+	if w.Code != http.StatusOK {
+		t.Errorf("bob login: %d %s", w.Code, w.Body)
+	}
+}
+
+func TestLoginRejectsBadCredentials(t *testing.T) {
+	s := newTestServer(t)
+	s.register("jake")
+
+	tests := []struct {
+		name string
+		path string
+		body any
+	}{
+		{"wrong password", "/api/users/login", gin.H{"user": gin.H{"email": "jake@example.com", "password": "wrong-password"}}},
+		{"unknown email", "/api/users/login", gin.H{"user": gin.H{"email": "nobody@example.com", "password": "jake-password"}}},
+		{"bad 2fa token", "/api/users/login/2fa", gin.H{"mfaToken": "not-a-token", "code": "123456"}},
+		{"bad magic link", "/api/users/magic-link/redeem", gin.H{"token": "not-a-token"}},
+	}
+	for _, tc := range tests {
+		if w := s.do(http.MethodPost, tc.path, "", tc.body); w.Code != http.StatusUnauthorized {
+			t.Errorf("%s: got %d %s, want 401", tc.name, w.Code, w.Body)
+		}
+	}
+}
+
+func TestAdminCannotEditAnotherAdmin(t *testing.T) {
+	s := newTestServer(t)
+	aliceToken := s.register("alice")
+	s.register("carol")
+	for _, email := range []string{"alice@example.com", "carol@example.com"} {
+		if err := s.roles.Assign(context.Background(), email, auth.RoleAdmin); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	path := fmt.Sprintf("/api/admin/users/%d", s.userID(aliceToken, "carol"))
+	w := s.do(http.MethodPut, path, aliceToken, gin.H{"user": gin.H{"email": "alice2@example.com"}})
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("edit another admin: got %d %s, want 403", w.Code, w.Body)
+	}
+
+	// carol 的邮箱没有变，仍然可以登录
+	w = s.do(http.MethodPost, "/api/users/login", "", gin.H{"user": gin.H{"email": "carol@example.com", "password": "carol-password"}})
+	if w.Code != http.StatusOK {
+		t.Errorf("carol login: %d %s", w.Code, w.Body)
+	}
+}
+
+func TestAdminListUsersPaging(t *testing.T) {
+	s := newTestServer(t)
+	adminToken := s.register("alice")
+	s.register("bob")
+	s.register("carol")
+	if err := s.roles.Assign(context.Background(), "alice@example.com", auth.RoleAdmin); err != nil {
+		t.Fatal(err)
+	}
+
+	// 1. limit 超出范围时截到 [1, 100]，无法解析时取默认值
+	tests := []struct {
+		query string
+		want  int
+	}{
+		{"limit=-1", 1},
+		{"limit=0", 1},
+		{"limit=1000000", 3},
+		{"limit=abc", 3},
+		{"limit=2&offset=2", 1},
+	}
+	for _, tc := range tests {
+		w := s.do(http.MethodGet, "/api/admin/users?"+tc.query, adminToken, nil)
+		var resp struct {
+			Users []struct {
+				ID int64 `json:"id"`
+			} `json:"users"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil || w.Code != http.StatusOK {
+			t.Fatalf("%s: %d %s", tc.query, w.Code, w.Body)
+		}
+		if len(resp.Users) != tc.want {
+			t.Errorf("%s: got %d users, want %d", tc.query, len(resp.Users), tc.want)
+		}
+	}
+
+	// 2. offset 为负数时拒绝
+	if w := s.do(http.MethodGet, "/api/admin/users?offset=-1", adminToken, nil); w.Code != http.StatusBadRequest {
+		t.Errorf("negative offset: got %d %s, want 400", w.Code, w.Body)
+	}
+}
+
+func TestUpdateArticleTags(t *testing.T) {
+	s := newTestServer(t)
+	token := s.register("jake")
+
+	w := s.do(http.MethodPost, "/api/articles", token, gin.H{"article": gin.H{
+		"title": "Tags", "description": "d", "body": "b", "tagList": []string{" Go ", "go", "Web  Dev"},
+	}})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create: %d %s", w.Code, w.Body)
+	}
+	article := func() (string, []string) {
+		t.Helper()
+		w := s.do(http.MethodGet, "/api/articles/tags", "", nil)
+		var resp struct {
+			Article struct {
+				Title   string   `json:"title"`
+				TagList []string `json:"tagList"`
+			} `json:"article"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("get: %d %s", w.Code, w.Body)
+		}
+		slices.Sort(resp.Article.TagList)
+		return resp.Article.Title, resp.Article.TagList
+	}
+	if _, tags := article(); !slices.Equal(tags, []string{"go", "web dev"}) {
+		t.Fatalf("tags after create = %v", tags)
+	}
+
+	// 1. 标签不合法时标题也不会被修改
+	tooMany := []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j", "k"}
+	w = s.do(http.MethodPut, "/api/articles/tags", token, gin.H{"article": gin.H{"title": "Changed", "tagList": tooMany}})
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("too many tags: %d %s", w.Code, w.Body)
+	}
+	if title, tags := article(); title != "Tags" || !slices.Equal(tags, []string{"go", "web dev"}) {
+		t.Errorf("after rejected update: title %q, tags %v", title, tags)
+	}
+
+	// 2. 整体替换；不传 tagList 不修改；空数组清空
+	w = s.do(http.MethodPut, "/api/articles/tags", token, gin.H{"article": gin.H{"tagList": []string{"GO", "Rust"}}})
+	if w.Code != http.StatusOK {
+		t.Fatalf("replace tags: %d %s", w.Code, w.Body)
+	}
+	if _, tags := article(); !slices.Equal(tags, []string{"go", "rust"}) {
+		t.Errorf("tags after replace = %v", tags)
+	}
+	s.do(http.MethodPut, "/api/articles/tags", token, gin.H{"article": gin.H{"body": "new body"}})
+	if _, tags := article(); !slices.Equal(tags, []string{"go", "rust"}) {
+		t.Errorf("tags after body update = %v", tags)
+	}
+	s.do(http.MethodPut, "/api/articles/tags", token, gin.H{"article": gin.H{"tagList": []string{}}})
+	if _, tags := article(); len(tags) != 0 {
+		t.Errorf("tags after clear = %v", tags)
+	}
+}
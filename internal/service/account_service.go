@@ -0,0 +1,50 @@
+package service
+
+import (
+	"context"
+	"github/CiroLong/realworld-gin/internal/model/dto"
+	"time"
+)
+
+// AccountService 密码找回 / 邮箱验证 / 免密登录等通过邮件链接完成的流程
+type AccountService interface {
+	// ForgotPassword 发送密码重置邮件；邮箱未注册时同样返回成功，避免被用来探测注册邮箱
+	ForgotPassword(ctx context.Context, req *dto.ForgotPasswordRequest) error
+
+	// ResetPassword 用邮件中的令牌重置密码，并撤销该用户的所有会话和个人访问令牌
+	ResetPassword(ctx context.Context, req *dto.ResetPasswordRequest) error
+
+	// SendVerificationEmail 给用户当前邮箱发送验证链接，已验证时什么也不做
+	SendVerificationEmail(ctx context.Context, userID int64) error
+
+	// VerifyEmail 用邮件中的令牌完成邮箱验证
+	VerifyEmail(ctx context.Context, req *dto.VerifyEmailRequest) error
+
+	// CheckEmailVerified 开启 RequireVerifiedEmail 时，邮箱未验证返回 common.ErrEmailNotVerified
+	CheckEmailVerified(ctx context.Context, userID int64) error
+
+	// SendMagicLink 发送免密登录链接；邮箱未注册或超过发送频率时同样返回成功，之前发出的链接保持有效
+	SendMagicLink(ctx context.Context, req *dto.MagicLinkRequest) error
+
+	// RedeemMagicLink 核销登录链接，返回用户 id；能收到邮件即证明拥有邮箱，顺便标记邮箱已验证
+	RedeemMagicLink(ctx context.Context, req *dto.RedeemMagicLinkRequest) (int64, error)
+
+	// ForcePasswordReset 管理员强制重置密码：原密码立即失效，撤销所有会话和个人访问令牌，并给用户发重置邮件
+	ForcePasswordReset(ctx context.Context, userID int64) error
+}
+
+// AccountConfig 邮件链接相关配置
+type AccountConfig struct {
+	// LinkBaseURL 邮件中链接指向的前端地址，如 https://example.com
+	LinkBaseURL      string
+	PasswordResetTTL time.Duration
+
+	EmailVerificationTTL time.Duration
+	// RequireVerifiedEmail 为 true 时，邮箱未验证的用户不能发文章和评论
+	RequireVerifiedEmail bool
+
+	MagicLinkTTL time.Duration
+	// MagicLinkRateLimit 每个邮箱在 MagicLinkRateWindow 内最多发送的登录链接数，0 表示不限制
+	MagicLinkRateLimit  int
+	MagicLinkRateWindow time.Duration
+}
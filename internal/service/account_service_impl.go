@@ -0,0 +1,312 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github/CiroLong/realworld-gin/internal/model/dto"
+	"github/CiroLong/realworld-gin/internal/model/entity"
+	"github/CiroLong/realworld-gin/internal/pkg/common"
+	"github/CiroLong/realworld-gin/internal/pkg/mailer"
+	"github/CiroLong/realworld-gin/internal/pkg/password"
+	"github/CiroLong/realworld-gin/internal/repository"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// mailTimeout 异步发信的超时时间
+const mailTimeout = 30 * time.Second
+
+type accountService struct {
+	userRepo    repository.UserRepo
+	sessionRepo repository.SessionRepo
+	patRepo     repository.PersonalAccessTokenRepo
+	tokens      *oneTimeTokens
+	hasher      password.Hasher
+	mailer      mailer.Mailer
+	cfg         AccountConfig
+}
+
+func NewAccountService(
+	userRepo repository.UserRepo,
+	sessionRepo repository.SessionRepo,
+	patRepo repository.PersonalAccessTokenRepo,
+	tokenRepo repository.OneTimeTokenRepo,
+	hasher password.Hasher,
+	mail mailer.Mailer,
+	cfg AccountConfig,
+) AccountService {
+	return &accountService{
+		userRepo:    userRepo,
+		sessionRepo: sessionRepo,
+		patRepo:     patRepo,
+		tokens:      newOneTimeTokens(tokenRepo),
+		hasher:      hasher,
+		mailer:      mail,
+		cfg:         cfg,
+	}
+}
+
+func (s *accountService) ForgotPassword(ctx context.Context, req *dto.ForgotPasswordRequest) error {
+	// 1. 查用户，不存在时直接返回成功
+	u, err := s.userRepo.FindByEmail(ctx, req.Email)
+	if err != nil {
+		if errors.Is(err, common.ErrUserNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	// 2. 签发重置令牌（之前发出的链接随之作废）
+	plain, err := s.tokens.issue(ctx, u.ID, entity.TokenPurposePasswordReset, s.cfg.PasswordResetTTL)
+	if err != nil {
+		return fmt.Errorf("issue reset token: %w", err)
+	}
+
+	// 3. 发邮件
+	s.sendAsync(mailer.Message{
+		To:      u.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\n"+
+				"Someone requested a password reset for your account. Open the link below to choose a new password:\n\n"+
+				"%s\n\n"+
+				"The link expires in %s and can only be used once. If you did not request this, you can ignore this email.\n",
+			u.Username, s.link("/reset-password", plain), s.cfg.PasswordResetTTL,
+		),
+	})
+	return nil
+}
+
+func (s *accountService) ResetPassword(ctx context.Context, req *dto.ResetPasswordRequest) error {
+	// 1. 核销令牌
+	ott, err := s.tokens.consume(ctx, entity.TokenPurposePasswordReset, req.Token)
+	if err != nil {
+		return err
+	}
+
+	// 2. 查用户
+	u, err := s.userRepo.FindByID(ctx, ott.UserID)
+	if err != nil {
+		if errors.Is(err, common.ErrUserNotFound) {
+			return common.ErrInvalidToken
+		}
+		return err
+	}
+
+	// 3. 更新密码
+	hashed, err := s.hasher.Hash(req.Password)
+	if err != nil {
+		return err
+	}
+	u.Password = hashed
+	if err := s.userRepo.Update(ctx, u); err != nil {
+		return fmt.Errorf("update password: %w", err)
+	}
+
+	// 4. 撤销所有会话和个人访问令牌，已登录的设备需要用新密码重新登录
+	// 令牌可能是泄露密码的人创建的，一并撤销，用户需要时重新创建
+	return s.revokeCredentials(ctx, u.ID)
+}
+
+func (s *accountService) SendVerificationEmail(ctx context.Context, userID int64) error {
+	// 1. 查用户
+	u, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if u.EmailVerifiedAt != nil {
+		return nil
+	}
+
+	// 2. 签发验证令牌（之前发出的链接随之作废，改邮箱后旧邮箱里的链接也就不能用了）
+	plain, err := s.tokens.issue(ctx, u.ID, entity.TokenPurposeEmailVerification, s.cfg.EmailVerificationTTL)
+	if err != nil {
+		return fmt.Errorf("issue verification token: %w", err)
+	}
+
+	// 3. 发邮件
+	s.sendAsync(mailer.Message{
+		To:      u.Email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\n"+
+				"Please confirm your email address by opening the link below:\n\n"+
+				"%s\n\n"+
+				"The link expires in %s. If you did not create an account, you can ignore this email.\n",
+			u.Username, s.link("/verify-email", plain), s.cfg.EmailVerificationTTL,
+		),
+	})
+	return nil
+}
+
+func (s *accountService) VerifyEmail(ctx context.Context, req *dto.VerifyEmailRequest) error {
+	// 1. 核销令牌
+	ott, err := s.tokens.consume(ctx, entity.TokenPurposeEmailVerification, req.Token)
+	if err != nil {
+		return err
+	}
+
+	// 2. 标记已验证
+	now := time.Now()
+	if err := s.userRepo.SetEmailVerified(ctx, ott.UserID, &now); err != nil {
+		if errors.Is(err, common.ErrUserNotFound) {
+			return common.ErrInvalidToken
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *accountService) CheckEmailVerified(ctx context.Context, userID int64) error {
+	if !s.cfg.RequireVerifiedEmail {
+		return nil
+	}
+
+	u, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if u.EmailVerifiedAt == nil {
+		return common.ErrEmailNotVerified
+	}
+	return nil
+}
+
+func (s *accountService) SendMagicLink(ctx context.Context, req *dto.MagicLinkRequest) error {
+	// 1. 查用户，不存在时直接返回成功（不通过登录链接注册）
+	u, err := s.userRepo.FindByEmail(ctx, req.Email)
+	if err != nil {
+		if errors.Is(err, common.ErrUserNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	// 2. 限流：超过频率时不再发信，也不签发新令牌，上一封邮件里的链接仍然可用
+	// 不返回错误，避免通过响应差异探测注册邮箱
+	if s.cfg.MagicLinkRateLimit > 0 {
+		count, err := s.tokens.issuedSince(ctx, u.ID, entity.TokenPurposeMagicLink, time.Now().Add(-s.cfg.MagicLinkRateWindow))
+		if err != nil {
+			return err
+		}
+		if count >= int64(s.cfg.MagicLinkRateLimit) {
+			log.Printf("magic link rate limit reached for user %d", u.ID)
+			return nil
+		}
+	}
+
+	// 3. 签发登录令牌（之前发出的链接随之作废）
+	plain, err := s.tokens.issue(ctx, u.ID, entity.TokenPurposeMagicLink, s.cfg.MagicLinkTTL)
+	if err != nil {
+		return fmt.Errorf("issue magic link token: %w", err)
+	}
+
+	// 4. 发邮件
+	s.sendAsync(mailer.Message{
+		To:      u.Email,
+		Subject: "Your sign-in link",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\n"+
+				"Open the link below to sign in:\n\n"+
+				"%s\n\n"+
+				"The link expires in %s and can only be used once. If you did not request it, you can ignore this email.\n",
+			u.Username, s.link("/magic-link", plain), s.cfg.MagicLinkTTL,
+		),
+	})
+	return nil
+}
+
+func (s *accountService) RedeemMagicLink(ctx context.Context, req *dto.RedeemMagicLinkRequest) (int64, error) {
+	// 1. 核销令牌
+	ott, err := s.tokens.consume(ctx, entity.TokenPurposeMagicLink, req.Token)
+	if err != nil {
+		return 0, err
+	}
+
+	// 2. 查用户
+	u, err := s.userRepo.FindByID(ctx, ott.UserID)
+	if err != nil {
+		if errors.Is(err, common.ErrUserNotFound) {
+			return 0, common.ErrInvalidToken
+		}
+		return 0, err
+	}
+
+	// 3. 链接是发到当前邮箱的，顺便完成邮箱验证
+	if u.EmailVerifiedAt == nil {
+		now := time.Now()
+		if err := s.userRepo.SetEmailVerified(ctx, u.ID, &now); err != nil {
+			return 0, err
+		}
+	}
+	return u.ID, nil
+}
+
+// ForcePasswordReset 清掉原密码、撤销所有凭证后发重置邮件，用户只能通过邮件里的链接设置新密码
+func (s *accountService) ForcePasswordReset(ctx context.Context, userID int64) error {
+	// 1. 查用户
+	u, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	// 2. 原密码作废，只能通过邮件里的链接设置新密码（第三方登录 / 免密登录不受影响）
+	u.Password = noPassword
+	if err := s.userRepo.Update(ctx, u); err != nil {
+		return fmt.Errorf("clear password: %w", err)
+	}
+
+	// 3. 撤销所有会话和个人访问令牌
+	if err := s.revokeCredentials(ctx, u.ID); err != nil {
+		return err
+	}
+
+	// 4. 签发重置令牌并发邮件
+	plain, err := s.tokens.issue(ctx, u.ID, entity.TokenPurposePasswordReset, s.cfg.PasswordResetTTL)
+	if err != nil {
+		return fmt.Errorf("issue reset token: %w", err)
+	}
+	s.sendAsync(mailer.Message{
+		To:      u.Email,
+		Subject: "Please choose a new password",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\n"+
+				"An administrator has reset the password of your account and signed you out everywhere. "+
+				"Open the link below to choose a new password:\n\n"+
+				"%s\n\n"+
+				"The link expires in %s. If it has expired, use \"Forgot password\" on the sign-in page to get a new one.\n",
+			u.Username, s.link("/reset-password", plain), s.cfg.PasswordResetTTL,
+		),
+	})
+	return nil
+}
+
+// revokeCredentials 撤销用户的所有会话和个人访问令牌
+func (s *accountService) revokeCredentials(ctx context.Context, userID int64) error {
+	if err := s.sessionRepo.RevokeUserSessions(ctx, userID); err != nil {
+		return fmt.Errorf("revoke sessions: %w", err)
+	}
+	if err := s.patRepo.RevokeUserTokens(ctx, userID); err != nil {
+		return fmt.Errorf("revoke personal access tokens: %w", err)
+	}
+	return nil
+}
+
+// link 拼接邮件中的前端链接
+func (s *accountService) link(path string, plain string) string {
+	return strings.TrimRight(s.cfg.LinkBaseURL, "/") + path + "?token=" + url.QueryEscape(plain)
+}
+
+// sendAsync 异步发信：接口耗时不因邮箱是否存在而不同，SMTP 变慢也不拖住请求
+func (s *accountService) sendAsync(msg mailer.Message) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), mailTimeout)
+		defer cancel()
+		if err := s.mailer.Send(ctx, msg); err != nil {
+			log.Printf("send mail %q to %s failed: %v", msg.Subject, msg.To, err)
+		}
+	}()
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github/CiroLong/realworld-gin/internal/model/dto"
+	"github/CiroLong/realworld-gin/internal/model/entity"
+	"github/CiroLong/realworld-gin/internal/pkg/auth"
+	"github/CiroLong/realworld-gin/internal/pkg/common"
+	"testing"
+	"time"
+)
+
+func TestAccountServiceResetPasswordRevokesCredentials(t *testing.T) {
+	tests := []struct {
+		name  string
+		reset func(s *testServices, userID int64) error
+	}{
+		{"reset with emailed token", func(s *testServices, userID int64) error {
+			// 相当于用户点开了 ForgotPassword 发出的邮件链接
+			plain, err := s.accounts.(*accountService).tokens.issue(context.Background(), userID, entity.TokenPurposePasswordReset, time.Hour)
+			if err != nil {
+				return err
+			}
+			req := &dto.ResetPasswordRequest{Token: plain, Password: "new-password"}
+			if err := s.accounts.ResetPassword(context.Background(), req); err != nil {
+				return err
+			}
+			// 令牌只能用一次
+			if err := s.accounts.ResetPassword(context.Background(), req); !errors.Is(err, common.ErrInvalidToken) {
+				s.t.Errorf("reuse reset token: %v", err)
+			}
+			return nil
+		}},
+		{"forced by admin", func(s *testServices, userID int64) error {
+			return s.accounts.ForcePasswordReset(context.Background(), userID)
+		}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s := newTestServices(t)
+			ctx := context.Background()
+			jake := s.register("jake")
+
+			// 1. 重置前会话和个人访问令牌都可用
+			var login dto.LoginRequest
+			login.User.Email, login.User.Password = "jake@example.com", "jake-password"
+			session, err := s.users.Login(ctx, &login, ClientMeta{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var create dto.CreateTokenRequest
+			create.Token.Name, create.Token.Scopes = "ci", []string{auth.ScopeRead}
+			pat, err := s.tokens.Create(ctx, jake, &create)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, raw := range []string{session.User.Token, pat.Token.Token} {
+				if _, err := s.authenticator.Authenticate(ctx, raw); err != nil {
+					t.Fatalf("before reset: %v", err)
+				}
+			}
+
+			// 2. 重置后都失效，令牌列表为空
+			if err := tc.reset(s, jake); err != nil {
+				t.Fatal(err)
+			}
+			for name, raw := range map[string]string{"session": session.User.Token, "personal access token": pat.Token.Token} {
+				if _, err := s.authenticator.Authenticate(ctx, raw); !errors.Is(err, common.ErrInvalidToken) {
+					t.Errorf("%s after reset: %v", name, err)
+				}
+			}
+			if list, err := s.tokens.List(ctx, jake); err != nil || len(list.Tokens) != 0 {
+				t.Errorf("tokens after reset: %+v, %v", list, err)
+			}
+
+			// 3. 原密码不能再登录
+			if _, err := s.users.Login(ctx, &login, ClientMeta{}); err == nil {
+				t.Error("login with old password succeeded")
+			}
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package service
+
+import (
+	"context"
+	"github/CiroLong/realworld-gin/internal/model/dto"
+)
+
+// AdminService 管理员的用户管理操作，adminID 为操作人，用于日志和禁止对自己 / 其他管理员的操作
+// 停用、强制重置密码和模拟登录不能作用于管理员（拥有 users:manage 权限的用户），返回 common.ErrAdminTarget
+type AdminService interface {
+	// ListUsers 按邮箱 / 用户名搜索用户，suspended 为 nil 时不按停用状态过滤
+	ListUsers(ctx context.Context, query string, suspended *bool, limit int, offset int) (*dto.AdminUserListResponse, error)
+
+	GetUser(ctx context.Context, userID int64) (*dto.AdminUserResponse, error)
+
+	// UpdateUser 修改邮箱 / 用户名，和已有用户冲突时返回 common.ErrUserAlreadyExist；不能修改管理员（common.ErrAdminTarget）
+	UpdateUser(ctx context.Context, adminID int64, userID int64, req *dto.AdminUpdateUserRequest) (*dto.AdminUserResponse, error)
+
+	// Suspend 停用账号并撤销所有会话，停用期间不能登录，个人访问令牌也不能使用
+	Suspend(ctx context.Context, adminID int64, userID int64, req *dto.SuspendUserRequest) (*dto.AdminUserResponse, error)
+
+	// Unsuspend 恢复账号；停用时撤销的会话不会恢复，用户需要重新登录
+	Unsuspend(ctx context.Context, adminID int64, userID int64) (*dto.AdminUserResponse, error)
+
+	// ForcePasswordReset 原密码失效并给用户发重置邮件
+	ForcePasswordReset(ctx context.Context, adminID int64, userID int64) error
+
+	// Impersonate 以用户身份登录，返回不能刷新的 access token；会话会出现在用户的会话列表里
+	Impersonate(ctx context.Context, adminID int64, userID int64, meta ClientMeta) (*dto.UserResponse, error)
+
+	// Unlock 解除登录失败导致的锁定
+	Unlock(ctx context.Context, adminID int64, userID int64) error
+}
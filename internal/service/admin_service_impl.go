@@ -0,0 +1,226 @@
+package service
+
+import (
+	"context"
+	"github/CiroLong/realworld-gin/internal/model/dto"
+	"github/CiroLong/realworld-gin/internal/model/entity"
+	"github/CiroLong/realworld-gin/internal/pkg/auth"
+	"github/CiroLong/realworld-gin/internal/pkg/common"
+	"github/CiroLong/realworld-gin/internal/pkg/jwt"
+	"github/CiroLong/realworld-gin/internal/repository"
+	"log"
+	"strings"
+	"time"
+)
+
+type adminService struct {
+	userRepo    repository.UserRepo
+	sessionRepo repository.SessionRepo
+	tokens      *tokenIssuer
+	accounts    AccountService
+	guard       LoginProtection
+	policy      Policy
+}
+
+func NewAdminService(
+	userRepo repository.UserRepo,
+	sessionRepo repository.SessionRepo,
+	jwtMgr jwt.Manager,
+	accounts AccountService,
+	guard LoginProtection,
+	policy Policy,
+) AdminService {
+	return &adminService{
+		userRepo:    userRepo,
+		sessionRepo: sessionRepo,
+		tokens:      newTokenIssuer(sessionRepo, jwtMgr, 0), // 只用于模拟登录，不签发 refresh token
+		accounts:    accounts,
+		guard:       guard,
+		policy:      policy,
+	}
+}
+
+func (s *adminService) ListUsers(ctx context.Context, query string, suspended *bool, limit int, offset int) (*dto.AdminUserListResponse, error) {
+	users, total, err := s.userRepo.List(ctx, repository.ListUsersFilter{
+		Query:     strings.TrimSpace(query),
+		Suspended: suspended,
+		Limit:     limit,
+		Offset:    offset,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &dto.AdminUserListResponse{Users: make([]dto.AdminUserDTO, 0, len(users)), UsersCount: total}
+	for _, u := range users {
+		resp.Users = append(resp.Users, toAdminUserDTO(u))
+	}
+	return resp, nil
+}
+
+func (s *adminService) GetUser(ctx context.Context, userID int64) (*dto.AdminUserResponse, error) {
+	u, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.AdminUserResponse{User: toAdminUserDTO(u)}, nil
+}
+
+func (s *adminService) UpdateUser(ctx context.Context, adminID int64, userID int64, req *dto.AdminUpdateUserRequest) (*dto.AdminUserResponse, error) {
+	// 1. 不能修改管理员的邮箱 / 用户名，否则可以借此接管另一个管理员的账号
+	u, err := s.target(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. 新邮箱 / 用户名不能被别人占用
+	if reservedAccount(stringValue(req.User.Email), stringValue(req.User.Username)) {
+		return nil, common.ErrUserAlreadyExist
+	}
+	emailChanged := false
+	if req.User.Email != nil && !strings.EqualFold(u.Email, *req.User.Email) {
+		if err := checkTaken(s.userRepo.FindByEmail(ctx, *req.User.Email)); err != nil {
+			return nil, err
+		}
+		u.Email = *req.User.Email
+		emailChanged = true
+	}
+	if req.User.Username != nil && !strings.EqualFold(u.Username, *req.User.Username) {
+		if err := checkTaken(s.userRepo.FindByUsername(ctx, *req.User.Username)); err != nil {
+			return nil, err
+		}
+		u.Username = *req.User.Username
+	}
+
+	// 3. 更新
+	if err := s.userRepo.Update(ctx, u); err != nil {
+		return nil, err
+	}
+	log.Printf("admin %d updated email/username of user %d", adminID, userID)
+
+	// 4. 换了邮箱需要重新验证
+	if emailChanged {
+		if err := s.userRepo.SetEmailVerified(ctx, u.ID, nil); err != nil {
+			return nil, err
+		}
+		u.EmailVerifiedAt = nil
+		if err := s.accounts.SendVerificationEmail(ctx, u.ID); err != nil {
+			log.Printf("send verification email to user %d failed: %v", u.ID, err)
+		}
+	}
+	return &dto.AdminUserResponse{User: toAdminUserDTO(u)}, nil
+}
+
+func (s *adminService) Suspend(ctx context.Context, adminID int64, userID int64, req *dto.SuspendUserRequest) (*dto.AdminUserResponse, error) {
+	// 1. 不能停用管理员
+	u, err := s.target(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. 标记停用，重复停用只更新原因
+	now := time.Now()
+	if u.SuspendedAt != nil {
+		now = *u.SuspendedAt
+	}
+	reason := strings.TrimSpace(req.Reason)
+	if err := s.userRepo.SetSuspended(ctx, u.ID, &now, reason); err != nil {
+		return nil, err
+	}
+	u.SuspendedAt, u.SuspendReason = &now, reason
+
+	// 3. 撤销所有会话，已签发的 access token 随即失效
+	if err := s.sessionRepo.RevokeUserSessions(ctx, u.ID); err != nil {
+		return nil, err
+	}
+	log.Printf("admin %d suspended user %d: %s", adminID, userID, reason)
+
+	return &dto.AdminUserResponse{User: toAdminUserDTO(u)}, nil
+}
+
+func (s *adminService) Unsuspend(ctx context.Context, adminID int64, userID int64) (*dto.AdminUserResponse, error) {
+	u, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.userRepo.SetSuspended(ctx, u.ID, nil, ""); err != nil {
+		return nil, err
+	}
+	u.SuspendedAt, u.SuspendReason = nil, ""
+	log.Printf("admin %d unsuspended user %d", adminID, userID)
+
+	return &dto.AdminUserResponse{User: toAdminUserDTO(u)}, nil
+}
+
+func (s *adminService) ForcePasswordReset(ctx context.Context, adminID int64, userID int64) error {
+	if _, err := s.target(ctx, userID); err != nil {
+		return err
+	}
+	if err := s.accounts.ForcePasswordReset(ctx, userID); err != nil {
+		return err
+	}
+	log.Printf("admin %d forced a password reset for user %d", adminID, userID)
+	return nil
+}
+
+func (s *adminService) Impersonate(ctx context.Context, adminID int64, userID int64, meta ClientMeta) (*dto.UserResponse, error) {
+	// 1. 不能模拟管理员（避免借此绕过权限），也不能模拟停用的账号
+	u, err := s.target(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if u.SuspendedAt != nil {
+		return nil, common.ErrAccountSuspended
+	}
+
+	// 2. 新建会话，只签发 access token
+	token, err := s.tokens.impersonate(ctx, u.ID, adminID, meta)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("admin %d is impersonating user %d", adminID, userID)
+
+	return newUserResponse(u, token, ""), nil
+}
+
+func (s *adminService) Unlock(ctx context.Context, adminID int64, userID int64) error {
+	u, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err := s.guard.Unlock(ctx, u.Email); err != nil {
+		return err
+	}
+	log.Printf("admin %d unlocked user %d", adminID, userID)
+	return nil
+}
+
+// target 查出被操作的用户，管理员返回 common.ErrAdminTarget
+func (s *adminService) target(ctx context.Context, userID int64) (*entity.User, error) {
+	u, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	isAdmin, err := s.policy.HasPermission(ctx, u.ID, auth.PermUsersManage)
+	if err != nil {
+		return nil, err
+	}
+	if isAdmin {
+		return nil, common.ErrAdminTarget
+	}
+	return u, nil
+}
+
+func toAdminUserDTO(u *entity.User) dto.AdminUserDTO {
+	return dto.AdminUserDTO{
+		ID:            u.ID,
+		Email:         u.Email,
+		Username:      u.Username,
+		Role:          roleOf(u),
+		EmailVerified: u.EmailVerifiedAt != nil,
+		Suspended:     u.SuspendedAt != nil,
+		SuspendedAt:   u.SuspendedAt,
+		SuspendReason: u.SuspendReason,
+		CreatedAt:     u.CreatedAt,
+	}
+}
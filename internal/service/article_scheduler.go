@@ -0,0 +1,21 @@
+package service
+
+import (
+	"context"
+	"time"
+)
+
+// ArticleScheduler 定时发布：在进程内定期把到点的 scheduled 文章发布出去
+// 多副本都运行时，由 repo 的条件更新保证每篇文章只被一个副本发布
+type ArticleScheduler interface {
+	// Run 阻塞运行直到 ctx 结束
+	Run(ctx context.Context)
+
+	// PublishDue 发布所有已到期的文章，返回发布的篇数
+	PublishDue(ctx context.Context) (int, error)
+}
+
+// ArticleSchedulerConfig Interval 为检查间隔，文章最多比计划时间晚这么久发布
+type ArticleSchedulerConfig struct {
+	Interval time.Duration
+}
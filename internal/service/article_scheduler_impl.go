@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"github/CiroLong/realworld-gin/internal/repository"
+	"log"
+	"time"
+)
+
+// scheduleBatchSize 每次从库里认领多少篇到期文章
+const scheduleBatchSize = 100
+
+type articleScheduler struct {
+	articleRepo repository.ArticleRepo
+	cfg         ArticleSchedulerConfig
+}
+
+func NewArticleScheduler(articleRepo repository.ArticleRepo, cfg ArticleSchedulerConfig) ArticleScheduler {
+	return &articleScheduler{
+		articleRepo: articleRepo,
+		cfg:         cfg,
+	}
+}
+
+func (s *articleScheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		// 启动时先跑一次，补上停机期间到期的文章
+		if _, err := s.PublishDue(ctx); err != nil {
+			log.Printf("publish scheduled articles failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *articleScheduler) PublishDue(ctx context.Context) (int, error) {
+	total := 0
+	for {
+		// 1. 认领一批到期文章，时间统一用 UTC 比较
+		published, err := s.articleRepo.PublishDue(ctx, time.Now().UTC(), scheduleBatchSize)
+		total += len(published)
+		for _, article := range published {
+			log.Printf("scheduled article %d (%s) published", article.ID, article.Slug)
+		}
+		if err != nil {
+			return total, err
+		}
+
+		// 2. 不满一批说明已经没有到期的了；满一批时可能被别的副本抢走了一部分，再查一次
+		if len(published) < scheduleBatchSize {
+			return total, nil
+		}
+	}
+}
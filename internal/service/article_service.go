@@ -7,14 +7,38 @@ import (
 
 type ArticleService interface {
 	CreateArticle(ctx context.Context, authorID int64, req *dto.CreateArticleRequest) (*dto.ArticleResponse, error)
-	GetArticle(ctx context.Context, slug string) (*dto.ArticleResponse, error)
-	UpdateArticle(ctx context.Context, slug string, userID int64, req *dto.UpdateArticleRequest) (*dto.ArticleResponse, error)
-	DeleteArticle(ctx context.Context, slug string, userID int64) error
+	GetArticle(ctx context.Context, slug string, userID int64) (*dto.ArticleResponse, error)
+	// UpdateArticle / DeleteArticle 的 version 为客户端 If-Match 带回的版本，和当前版本不一致返回 ErrVersionMismatch；0 表示不检查
+	UpdateArticle(ctx context.Context, slug string, userID int64, version int64, req *dto.UpdateArticleRequest) (*dto.ArticleResponse, error)
+	DeleteArticle(ctx context.Context, slug string, userID int64, version int64) error
+	// PublishArticle 发布草稿 / 不公开 / 归档的文章
+	PublishArticle(ctx context.Context, slug string, userID int64) (*dto.ArticleResponse, error)
 	FavoriteArticle(ctx context.Context, slug string, userID int64) (*dto.ArticleResponse, error)
 	UnfavoriteArticle(ctx context.Context, slug string, userID int64) (*dto.ArticleResponse, error)
 
-	ListArticles(ctx context.Context, tag string, author string, favorited string, userID int64, limit int, offset int) (*dto.MultipleArticlesResponse, error)
+	// ListArticles status 为空时只列出已发布的文章，其他状态只能列出自己的文章
+	ListArticles(ctx context.Context, tag string, author string, favorited string, status string, userID int64, limit int, offset int) (*dto.MultipleArticlesResponse, error)
 	FeedArticles(ctx context.Context, userID int64, limit int, offset int) (*dto.MultipleArticlesResponse, error)
+	// ScheduledArticles 当前用户等待定时发布的文章
+	ScheduledArticles(ctx context.Context, userID int64, limit int, offset int) (*dto.MultipleArticlesResponse, error)
 
 	ListTags(ctx context.Context) ([]string, error)
+
+	// ResolveSlug 文章改过 slug 时，旧 slug 对应的当前 slug；slug 就是某篇文章当前的 slug 时原样返回，
+	// 不是旧 slug 或文章不可见时返回 ErrNotFound
+	ResolveSlug(ctx context.Context, slug string, userID int64) (string, error)
+
+	// 修订历史，只有能编辑文章的人可以查看和恢复
+	ListRevisions(ctx context.Context, slug string, userID int64, limit int, offset int) (*dto.MultipleRevisionsResponse, error)
+	GetRevision(ctx context.Context, slug string, userID int64, revisionID int64) (*dto.RevisionResponse, error)
+	// DiffRevisions 从 fromID 到 toID 的 unified diff，fromID 为 0 时和上一条修订比较
+	DiffRevisions(ctx context.Context, slug string, userID int64, fromID int64, toID int64) (*dto.RevisionDiffResponse, error)
+	// RestoreRevision 把文章内容恢复成某条修订，并追加一条新修订
+	RestoreRevision(ctx context.Context, slug string, userID int64, revisionID int64) (*dto.ArticleResponse, error)
+}
+
+// ArticleConfig 文章相关配置
+type ArticleConfig struct {
+	// HideSuspendedAuthors 为 true 时文章列表和 Feed 不展示被停用账号的文章
+	HideSuspendedAuthors bool
 }
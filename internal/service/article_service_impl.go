@@ -3,26 +3,44 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
 	"github/CiroLong/realworld-gin/internal/model/dto"
 	"github/CiroLong/realworld-gin/internal/model/entity"
 	"github/CiroLong/realworld-gin/internal/pkg/common"
-	"github/CiroLong/realworld-gin/internal/pkg/utils"
+	"github/CiroLong/realworld-gin/internal/pkg/diff"
+	"github/CiroLong/realworld-gin/internal/pkg/slug"
+	"github/CiroLong/realworld-gin/internal/pkg/tagname"
 	"github/CiroLong/realworld-gin/internal/repository"
+	"slices"
+	"strings"
+	"time"
 )
 
 type articleService struct {
 	articleRepo repository.ArticleRepo
 	userRepo    repository.UserRepo
+	policy      Policy
+	slugs       *slug.Slugifier
+	cfg         ArticleConfig
 }
 
-func NewArticleService(articleRepo repository.ArticleRepo, userRepo repository.UserRepo) ArticleService {
+func NewArticleService(articleRepo repository.ArticleRepo, userRepo repository.UserRepo, policy Policy, slugs *slug.Slugifier, cfg ArticleConfig) ArticleService {
 	return &articleService{
 		articleRepo: articleRepo,
 		userRepo:    userRepo,
+		policy:      policy,
+		slugs:       slugs,
+		cfg:         cfg,
 	}
 }
 
 func (s articleService) CreateArticle(ctx context.Context, authorID int64, req *dto.CreateArticleRequest) (*dto.ArticleResponse, error) {
+	// 0. 标签先规范化和校验，不合法时什么都不创建
+	tagList, err := normalizeTags(req.Article.TagList)
+	if err != nil {
+		return nil, err
+	}
+
 	// 1. 生成 Article 实体
 	articleEntity := &entity.Article{
 		Title:       req.Article.Title,
@@ -30,104 +48,116 @@ func (s articleService) CreateArticle(ctx context.Context, authorID int64, req *
 		Body:        req.Article.Body,
 		AuthorID:    authorID,
 	}
-	// 2. 调用 Repo 创建文章（DB 操作全部 Repo 完成）
-	if err := s.articleRepo.Create(ctx, articleEntity); err != nil {
-		return nil, err
-	}
-
-	// 3. 处理 tag（Repo 提供接口）
-	var tags []*entity.Tag
-	if len(req.Article.TagList) > 0 {
-		var err error
-		tags, err = s.articleRepo.GetOrCreateTags(ctx, req.Article.TagList)
-		if err != nil {
-			return nil, err
+	// 默认直接发布，兼容 RealWorld 客户端；带了 publishAt 就是定时发布
+	if req.Article.PublishAt != nil {
+		if req.Article.Status != "" {
+			return nil, common.ErrPublishAtWithStatus
 		}
-
-		if err = s.articleRepo.ReplaceArticleTags(ctx, articleEntity.ID, tags); err != nil {
+		if err := schedule(articleEntity, *req.Article.PublishAt); err != nil {
 			return nil, err
 		}
+	} else {
+		status := req.Article.Status
+		if status == "" {
+			status = entity.ArticleStatusPublished
+		}
+		setStatus(articleEntity, status)
+	}
+	// 作者指定的 slug 直接使用，否则根据标题生成
+	if req.Article.Slug != "" {
+		err = s.customSlug(ctx, articleEntity, req.Article.Slug)
+	} else {
+		articleEntity.Slug, err = s.generateSlug(ctx, articleEntity.Title, 0)
 	}
-
-	// 4. 获取作者信息
-	author, err := s.userRepo.FindByID(ctx, authorID)
 	if err != nil {
-		return nil, errors.New("author not found")
+		return nil, err
 	}
 
-	// 5. 拼装 DTO（业务层逻辑）
-	authorDTO := dto.AuthorDTO{
-		Username:  author.Username,
-		Bio:       author.Bio,
-		Image:     author.Image,
-		Following: false, // 用户不可以关注自己
+	// 2. 文章、第一版修订和标签在同一个事务里写入；生成的 slug 在检查之后被别人抢先用了时重新生成一次
+	create := func() error {
+		return s.articleRepo.CreateWithRevisionAndTags(ctx, articleEntity, newRevision(articleEntity, authorID, time.Time{}), tagList)
 	}
-
-	tagNames := make([]string, len(tags))
-	for i, t := range tags {
-		tagNames[i] = t.Name
+	err = create()
+	if errors.Is(err, common.ErrSlugTaken) && !articleEntity.SlugLocked {
+		if articleEntity.Slug, err = s.generateSlug(ctx, articleEntity.Title, 0); err == nil {
+			err = create()
+		}
 	}
-
-	// 创建时没有收藏
-	return dto.NewArticleResponse(articleEntity, tagNames, authorDTO, false), nil
-}
-
-// GetArticle 获取单篇文章
-func (s articleService) GetArticle(ctx context.Context, slug string) (*dto.ArticleResponse, error) {
-	// 1. 获取文章
-	article, err := s.articleRepo.FindBySlug(ctx, slug)
 	if err != nil {
-		return nil, common.ErrNotFound
+		return nil, err
 	}
 
-	// 2. 获取作者
-	author, err := s.userRepo.FindByID(ctx, article.AuthorID)
+	// 3. 获取作者信息
+	author, err := s.userRepo.FindByID(ctx, authorID)
 	if err != nil {
 		return nil, errors.New("author not found")
 	}
 
+	// 4. 拼装 DTO（业务层逻辑）
 	authorDTO := dto.AuthorDTO{
 		Username:  author.Username,
 		Bio:       author.Bio,
 		Image:     author.Image,
-		Following: false, // TODO：GetArticle不一定带Auth
+		Following: false, // 用户不可以关注自己
 	}
 
-	// 3. 获取标签
-	tags, err := s.articleRepo.GetTagsByArticleID(ctx, article.ID)
-	if err != nil {
-		return nil, err
-	}
-	tagNames := make([]string, len(tags))
-	for i, t := range tags {
-		tagNames[i] = t.Name
-	}
+	// 标签已规范化，和写入的一致；创建时没有收藏
+	return dto.NewArticleResponse(articleEntity, tagList, authorDTO, false), nil
+}
 
-	// 4. 获取收藏信息
-	favorited := false
-	// TODO: 如果这里传入了userID应该进行查询
+// GetArticle 获取单篇文章，userID 为 0 表示未登录
+func (s articleService) GetArticle(ctx context.Context, slug string, userID int64) (*dto.ArticleResponse, error) {
+	// 1. 获取文章，看不到的文章当作不存在
+	article, err := s.articleRepo.FindBySlug(ctx, slug)
+	if err != nil || !canView(article, userID) {
+		return nil, common.ErrNotFound
+	}
 
-	// 5. 返回 DTO
-	return dto.NewArticleResponse(article, tagNames, authorDTO, favorited), nil
+	// 2. 拼装 DTO
+	return s.articleResponse(ctx, article, userID)
 }
 
 // UpdateArticle 更新
-func (s articleService) UpdateArticle(ctx context.Context, slug string, userID int64, req *dto.UpdateArticleRequest) (*dto.ArticleResponse, error) {
+func (s articleService) UpdateArticle(ctx context.Context, slug string, userID int64, version int64, req *dto.UpdateArticleRequest) (*dto.ArticleResponse, error) {
 	// 1. 查article
 	article, err := s.articleRepo.FindBySlug(ctx, slug)
-	if err != nil {
+	if err != nil || !canView(article, userID) {
 		return nil, common.ErrNotFound
 	}
 
-	// 无权限更新
-	if article.AuthorID != userID {
-		return nil, common.ErrPermissionDenied
+	// 作者本人或拥有 articles:update_any 权限
+	if err := s.policy.CanUpdateArticle(ctx, userID, article); err != nil {
+		return nil, err
+	}
+
+	// 客户端是基于旧版本修改的；读到之后又被别人改的情况由 repo 的条件更新兜底
+	if err := checkVersion(article.Version, version); err != nil {
+		return nil, err
+	}
+
+	// 标签先规范化和校验，不合法时什么都不改
+	var tagList []string
+	editTags := req.Article.TagList != nil || len(req.Article.AddTags) > 0 || len(req.Article.RemoveTags) > 0
+	if editTags {
+		if tagList, err = s.editedTags(ctx, article.ID, req); err != nil {
+			return nil, err
+		}
 	}
 
 	// 只更新非空字段
-	if req.Article.Title != "" {
-		article.Title = req.Article.Title
-		article.Slug = utils.GenerateSlug(req.Article.Title)
+	before := *article
+	if req.Article.Slug != "" {
+		if err := s.customSlug(ctx, article, req.Article.Slug); err != nil {
+			return nil, err
+		}
+	}
+	if req.Article.SlugLocked != nil {
+		article.SlugLocked = *req.Article.SlugLocked
+	}
+	if req.Article.Title != "" && req.Article.Title != article.Title {
+		if err := s.retitle(ctx, article, req.Article.Title); err != nil {
+			return nil, err
+		}
 	}
 	if req.Article.Description != "" {
 		article.Description = req.Article.Description
@@ -135,8 +165,34 @@ func (s articleService) UpdateArticle(ctx context.Context, slug string, userID i
 	if req.Article.Body != "" {
 		article.Body = req.Article.Body
 	}
+	if req.Article.PublishAt != nil {
+		if req.Article.Status != "" {
+			return nil, common.ErrPublishAtWithStatus
+		}
+		if article.Status == entity.ArticleStatusPublished {
+			return nil, common.ErrAlreadyPublished
+		}
+		if err := schedule(article, *req.Article.PublishAt); err != nil {
+			return nil, err
+		}
+	} else if req.Article.Status != "" {
+		setStatus(article, req.Article.Status)
+	}
 
-	if err := s.articleRepo.Update(ctx, article); err != nil {
+	// 内容有变化时记一条修订，只改状态不记；标签不记修订，修改了标签就整体替换，和文章在同一个事务里写入
+	var revision *entity.ArticleRevision
+	if contentChanged(&before, article) {
+		revision = newRevision(article, userID, time.Time{})
+	}
+	switch {
+	case editTags:
+		err = s.articleRepo.UpdateWithTags(ctx, article, revision, tagList)
+	case revision != nil:
+		err = s.articleRepo.UpdateWithRevision(ctx, article, revision)
+	default:
+		err = s.articleRepo.Update(ctx, article)
+	}
+	if err != nil {
 		return nil, err
 	}
 
@@ -165,23 +221,51 @@ func (s articleService) UpdateArticle(ctx context.Context, slug string, userID i
 }
 
 // DeleteArticle 删除
-func (s articleService) DeleteArticle(ctx context.Context, slug string, userID int64) error {
+func (s articleService) DeleteArticle(ctx context.Context, slug string, userID int64, version int64) error {
 	article, err := s.articleRepo.FindBySlug(ctx, slug)
-	if err != nil {
+	if err != nil || !canView(article, userID) {
 		return common.ErrNotFound
 	}
 
-	if article.AuthorID != userID {
-		return common.ErrPermissionDenied
+	// 作者本人或拥有 articles:delete_any 权限（如版主）
+	if err := s.policy.CanDeleteArticle(ctx, userID, article); err != nil {
+		return err
+	}
+	if err := checkVersion(article.Version, version); err != nil {
+		return err
+	}
+
+	return s.articleRepo.Delete(ctx, article.ID, article.Version)
+}
+
+// PublishArticle 发布
+func (s articleService) PublishArticle(ctx context.Context, slug string, userID int64) (*dto.ArticleResponse, error) {
+	// 1. 查文章，草稿只有作者能看到
+	article, err := s.articleRepo.FindBySlug(ctx, slug)
+	if err != nil || !canView(article, userID) {
+		return nil, common.ErrNotFound
+	}
+
+	// 2. 和编辑文章同样的权限
+	if err := s.policy.CanUpdateArticle(ctx, userID, article); err != nil {
+		return nil, err
 	}
 
-	return s.articleRepo.Delete(ctx, article.ID)
+	// 3. 已发布的文章重复发布什么也不做
+	if article.Status != entity.ArticleStatusPublished {
+		setStatus(article, entity.ArticleStatusPublished)
+		if err := s.articleRepo.Update(ctx, article); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.articleResponse(ctx, article, userID)
 }
 
 // FavoriteArticle 点赞
 func (s articleService) FavoriteArticle(ctx context.Context, slug string, userID int64) (*dto.ArticleResponse, error) {
 	article, err := s.articleRepo.FindBySlug(ctx, slug)
-	if err != nil {
+	if err != nil || !canView(article, userID) {
 		return nil, common.ErrNotFound
 	}
 
@@ -225,7 +309,7 @@ func (s articleService) FavoriteArticle(ctx context.Context, slug string, userID
 // UnfavoriteArticle 取消点赞
 func (s articleService) UnfavoriteArticle(ctx context.Context, slug string, userID int64) (*dto.ArticleResponse, error) {
 	article, err := s.articleRepo.FindBySlug(ctx, slug)
-	if err != nil {
+	if err != nil || !canView(article, userID) {
 		return nil, common.ErrNotFound
 	}
 	favorited, err := s.articleRepo.IsFavorited(ctx, userID, article.ID)
@@ -271,16 +355,19 @@ func (s articleService) ListArticles(
 	tag string,
 	author string,
 	favorited string,
+	status string,
 	userID int64,
 	limit int,
 	offset int,
 ) (*dto.MultipleArticlesResponse, error) {
 	// 1. 构造过滤条件
 	filter := repository.ListArticlesFilter{
-		Limit:  limit,
-		Offset: offset,
+		ExcludeSuspendedAuthors: s.cfg.HideSuspendedAuthors,
+		Limit:                   limit,
+		Offset:                  offset,
 	}
-	if tag != "" {
+	// 标签存储时已规范化，查询时同样处理，?tag=Go 和 ?tag=go 一样
+	if tag = tagname.Normalize(tag); tag != "" {
 		filter.Tag = &tag
 	}
 	if author != "" {
@@ -290,7 +377,23 @@ func (s articleService) ListArticles(
 		filter.FavoritedBy = &favorited
 	}
 
-	// 2. 查文章列表 + 总数
+	// 2. 未发布的文章只能列出自己的
+	if status != "" && status != entity.ArticleStatusPublished {
+		if !validStatus(status) {
+			return nil, common.ErrInvalidArticleStatus
+		}
+		if userID == 0 {
+			return nil, common.ErrPermissionDenied
+		}
+		viewer, err := s.userRepo.FindByID(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		filter.Author = &viewer.Username
+		filter.Statuses = []string{status}
+	}
+
+	// 3. 查文章列表 + 总数
 	articles, total, err := s.articleRepo.List(ctx, filter)
 	if err != nil {
 		return nil, err
@@ -303,19 +406,19 @@ func (s articleService) ListArticles(
 		}, nil
 	}
 
-	// 3. 批量准备 articleID
+	// 4. 批量准备 articleID
 	articleIDs := make([]int64, 0, len(articles))
 	for _, a := range articles {
 		articleIDs = append(articleIDs, a.ID)
 	}
 
-	// 4. 批量获取 tag
+	// 5. 批量获取 tag
 	tagsMap, err := s.articleRepo.GetTagsByArticleIDs(ctx, articleIDs)
 	if err != nil {
 		return nil, err
 	}
 
-	// 5. 拼 DTO
+	// 6. 拼 DTO
 	articleDTOs := make([]dto.ArticleWithoutBodyDTO, 0, len(articles))
 
 	for _, a := range articles {
@@ -333,22 +436,12 @@ func (s articleService) ListArticles(
 			favoritedFlag, _ = s.articleRepo.IsFavorited(ctx, userID, a.ID)
 		}
 
-		articleDTOs = append(articleDTOs, dto.ArticleWithoutBodyDTO{
-			Slug:           a.Slug,
-			Title:          a.Title,
-			Description:    a.Description,
-			TagList:        tagsMap[a.ID],
-			CreatedAt:      a.CreatedAt,
-			UpdatedAt:      a.UpdatedAt,
-			FavoritesCount: a.FavoritesCount,
-			Favorited:      favoritedFlag,
-			Author: dto.AuthorDTO{
-				Username:  authorEntity.Username,
-				Bio:       authorEntity.Bio,
-				Image:     authorEntity.Image,
-				Following: following,
-			},
-		})
+		articleDTOs = append(articleDTOs, dto.NewArticleWithoutBodyDTO(a, tagsMap[a.ID], dto.AuthorDTO{
+			Username:  authorEntity.Username,
+			Bio:       authorEntity.Bio,
+			Image:     authorEntity.Image,
+			Following: following,
+		}, favoritedFlag))
 	}
 
 	return &dto.MultipleArticlesResponse{
@@ -363,7 +456,11 @@ func (s articleService) FeedArticles(
 	limit int,
 	offset int,
 ) (*dto.MultipleArticlesResponse, error) {
-	articles, total, err := s.articleRepo.Feed(ctx, userID, limit, offset)
+	articles, total, err := s.articleRepo.Feed(ctx, userID, repository.FeedFilter{
+		ExcludeSuspendedAuthors: s.cfg.HideSuspendedAuthors,
+		Limit:                   limit,
+		Offset:                  offset,
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -398,22 +495,54 @@ func (s articleService) FeedArticles(
 			favoritedFlag, _ = s.articleRepo.IsFavorited(ctx, userID, a.ID)
 		}
 
-		articleDTOs = append(articleDTOs, dto.ArticleWithoutBodyDTO{
-			Slug:           a.Slug,
-			Title:          a.Title,
-			Description:    a.Description,
-			TagList:        tagsMap[a.ID],
-			CreatedAt:      a.CreatedAt,
-			UpdatedAt:      a.UpdatedAt,
-			Favorited:      favoritedFlag,
-			FavoritesCount: a.FavoritesCount,
-			Author: dto.AuthorDTO{
-				Username:  author.Username,
-				Bio:       author.Bio,
-				Image:     author.Image,
-				Following: true,
-			},
-		})
+		articleDTOs = append(articleDTOs, dto.NewArticleWithoutBodyDTO(a, tagsMap[a.ID], dto.AuthorDTO{
+			Username:  author.Username,
+			Bio:       author.Bio,
+			Image:     author.Image,
+			Following: true,
+		}, favoritedFlag))
+	}
+
+	return &dto.MultipleArticlesResponse{
+		Articles:      articleDTOs,
+		ArticlesCount: int(total),
+	}, nil
+}
+
+// ScheduledArticles 当前用户的定时发布文章，按发布时间先后排序
+func (s articleService) ScheduledArticles(ctx context.Context, userID int64, limit int, offset int) (*dto.MultipleArticlesResponse, error) {
+	// 1. 查文章
+	articles, total, err := s.articleRepo.ListScheduled(ctx, userID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. 作者都是自己
+	author, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	authorDTO := dto.AuthorDTO{
+		Username:  author.Username,
+		Bio:       author.Bio,
+		Image:     author.Image,
+		Following: false,
+	}
+
+	// 3. 批量获取 tag
+	articleIDs := make([]int64, 0, len(articles))
+	for _, a := range articles {
+		articleIDs = append(articleIDs, a.ID)
+	}
+	tagsMap, err := s.articleRepo.GetTagsByArticleIDs(ctx, articleIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	// 4. 拼 DTO，还没发布的文章不会被收藏
+	articleDTOs := make([]dto.ArticleWithoutBodyDTO, 0, len(articles))
+	for _, a := range articles {
+		articleDTOs = append(articleDTOs, dto.NewArticleWithoutBodyDTO(a, tagsMap[a.ID], authorDTO, false))
 	}
 
 	return &dto.MultipleArticlesResponse{
@@ -425,3 +554,381 @@ func (s articleService) FeedArticles(
 func (s articleService) ListTags(ctx context.Context) ([]string, error) {
 	return s.articleRepo.ListTags(ctx)
 }
+
+// ListRevisions 修订列表，新的在前
+func (s articleService) ListRevisions(ctx context.Context, slug string, userID int64, limit int, offset int) (*dto.MultipleRevisionsResponse, error) {
+	// 1. 查文章并校验权限
+	article, err := s.editableArticle(ctx, slug, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. 查修订
+	revisions, total, err := s.articleRepo.ListRevisions(ctx, article.ID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. 拼 DTO，同一个人的多次修改只查一次用户
+	editors := make(map[int64]string)
+	result := make([]dto.RevisionDTO, 0, len(revisions))
+	for _, r := range revisions {
+		result = append(result, s.revisionDTO(ctx, r, editors))
+	}
+
+	return &dto.MultipleRevisionsResponse{
+		Revisions:      result,
+		RevisionsCount: int(total),
+	}, nil
+}
+
+// GetRevision 单条修订的完整内容
+func (s articleService) GetRevision(ctx context.Context, slug string, userID int64, revisionID int64) (*dto.RevisionResponse, error) {
+	article, err := s.editableArticle(ctx, slug, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	revision, err := s.articleRepo.FindRevision(ctx, article.ID, revisionID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.RevisionResponse{Revision: s.revisionDTO(ctx, revision, map[int64]string{})}, nil
+}
+
+// DiffRevisions 两条修订之间的 unified diff
+func (s articleService) DiffRevisions(ctx context.Context, slug string, userID int64, fromID int64, toID int64) (*dto.RevisionDiffResponse, error) {
+	// 1. 查文章并校验权限
+	article, err := s.editableArticle(ctx, slug, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. 查两条修订，没指定 from 时取上一条，第一版和空内容比较
+	to, err := s.articleRepo.FindRevision(ctx, article.ID, toID)
+	if err != nil {
+		return nil, err
+	}
+	var from *entity.ArticleRevision
+	if fromID != 0 {
+		from, err = s.articleRepo.FindRevision(ctx, article.ID, fromID)
+	} else {
+		from, err = s.articleRepo.PreviousRevision(ctx, article.ID, toID)
+		if errors.Is(err, common.ErrNotFound) {
+			from, err = &entity.ArticleRevision{}, nil
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. 按行比较
+	return &dto.RevisionDiffResponse{
+		Diff: dto.RevisionDiffDTO{
+			From:    from.ID,
+			To:      to.ID,
+			Unified: diff.Unified(revisionLabel(from), revisionLabel(to), revisionText(from), revisionText(to)),
+		},
+	}, nil
+}
+
+// RestoreRevision 恢复旧版本：不改写历史，而是把旧内容作为一条新修订追加
+func (s articleService) RestoreRevision(ctx context.Context, slug string, userID int64, revisionID int64) (*dto.ArticleResponse, error) {
+	// 1. 查文章并校验权限
+	article, err := s.editableArticle(ctx, slug, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. 查要恢复的修订
+	revision, err := s.articleRepo.FindRevision(ctx, article.ID, revisionID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. 写回内容，标题变了和编辑时一样重新生成 slug
+	if article.Title != revision.Title {
+		if err := s.retitle(ctx, article, revision.Title); err != nil {
+			return nil, err
+		}
+	}
+	article.Description = revision.Description
+	article.Body = revision.Body
+
+	restored := newRevision(article, userID, time.Time{})
+	restored.RestoredFrom = revision.ID
+	if err := s.articleRepo.UpdateWithRevision(ctx, article, restored); err != nil {
+		return nil, err
+	}
+
+	return s.articleResponse(ctx, article, userID)
+}
+
+// ResolveSlug 旧 slug 对应文章当前的 slug，看不到的文章当作不存在
+// 绝大多数请求用的就是当前 slug，先按 slug 的唯一索引查，查不到时才去查 slug 历史
+func (s articleService) ResolveSlug(ctx context.Context, slug string, userID int64) (string, error) {
+	if _, err := s.articleRepo.FindBySlug(ctx, slug); err == nil {
+		return slug, nil
+	} else if !errors.Is(err, common.ErrNotFound) {
+		return "", err
+	}
+
+	article, err := s.articleRepo.FindByOldSlug(ctx, slug)
+	if err != nil || !canView(article, userID) {
+		return "", common.ErrNotFound
+	}
+	return article.Slug, nil
+}
+
+// customSlug 使用作者指定的 slug 并锁定，不能和其他文章当前或用过的 slug 重复（否则旧链接会指错文章）
+func (s articleService) customSlug(ctx context.Context, article *entity.Article, value string) error {
+	if value != article.Slug {
+		if !s.slugs.Valid(value) {
+			return common.ErrInvalidSlug
+		}
+		taken, err := s.articleRepo.SlugTaken(ctx, value, article.ID)
+		if err != nil {
+			return err
+		}
+		if taken {
+			return common.ErrSlugTaken
+		}
+		article.Slug = value
+	}
+	article.SlugLocked = true
+	return nil
+}
+
+// maxSlugAttempts 生成 slug 时最多尝试的次数
+const maxSlugAttempts = 5
+
+// generateSlug 由标题生成 slug：优先用标题转换的结果，是保留字或被占用（包括其他文章的旧 slug）时加随机后缀
+func (s articleService) generateSlug(ctx context.Context, title string, articleID int64) (string, error) {
+	base := s.slugs.Make(title)
+	candidate := base
+	for i := 0; i < maxSlugAttempts; i++ {
+		if !slug.Reserved(candidate) {
+			taken, err := s.articleRepo.SlugTaken(ctx, candidate, articleID)
+			if err != nil {
+				return "", err
+			}
+			if !taken {
+				return candidate, nil
+			}
+		}
+		candidate = s.slugs.WithSuffix(base)
+	}
+	return "", common.ErrSlugTaken
+}
+
+// retitle 修改标题，slug 没有锁定且新标题转换出的 slug 不同时重新生成 slug；旧 slug 由 repo 记入历史，旧链接仍然可用
+func (s articleService) retitle(ctx context.Context, article *entity.Article, title string) error {
+	old := article.Title
+	article.Title = title
+	if article.SlugLocked || s.slugs.Make(title) == s.slugs.Make(old) {
+		return nil
+	}
+	newSlug, err := s.generateSlug(ctx, title, article.ID)
+	if err != nil {
+		return err
+	}
+	article.Slug = newSlug
+	return nil
+}
+
+// editableArticle 查文章并要求当前用户能编辑它，看不到的文章当作不存在
+func (s articleService) editableArticle(ctx context.Context, slug string, userID int64) (*entity.Article, error) {
+	article, err := s.articleRepo.FindBySlug(ctx, slug)
+	if err != nil || !canView(article, userID) {
+		return nil, common.ErrNotFound
+	}
+	if err := s.policy.CanUpdateArticle(ctx, userID, article); err != nil {
+		return nil, err
+	}
+	return article, nil
+}
+
+// revisionDTO editors 缓存 editorID -> 用户名；用户已不存在时用户名为空
+func (s articleService) revisionDTO(ctx context.Context, r *entity.ArticleRevision, editors map[int64]string) dto.RevisionDTO {
+	editor, ok := editors[r.EditorID]
+	if !ok {
+		if u, err := s.userRepo.FindByID(ctx, r.EditorID); err == nil {
+			editor = u.Username
+		}
+		editors[r.EditorID] = editor
+	}
+	return dto.RevisionDTO{
+		ID:           r.ID,
+		Title:        r.Title,
+		Description:  r.Description,
+		Body:         r.Body,
+		Editor:       editor,
+		RestoredFrom: r.RestoredFrom,
+		CreatedAt:    r.CreatedAt,
+	}
+}
+
+// articleResponse 拼装单篇文章的响应，userID 为 0 时不查关注和收藏
+func (s articleService) articleResponse(ctx context.Context, article *entity.Article, userID int64) (*dto.ArticleResponse, error) {
+	// 1. 获取作者
+	author, err := s.userRepo.FindByID(ctx, article.AuthorID)
+	if err != nil {
+		return nil, errors.New("author not found")
+	}
+
+	// 2. 关注和收藏信息
+	following := false
+	favorited := false
+	if userID > 0 {
+		if userID != author.ID {
+			if following, err = s.userRepo.IsFollowing(ctx, userID, author.ID); err != nil {
+				return nil, err
+			}
+		}
+		if favorited, err = s.articleRepo.IsFavorited(ctx, userID, article.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	authorDTO := dto.AuthorDTO{
+		Username:  author.Username,
+		Bio:       author.Bio,
+		Image:     author.Image,
+		Following: following,
+	}
+
+	// 3. 获取标签
+	tags, err := s.articleRepo.GetTagsByArticleID(ctx, article.ID)
+	if err != nil {
+		return nil, err
+	}
+	tagNames := make([]string, len(tags))
+	for i, t := range tags {
+		tagNames[i] = t.Name
+	}
+
+	return dto.NewArticleResponse(article, tagNames, authorDTO, favorited), nil
+}
+
+// canView 草稿和归档的文章只有作者本人能看到，其他人（包括管理员）当作不存在
+func canView(article *entity.Article, userID int64) bool {
+	switch article.Status {
+	case entity.ArticleStatusPublished, entity.ArticleStatusUnlisted:
+		return true
+	}
+	return userID != 0 && article.AuthorID == userID
+}
+
+// setStatus 修改文章状态，第一次发布时记录发布时间；原来的定时发布随之取消
+func setStatus(article *entity.Article, status string) {
+	article.Status = status
+	article.PublishAt = nil
+	if status == entity.ArticleStatusPublished && article.PublishedAt == nil {
+		now := time.Now()
+		article.PublishedAt = &now
+	}
+}
+
+// schedule 定时发布，时间统一存 UTC；到点前和草稿一样只有作者可见
+func schedule(article *entity.Article, publishAt time.Time) error {
+	if !publishAt.After(time.Now()) {
+		return common.ErrPublishAtInPast
+	}
+	publishAt = publishAt.UTC()
+	article.Status = entity.ArticleStatusScheduled
+	article.PublishAt = &publishAt
+	return nil
+}
+
+// maxTagsPerArticle 每篇文章最多的标签数
+const maxTagsPerArticle = 10
+
+// normalizeTags 用 tagname.Normalize 规范化并去重，保持原来的顺序，空标签忽略
+func normalizeTags(names []string) ([]string, error) {
+	tags := make([]string, 0, len(names))
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		tag := tagname.Normalize(name)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		if !tagname.Valid(tag) {
+			return nil, common.ErrInvalidTag
+		}
+		seen[tag] = true
+		tags = append(tags, tag)
+	}
+	if len(tags) > maxTagsPerArticle {
+		return nil, common.ErrTooManyTags
+	}
+	return tags, nil
+}
+
+// editedTags 修改后文章的标签（已规范化）：以 tagList 或当前标签为基础，加上 addTags，再去掉 removeTags
+func (s articleService) editedTags(ctx context.Context, articleID int64, req *dto.UpdateArticleRequest) ([]string, error) {
+	var names []string
+	if req.Article.TagList != nil {
+		names = slices.Clone(*req.Article.TagList)
+	} else {
+		current, err := s.articleRepo.GetTagsByArticleID(ctx, articleID)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range current {
+			names = append(names, t.Name)
+		}
+	}
+	names = append(names, req.Article.AddTags...)
+
+	// 先去掉要删除的再校验，删掉标签时不会因为超出数量限制失败
+	removed := make(map[string]bool, len(req.Article.RemoveTags))
+	for _, name := range req.Article.RemoveTags {
+		removed[tagname.Normalize(name)] = true
+	}
+	names = slices.DeleteFunc(names, func(name string) bool {
+		return removed[tagname.Normalize(name)]
+	})
+	return normalizeTags(names)
+}
+
+// newRevision 用文章当前内容生成一条修订，createdAt 为零值时由 repo 填写
+func newRevision(article *entity.Article, editorID int64, createdAt time.Time) *entity.ArticleRevision {
+	return &entity.ArticleRevision{
+		ArticleID:   article.ID,
+		EditorID:    editorID,
+		Title:       article.Title,
+		Description: article.Description,
+		Body:        article.Body,
+		CreatedAt:   createdAt,
+	}
+}
+
+func contentChanged(before, after *entity.Article) bool {
+	return before.Title != after.Title || before.Description != after.Description || before.Body != after.Body
+}
+
+// revisionText 比较时把一条修订排成一份文本：标题、描述各一行，空一行后是正文
+func revisionText(r *entity.ArticleRevision) string {
+	if r.ID == 0 {
+		return ""
+	}
+	return "Title: " + r.Title + "\nDescription: " + r.Description + "\n\n" + strings.TrimSuffix(r.Body, "\n") + "\n"
+}
+
+// revisionLabel diff 文件头中的名字，和 diff -u 一样在 tab 后写时间
+func revisionLabel(r *entity.ArticleRevision) string {
+	if r.ID == 0 {
+		return "/dev/null"
+	}
+	return fmt.Sprintf("revision/%d\t%s", r.ID, r.CreatedAt.UTC().Format(time.RFC3339))
+}
+
+func validStatus(status string) bool {
+	for _, s := range entity.ArticleStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,340 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github/CiroLong/realworld-gin/internal/model/dto"
+	"github/CiroLong/realworld-gin/internal/model/entity"
+	"github/CiroLong/realworld-gin/internal/pkg/common"
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestArticleServiceCreateAndGet(t *testing.T) {
+	s := newTestServices(t)
+	ctx := context.Background()
+	jake := s.register("jake")
+	anna := s.register("anna")
+
+	// 1. slug 由标题生成，同名标题生成不同的 slug
+	first := s.createArticle(jake, "How to train your dragon", "", "Dragons", " training ")
+	second := s.createArticle(anna, "How to train your dragon", "")
+	if first != "how-to-train-your-dragon" || second == first {
+		t.Errorf("slugs = %q, %q", first, second)
+	}
+
+	// 2. 已发布的文章所有人可见，标签已规范化
+	for _, userID := range []int64{0, jake, anna} {
+		resp, err := s.articles.GetArticle(ctx, first, userID)
+		if err != nil {
+			t.Fatalf("get as %d: %v", userID, err)
+		}
+		tags := slices.Clone(resp.Article.TagList)
+		slices.Sort(tags)
+		if resp.Article.Author.Username != "jake" || !slices.Equal(tags, []string{"dragons", "training"}) {
+			t.Errorf("get as %d: %+v", userID, resp.Article)
+		}
+	}
+
+	// 3. 不存在的文章、别人的草稿都当作不存在
+	draft := s.createArticle(jake, "Draft", entity.ArticleStatusDraft)
+	if _, err := s.articles.GetArticle(ctx, "missing", jake); !errors.Is(err, common.ErrNotFound) {
+		t.Errorf("missing article: %v", err)
+	}
+	if _, err := s.articles.GetArticle(ctx, draft, anna); !errors.Is(err, common.ErrNotFound) {
+		t.Errorf("someone else's draft: %v", err)
+	}
+	if _, err := s.articles.GetArticle(ctx, draft, jake); err != nil {
+		t.Errorf("own draft: %v", err)
+	}
+
+	// 4. 自定义 slug 已被占用
+	var req dto.CreateArticleRequest
+	req.Article.Title, req.Article.Description, req.Article.Body, req.Article.Slug = "Other", "d", "b", first
+	if _, err := s.articles.CreateArticle(ctx, anna, &req); !errors.Is(err, common.ErrSlugTaken) {
+		t.Errorf("taken slug: %v", err)
+	}
+}
+
+func TestArticleServiceUpdateAndDelete(t *testing.T) {
+	s := newTestServices(t)
+	ctx := context.Background()
+	jake := s.register("jake")
+	anna := s.register("anna")
+	slug := s.createArticle(jake, "Original", "")
+
+	// 1. 只有作者可以修改和删除
+	var req dto.UpdateArticleRequest
+	req.Article.Body = "changed"
+	if _, err := s.articles.UpdateArticle(ctx, slug, anna, 0, &req); !errors.Is(err, common.ErrPermissionDenied) {
+		t.Errorf("update by another user: %v", err)
+	}
+	if err := s.articles.DeleteArticle(ctx, slug, anna, 0); !errors.Is(err, common.ErrPermissionDenied) {
+		t.Errorf("delete by another user: %v", err)
+	}
+
+	// 2. 版本不对时拒绝
+	resp, err := s.articles.UpdateArticle(ctx, slug, jake, 0, &req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Article.Body != "changed" {
+		t.Errorf("body = %q", resp.Article.Body)
+	}
+	if _, err := s.articles.UpdateArticle(ctx, slug, jake, resp.Article.Version-1, &req); !errors.Is(err, common.ErrVersionMismatch) {
+		t.Errorf("stale update: %v", err)
+	}
+
+	// 3. 作者删除后不再可见
+	if err := s.articles.DeleteArticle(ctx, slug, jake, resp.Article.Version); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.articles.GetArticle(ctx, slug, jake); !errors.Is(err, common.ErrNotFound) {
+		t.Errorf("get after delete: %v", err)
+	}
+	if err := s.articles.DeleteArticle(ctx, slug, jake, 0); !errors.Is(err, common.ErrNotFound) {
+		t.Errorf("delete twice: %v", err)
+	}
+}
+
+func TestArticleServiceResolveSlug(t *testing.T) {
+	s := newTestServices(t)
+	ctx := context.Background()
+	jake := s.register("jake")
+	anna := s.register("anna")
+	old := s.createArticle(jake, "Original", entity.ArticleStatusDraft)
+
+	var req dto.UpdateArticleRequest
+	req.Article.Slug = "renamed"
+	if _, err := s.articles.UpdateArticle(ctx, old, jake, 0, &req); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		slug   string
+		userID int64
+		want   string
+	}{
+		{"current slug", "renamed", jake, "renamed"},
+		{"old slug", old, jake, "renamed"},
+		{"old slug of someone else's draft", old, anna, ""},
+		{"unknown slug", "missing", jake, ""},
+	}
+	for _, tc := range tests {
+		got, err := s.articles.ResolveSlug(ctx, tc.slug, tc.userID)
+		if tc.want == "" {
+			if !errors.Is(err, common.ErrNotFound) {
+				t.Errorf("%s: got %q, %v; want ErrNotFound", tc.name, got, err)
+			}
+			continue
+		}
+		if err != nil || got != tc.want {
+			t.Errorf("%s: got %q, %v; want %q", tc.name, got, err, tc.want)
+		}
+	}
+}
+
+func TestArticleServiceUpdateTags(t *testing.T) {
+	s := newTestServices(t)
+	ctx := context.Background()
+	jake := s.register("jake")
+	slug := s.createArticle(jake, "Tagged", "", "go", "web")
+
+	update := func(edit func(req *dto.UpdateArticleRequest)) []string {
+		t.Helper()
+		var req dto.UpdateArticleRequest
+		edit(&req)
+		resp, err := s.articles.UpdateArticle(ctx, slug, jake, 0, &req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tags := slices.Clone(resp.Article.TagList)
+		slices.Sort(tags)
+		return tags
+	}
+
+	// 1. addTags 在当前标签的基础上增加，已有的不重复
+	tags := update(func(req *dto.UpdateArticleRequest) { req.Article.AddTags = []string{"Rust", "GO"} })
+	if !slices.Equal(tags, []string{"go", "rust", "web"}) {
+		t.Errorf("add: tags = %v", tags)
+	}
+
+	// 2. removeTags 按规范化后的名字去掉，同时出现在 addTags 中的也去掉
+	tags = update(func(req *dto.UpdateArticleRequest) {
+		req.Article.AddTags = []string{"db"}
+		req.Article.RemoveTags = []string{" WEB ", "db", "missing"}
+	})
+	if !slices.Equal(tags, []string{"go", "rust"}) {
+		t.Errorf("remove: tags = %v", tags)
+	}
+
+	// 3. 不动标签时保持原样
+	tags = update(func(req *dto.UpdateArticleRequest) { req.Article.Body = "changed" })
+	if !slices.Equal(tags, []string{"go", "rust"}) {
+		t.Errorf("unchanged: tags = %v", tags)
+	}
+
+	// 4. tagList 整体替换，空数组清空
+	tags = update(func(req *dto.UpdateArticleRequest) { req.Article.TagList = &[]string{"web"} })
+	if !slices.Equal(tags, []string{"web"}) {
+		t.Errorf("replace: tags = %v", tags)
+	}
+	tags = update(func(req *dto.UpdateArticleRequest) { req.Article.TagList = &[]string{} })
+	if len(tags) != 0 {
+		t.Errorf("clear: tags = %v", tags)
+	}
+
+	// 5. 超出数量限制时什么都不改
+	var req dto.UpdateArticleRequest
+	for i := range maxTagsPerArticle + 1 {
+		req.Article.AddTags = append(req.Article.AddTags, fmt.Sprintf("tag%d", i))
+	}
+	if _, err := s.articles.UpdateArticle(ctx, slug, jake, 0, &req); !errors.Is(err, common.ErrTooManyTags) {
+		t.Errorf("too many tags: %v", err)
+	}
+}
+
+func TestArticleServiceListAndFeed(t *testing.T) {
+	s := newTestServices(t)
+	ctx := context.Background()
+	jake := s.register("jake")
+	anna := s.register("anna")
+	bob := s.register("bob")
+
+	jakes := s.createArticle(jake, "Jake writes Go", "", "go")
+	annas := s.createArticle(anna, "Anna writes Go and Web", "", "go", "web")
+	s.createArticle(anna, "Anna's draft", entity.ArticleStatusDraft, "go")
+	if _, err := s.articles.FavoriteArticle(ctx, annas, bob); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.users.FollowUserByName(ctx, bob, "jake"); err != nil {
+		t.Fatal(err)
+	}
+
+	slugsOf := func(resp *dto.MultipleArticlesResponse) []string {
+		var slugs []string
+		for _, a := range resp.Articles {
+			slugs = append(slugs, a.Slug)
+		}
+		slices.Sort(slugs)
+		return slugs
+	}
+
+	// 1. 列表过滤：草稿不出现
+	tests := []struct {
+		name                   string
+		tag, author, favorited string
+		want                   []string
+	}{
+		{"all", "", "", "", []string{annas, jakes}},
+		{"tag", "GO", "", "", []string{annas, jakes}},
+		{"tag web", "web", "", "", []string{annas}},
+		{"author", "", "anna", "", []string{annas}},
+		{"favorited", "", "", "bob", []string{annas}},
+		{"no match", "rust", "", "", nil},
+	}
+	for _, tc := range tests {
+		resp, err := s.articles.ListArticles(ctx, tc.tag, tc.author, tc.favorited, "", bob, 20, 0)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if got := slugsOf(resp); !slices.Equal(got, tc.want) || resp.ArticlesCount != len(tc.want) {
+			t.Errorf("%s: %v (count %d), want %v", tc.name, got, resp.ArticlesCount, tc.want)
+		}
+	}
+
+	// 2. 按草稿状态只能列出自己的，未登录时拒绝
+	resp, err := s.articles.ListArticles(ctx, "", "anna", "", entity.ArticleStatusDraft, anna, 20, 0)
+	if err != nil || resp.ArticlesCount != 1 {
+		t.Errorf("own drafts: %+v, %v", resp, err)
+	}
+	if resp, err := s.articles.ListArticles(ctx, "", "anna", "", entity.ArticleStatusDraft, bob, 20, 0); err != nil || resp.ArticlesCount != 0 {
+		t.Errorf("someone else's drafts: %+v, %v", resp, err)
+	}
+	if _, err := s.articles.ListArticles(ctx, "", "", "", entity.ArticleStatusDraft, 0, 20, 0); !errors.Is(err, common.ErrPermissionDenied) {
+		t.Errorf("drafts without login: %v", err)
+	}
+
+	// 3. Feed 只有关注的作者的文章
+	feed, err := s.articles.FeedArticles(ctx, bob, 20, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := slugsOf(feed); !slices.Equal(got, []string{jakes}) {
+		t.Errorf("feed = %v", got)
+	}
+
+	// 4. 标签列表去重
+	tags, err := s.articles.ListTags(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	slices.Sort(tags)
+	if !slices.Equal(tags, []string{"go", "web"}) {
+		t.Errorf("tags = %v", tags)
+	}
+}
+
+func TestArticleServiceFavoriteConcurrently(t *testing.T) {
+	s := newTestServices(t)
+	ctx := context.Background()
+	jake := s.register("jake")
+	slug := s.createArticle(jake, "Popular", "")
+
+	// 多个用户同时收藏，每人各一次；重复收藏不重复计数
+	users := make([]int64, 8)
+	for i := range users {
+		users[i] = s.register(fmt.Sprintf("reader%d", i))
+	}
+	var wg sync.WaitGroup
+	for _, userID := range users {
+		for range 2 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if _, err := s.articles.FavoriteArticle(ctx, slug, userID); err != nil {
+					t.Error(err)
+				}
+			}()
+		}
+	}
+	wg.Wait()
+
+	resp, err := s.articles.GetArticle(ctx, slug, users[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Article.FavoritesCount != len(users) || !resp.Article.Favorited {
+		t.Errorf("favoritesCount = %d, favorited = %v", resp.Article.FavoritesCount, resp.Article.Favorited)
+	}
+
+	resp, err = s.articles.UnfavoriteArticle(ctx, slug, users[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Article.FavoritesCount != len(users)-1 || resp.Article.Favorited {
+		t.Errorf("after unfavorite: favoritesCount = %d, favorited = %v", resp.Article.FavoritesCount, resp.Article.Favorited)
+	}
+}
+
+func TestArticleServiceFavoriteHiddenArticle(t *testing.T) {
+	s := newTestServices(t)
+	ctx := context.Background()
+	jake := s.register("jake")
+	anna := s.register("anna")
+	draft := s.createArticle(jake, "Draft", entity.ArticleStatusDraft)
+
+	// 看不到的文章收藏和取消收藏都当作不存在，不泄露文章和作者信息
+	if _, err := s.articles.FavoriteArticle(ctx, draft, anna); !errors.Is(err, common.ErrNotFound) {
+		t.Errorf("favorite someone else's draft: %v", err)
+	}
+	if resp, err := s.articles.UnfavoriteArticle(ctx, draft, anna); !errors.Is(err, common.ErrNotFound) {
+		t.Errorf("unfavorite someone else's draft: %+v, %v", resp, err)
+	}
+	if _, err := s.articles.UnfavoriteArticle(ctx, draft, jake); err != nil {
+		t.Errorf("unfavorite own draft: %v", err)
+	}
+}
@@ -0,0 +1,7 @@
+package service
+
+// ClientMeta 发起请求的客户端信息，由 handler 从 HTTP 请求中取出
+type ClientMeta struct {
+	IP        string
+	UserAgent string
+}
@@ -12,25 +12,31 @@ type commentService struct {
 	commentRepo repository.CommentRepo
 	articleRepo repository.ArticleRepo
 	userRepo    repository.UserRepo
+	policy      Policy
 }
 
 func NewCommentService(commentRepo repository.CommentRepo,
 	articleRepo repository.ArticleRepo,
 	userRepo repository.UserRepo,
+	policy Policy,
 ) CommentService {
 	return &commentService{
 		commentRepo: commentRepo,
 		articleRepo: articleRepo,
 		userRepo:    userRepo,
+		policy:      policy,
 	}
 }
 
 func (c commentService) CreateComment(ctx context.Context, userID int64, slug string, req *dto.CreateCommentRequest) (*dto.SingleCommentResponse, error) {
-	// 1. 查文章
+	// 1. 查文章，看不到的文章当作不存在
 	article, err := c.articleRepo.FindBySlug(ctx, slug)
 	if err != nil {
 		return nil, err
 	}
+	if !canView(article, userID) {
+		return nil, common.ErrNotFound
+	}
 
 	// 2. 创建 Comment entity
 	comment := &entity.Comment{
@@ -72,11 +78,14 @@ func (c commentService) CreateComment(ctx context.Context, userID int64, slug st
 
 // userID == 0 时不用查following
 func (c commentService) GetComments(ctx context.Context, slug string, userID int64) (*dto.MultipleCommentsResponse, error) {
-	// 1. 查文章
+	// 1. 查文章，看不到的文章当作不存在
 	article, err := c.articleRepo.FindBySlug(ctx, slug)
 	if err != nil {
 		return nil, err
 	}
+	if !canView(article, userID) {
+		return nil, common.ErrNotFound
+	}
 
 	// 2. 查评论
 	comments, err := c.commentRepo.ListByArticle(ctx, article.ID)
@@ -131,9 +140,9 @@ func (c commentService) DeleteComment(ctx context.Context, userID int64, comment
 		return err
 	}
 
-	// 2. 权限校验
-	if comment.AuthorID != userID {
-		return common.ErrPermissionDenied
+	// 2. 权限校验：作者本人或拥有 comments:delete_any 权限（如版主）
+	if err := c.policy.CanDeleteComment(ctx, userID, comment); err != nil {
+		return err
 	}
 
 	// 3. 删除
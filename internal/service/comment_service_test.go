@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github/CiroLong/realworld-gin/internal/model/dto"
+	"github/CiroLong/realworld-gin/internal/model/entity"
+	"github/CiroLong/realworld-gin/internal/pkg/common"
+	"testing"
+)
+
+func TestCommentService(t *testing.T) {
+	s := newTestServices(t)
+	ctx := context.Background()
+	jake := s.register("jake")
+	anna := s.register("anna")
+	slug := s.createArticle(jake, "Commented", "")
+	draft := s.createArticle(jake, "Draft", entity.ArticleStatusDraft)
+
+	comment := func(userID int64, slug, body string) (*dto.SingleCommentResponse, error) {
+		var req dto.CreateCommentRequest
+		req.Comment.Body = body
+		return s.comments.CreateComment(ctx, userID, slug, &req)
+	}
+
+	// 1. 评论并列出
+	created, err := comment(anna, slug, "nice post")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.Comment.Author.Username != "anna" || created.Comment.Body != "nice post" {
+		t.Errorf("created = %+v", created.Comment)
+	}
+	list, err := s.comments.GetComments(ctx, slug, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Comments) != 1 || list.Comments[0].ID != created.Comment.ID {
+		t.Errorf("comments = %+v", list.Comments)
+	}
+
+	// 2. 不存在的文章和别人的草稿不能评论，也看不到评论
+	for _, target := range []string{"missing", draft} {
+		if _, err := comment(anna, target, "hi"); !errors.Is(err, common.ErrNotFound) {
+			t.Errorf("comment on %s: %v", target, err)
+		}
+		if _, err := s.comments.GetComments(ctx, target, anna); !errors.Is(err, common.ErrNotFound) {
+			t.Errorf("comments of %s: %v", target, err)
+		}
+	}
+
+	// 3. 只有评论的作者可以删除，文章作者也不行
+	if err := s.comments.DeleteComment(ctx, jake, created.Comment.ID); !errors.Is(err, common.ErrPermissionDenied) {
+		t.Errorf("delete by article author: %v", err)
+	}
+	if err := s.comments.DeleteComment(ctx, anna, created.Comment.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.comments.DeleteComment(ctx, anna, created.Comment.ID); !errors.Is(err, common.ErrNotFound) {
+		t.Errorf("delete twice: %v", err)
+	}
+	if list, err := s.comments.GetComments(ctx, slug, 0); err != nil || len(list.Comments) != 0 {
+		t.Errorf("comments after delete: %+v, %v", list, err)
+	}
+}
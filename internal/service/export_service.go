@@ -0,0 +1,31 @@
+package service
+
+import (
+	"context"
+	"github/CiroLong/realworld-gin/internal/model/dto"
+	"time"
+)
+
+// ExportConfig SyncLimit 为直接生成的数据条数上限（文章 + 评论 + 收藏），超过后在后台生成；
+// TTL 为后台生成的导出保留多久
+type ExportConfig struct {
+	SyncLimit int
+	TTL       time.Duration
+}
+
+// DataExportResult Archive 不为空时是生成好的 ZIP，否则 Status 为后台任务的状态，Pending 表示还在生成
+type DataExportResult struct {
+	Filename string
+	Archive  []byte
+	Status   *dto.DataExportResponse
+	Pending  bool
+}
+
+// ExportService 个人数据导出（GDPR），导出资料、文章、评论、收藏和关注关系
+type ExportService interface {
+	// Export 数据量不超过 SyncLimit 时直接返回 ZIP，否则创建后台任务（已有进行中的任务时复用）
+	Export(ctx context.Context, userID int64) (*DataExportResult, error)
+
+	// Get 查询后台任务，生成好后返回 ZIP；不存在、不属于该用户或已过期时返回 common.ErrNotFound
+	Get(ctx context.Context, userID int64, exportID int64) (*DataExportResult, error)
+}
@@ -0,0 +1,394 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github/CiroLong/realworld-gin/internal/model/dto"
+	"github/CiroLong/realworld-gin/internal/model/entity"
+	"github/CiroLong/realworld-gin/internal/pkg/common"
+	"github/CiroLong/realworld-gin/internal/repository"
+	"log"
+	"time"
+)
+
+const (
+	// exportPageSize 生成导出时每次从库里读多少条
+	exportPageSize = 100
+	// exportTimeout 后台生成的时限，超时仍是 pending 的任务（比如进程中途退出）视为失败
+	exportTimeout = 10 * time.Minute
+)
+
+// favoriteStatuses 收藏列表里只导出用户还能看到的文章
+var favoriteStatuses = []string{entity.ArticleStatusPublished, entity.ArticleStatusUnlisted}
+
+type exportService struct {
+	userRepo    repository.UserRepo
+	articleRepo repository.ArticleRepo
+	commentRepo repository.CommentRepo
+	exportRepo  repository.DataExportRepo
+	cfg         ExportConfig
+}
+
+func NewExportService(
+	userRepo repository.UserRepo,
+	articleRepo repository.ArticleRepo,
+	commentRepo repository.CommentRepo,
+	exportRepo repository.DataExportRepo,
+	cfg ExportConfig,
+) ExportService {
+	return &exportService{
+		userRepo:    userRepo,
+		articleRepo: articleRepo,
+		commentRepo: commentRepo,
+		exportRepo:  exportRepo,
+		cfg:         cfg,
+	}
+}
+
+func (s *exportService) Export(ctx context.Context, userID int64) (*DataExportResult, error) {
+	u, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 1. 数据量小直接生成
+	n, err := s.count(ctx, u)
+	if err != nil {
+		return nil, err
+	}
+	if n <= s.cfg.SyncLimit {
+		archive, err := s.build(ctx, u)
+		if err != nil {
+			return nil, err
+		}
+		return &DataExportResult{Filename: exportFilename(u), Archive: archive}, nil
+	}
+
+	// 2. 顺手清理过期的导出
+	now := time.Now()
+	if _, err := s.exportRepo.DeleteExpired(ctx, now); err != nil {
+		log.Printf("delete expired data exports failed: %v", err)
+	}
+
+	// 3. 已有进行中的任务时直接返回，避免重复生成
+	latest, err := s.exportRepo.FindLatestByUser(ctx, u.ID, now)
+	if err != nil && !errors.Is(err, common.ErrNotFound) {
+		return nil, err
+	}
+	if latest != nil && latest.Status == entity.ExportStatusPending && !exportStale(latest) {
+		return &DataExportResult{Status: toDataExportResponse(latest), Pending: true}, nil
+	}
+
+	// 4. 创建任务，后台生成
+	export := &entity.DataExport{
+		UserID:    u.ID,
+		Status:    entity.ExportStatusPending,
+		ExpiresAt: now.Add(s.cfg.TTL),
+	}
+	if err := s.exportRepo.Create(ctx, export); err != nil {
+		return nil, err
+	}
+	go s.run(export, u)
+
+	return &DataExportResult{Status: toDataExportResponse(export), Pending: true}, nil
+}
+
+func (s *exportService) Get(ctx context.Context, userID int64, exportID int64) (*DataExportResult, error) {
+	// 1. 只能查自己的、未过期的导出
+	export, err := s.exportRepo.FindByID(ctx, exportID)
+	if err != nil {
+		return nil, err
+	}
+	if export.UserID != userID || !export.ExpiresAt.After(time.Now()) {
+		return nil, common.ErrNotFound
+	}
+
+	// 2. 还没生成好
+	if export.Status != entity.ExportStatusReady {
+		if exportStale(export) {
+			export.Status = entity.ExportStatusFailed
+			export.Error = "export was interrupted, please request a new one"
+		}
+		return &DataExportResult{
+			Status:  toDataExportResponse(export),
+			Pending: export.Status == entity.ExportStatusPending,
+		}, nil
+	}
+
+	// 3. 返回 ZIP
+	u, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &DataExportResult{Filename: exportFilename(u), Archive: export.Data}, nil
+}
+
+// run 后台生成导出，结果写回任务
+func (s *exportService) run(export *entity.DataExport, u *entity.User) {
+	ctx, cancel := context.WithTimeout(context.Background(), exportTimeout)
+	defer cancel()
+
+	archive, err := s.build(ctx, u)
+	now := time.Now()
+	export.CompletedAt = &now
+	if err != nil {
+		log.Printf("data export %d of user %d failed: %v", export.ID, u.ID, err)
+		export.Status = entity.ExportStatusFailed
+		export.Error = "export failed, please request a new one"
+	} else {
+		export.Status = entity.ExportStatusReady
+		export.Data = archive
+	}
+
+	// 生成可能刚好用完了时限，写回结果用新的 context
+	finishCtx, finishCancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer finishCancel()
+	if err := s.exportRepo.Finish(finishCtx, export); err != nil {
+		log.Printf("save data export %d failed: %v", export.ID, err)
+	}
+}
+
+// count 需要导出的文章、评论和收藏条数，用于决定是否在后台生成
+func (s *exportService) count(ctx context.Context, u *entity.User) (int, error) {
+	_, articles, err := s.articleRepo.List(ctx, repository.ListArticlesFilter{Author: &u.Username, Statuses: entity.ArticleStatuses, Limit: 1})
+	if err != nil {
+		return 0, err
+	}
+	_, favorites, err := s.articleRepo.List(ctx, repository.ListArticlesFilter{FavoritedBy: &u.Username, Statuses: favoriteStatuses, Limit: 1})
+	if err != nil {
+		return 0, err
+	}
+	_, comments, err := s.commentRepo.ListByAuthor(ctx, u.ID, 1, 0)
+	if err != nil {
+		return 0, err
+	}
+	return int(articles + favorites + comments), nil
+}
+
+// build 生成 ZIP，每类数据一个 JSON 文件
+func (s *exportService) build(ctx context.Context, u *entity.User) ([]byte, error) {
+	articles, err := s.exportArticles(ctx, u)
+	if err != nil {
+		return nil, fmt.Errorf("export articles: %w", err)
+	}
+	comments, err := s.exportComments(ctx, u)
+	if err != nil {
+		return nil, fmt.Errorf("export comments: %w", err)
+	}
+	favorites, err := s.exportFavorites(ctx, u)
+	if err != nil {
+		return nil, fmt.Errorf("export favorites: %w", err)
+	}
+	follows, err := s.exportFollows(ctx, u)
+	if err != nil {
+		return nil, fmt.Errorf("export follows: %w", err)
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, file := range []struct {
+		name string
+		data interface{}
+	}{
+		{"profile.json", dto.ExportProfile{
+			Username:      u.Username,
+			Email:         u.Email,
+			Bio:           u.Bio,
+			Image:         u.Image,
+			Role:          roleOf(u),
+			EmailVerified: u.EmailVerifiedAt != nil,
+			CreatedAt:     u.CreatedAt,
+		}},
+		{"articles.json", articles},
+		{"comments.json", comments},
+		{"favorites.json", favorites},
+		{"follows.json", follows},
+	} {
+		w, err := zw.Create(file.name)
+		if err != nil {
+			return nil, err
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(file.data); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (s *exportService) exportArticles(ctx context.Context, u *entity.User) ([]dto.ExportArticle, error) {
+	result := make([]dto.ExportArticle, 0)
+	for offset := 0; ; {
+		articles, total, err := s.articleRepo.List(ctx, repository.ListArticlesFilter{
+			Author:   &u.Username,
+			Statuses: entity.ArticleStatuses, // 草稿、归档也是用户自己的数据
+			Limit:    exportPageSize,
+			Offset:   offset,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		ids := make([]int64, 0, len(articles))
+		for _, a := range articles {
+			ids = append(ids, a.ID)
+		}
+		tags, err := s.articleRepo.GetTagsByArticleIDs(ctx, ids)
+		if err != nil {
+			return nil, err
+		}
+		for _, a := range articles {
+			tagList := tags[a.ID]
+			if tagList == nil {
+				tagList = []string{}
+			}
+			result = append(result, dto.ExportArticle{
+				Slug:           a.Slug,
+				Title:          a.Title,
+				Description:    a.Description,
+				Body:           a.Body,
+				TagList:        tagList,
+				FavoritesCount: a.FavoritesCount,
+				Status:         a.Status,
+				PublishedAt:    a.PublishedAt,
+				CreatedAt:      a.CreatedAt,
+				UpdatedAt:      a.UpdatedAt,
+			})
+		}
+
+		offset += len(articles)
+		if len(articles) == 0 || int64(offset) >= total {
+			return result, nil
+		}
+	}
+}
+
+func (s *exportService) exportComments(ctx context.Context, u *entity.User) ([]dto.ExportComment, error) {
+	result := make([]dto.ExportComment, 0)
+	slugs := make(map[int64]string)
+	for offset := 0; ; {
+		comments, total, err := s.commentRepo.ListByAuthor(ctx, u.ID, exportPageSize, offset)
+		if err != nil {
+			return nil, err
+		}
+
+		// 评论所在文章的 slug，同一篇文章只查一次
+		var missing []int64
+		for _, c := range comments {
+			if _, ok := slugs[c.ArticleID]; !ok {
+				slugs[c.ArticleID] = ""
+				missing = append(missing, c.ArticleID)
+			}
+		}
+		articles, err := s.articleRepo.FindByIDs(ctx, missing)
+		if err != nil {
+			return nil, err
+		}
+		for _, a := range articles {
+			slugs[a.ID] = a.Slug
+		}
+
+		for _, c := range comments {
+			result = append(result, dto.ExportComment{
+				ID:        c.ID,
+				Article:   slugs[c.ArticleID],
+				Body:      c.Body,
+				CreatedAt: c.CreatedAt,
+			})
+		}
+
+		offset += len(comments)
+		if len(comments) == 0 || int64(offset) >= total {
+			return result, nil
+		}
+	}
+}
+
+func (s *exportService) exportFavorites(ctx context.Context, u *entity.User) ([]dto.ExportFavorite, error) {
+	result := make([]dto.ExportFavorite, 0)
+	authors := make(map[int64]string)
+	for offset := 0; ; {
+		articles, total, err := s.articleRepo.List(ctx, repository.ListArticlesFilter{
+			FavoritedBy: &u.Username,
+			Statuses:    favoriteStatuses,
+			Limit:       exportPageSize,
+			Offset:      offset,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, a := range articles {
+			author, ok := authors[a.AuthorID]
+			if !ok {
+				if au, err := s.userRepo.FindByID(ctx, a.AuthorID); err == nil {
+					author = au.Username
+				} else if !errors.Is(err, common.ErrUserNotFound) {
+					return nil, err
+				}
+				authors[a.AuthorID] = author
+			}
+			result = append(result, dto.ExportFavorite{
+				Slug:   a.Slug,
+				Title:  a.Title,
+				Author: author,
+			})
+		}
+
+		offset += len(articles)
+		if len(articles) == 0 || int64(offset) >= total {
+			return result, nil
+		}
+	}
+}
+
+func (s *exportService) exportFollows(ctx context.Context, u *entity.User) (*dto.ExportFollows, error) {
+	following, err := s.userRepo.ListFollowing(ctx, u.ID)
+	if err != nil {
+		return nil, err
+	}
+	followers, err := s.userRepo.ListFollowers(ctx, u.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.ExportFollows{
+		Following: usernames(following),
+		Followers: usernames(followers),
+	}, nil
+}
+
+// exportStale 超过时限仍未完成的任务
+func exportStale(export *entity.DataExport) bool {
+	return export.Status == entity.ExportStatusPending && time.Since(export.CreatedAt) > exportTimeout
+}
+
+func exportFilename(u *entity.User) string {
+	return fmt.Sprintf("realworld-export-%s-%s.zip", u.Username, time.Now().Format("20060102"))
+}
+
+func usernames(users []*entity.User) []string {
+	names := make([]string, 0, len(users))
+	for _, u := range users {
+		names = append(names, u.Username)
+	}
+	return names
+}
+
+func toDataExportResponse(export *entity.DataExport) *dto.DataExportResponse {
+	return &dto.DataExportResponse{Export: dto.DataExportDTO{
+		ID:          export.ID,
+		Status:      export.Status,
+		Error:       export.Error,
+		CreatedAt:   export.CreatedAt,
+		CompletedAt: export.CompletedAt,
+		ExpiresAt:   export.ExpiresAt,
+	}}
+}
@@ -0,0 +1,41 @@
+package service
+
+import (
+	"context"
+	"time"
+)
+
+// LoginProtection 登录防爆破：按账号和来源 IP 统计连续失败次数
+// 超过免费次数后按指数退避限制下一次尝试（429），账号连续失败达到阈值后临时锁定（423）
+type LoginProtection interface {
+	// Check 尝试登录前调用，处于退避或锁定期时返回 *common.RetryAfterError
+	Check(ctx context.Context, email string, ip string) error
+
+	// Failure 记录一次失败（密码或两步验证码错误）
+	Failure(ctx context.Context, email string, ip string)
+
+	// Success 登录成功，清空账号的失败计数
+	Success(ctx context.Context, email string)
+
+	// Unlock 管理员解锁账号并清空失败计数
+	Unlock(ctx context.Context, email string) error
+}
+
+// LoginProtectionConfig 防爆破配置
+type LoginProtectionConfig struct {
+	Enabled bool
+
+	// FreeAttempts 账号连续失败多少次之后开始退避，IPFreeAttempts 同理（同一 IP 可能对应很多正常用户，应设得更大）
+	FreeAttempts   int
+	IPFreeAttempts int
+	// 退避时间从 BaseDelay 开始每次翻倍，最多 MaxDelay
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+
+	// LockThreshold 账号连续失败达到这个次数后锁定 LockDuration，0 表示不锁定
+	LockThreshold int
+	LockDuration  time.Duration
+
+	// Window 超过这么久没有失败，计数重新开始
+	Window time.Duration
+}
@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github/CiroLong/realworld-gin/internal/model/entity"
+	"github/CiroLong/realworld-gin/internal/pkg/common"
+	"github/CiroLong/realworld-gin/internal/repository"
+	"log"
+	"strings"
+	"time"
+)
+
+type loginProtection struct {
+	repo repository.LoginAttemptRepo
+	cfg  LoginProtectionConfig
+}
+
+func NewLoginProtection(repo repository.LoginAttemptRepo, cfg LoginProtectionConfig) LoginProtection {
+	return &loginProtection{
+		repo: repo,
+		cfg:  cfg,
+	}
+}
+
+func (p *loginProtection) Check(ctx context.Context, email string, ip string) error {
+	if !p.cfg.Enabled {
+		return nil
+	}
+	now := time.Now()
+
+	// 1. 账号维度：锁定优先，其次是退避
+	account, err := p.get(ctx, accountSubject(email))
+	if err != nil {
+		return err
+	}
+	if account != nil && account.LockedUntil != nil && now.Before(*account.LockedUntil) {
+		return &common.RetryAfterError{Err: common.ErrAccountLocked, RetryAfter: account.LockedUntil.Sub(now)}
+	}
+	wait := p.backoff(account, p.cfg.FreeAttempts, now)
+
+	// 2. IP 维度只退避，不锁定
+	if ip != "" {
+		byIP, err := p.get(ctx, ipSubject(ip))
+		if err != nil {
+			return err
+		}
+		if w := p.backoff(byIP, p.cfg.IPFreeAttempts, now); w > wait {
+			wait = w
+		}
+	}
+
+	if wait > 0 {
+		return &common.RetryAfterError{Err: common.ErrTooManyAttempts, RetryAfter: wait}
+	}
+	return nil
+}
+
+func (p *loginProtection) Failure(ctx context.Context, email string, ip string) {
+	if !p.cfg.Enabled {
+		return
+	}
+	now := time.Now()
+
+	// 计数失败不影响登录结果本身，只记日志
+	account, err := p.record(ctx, accountSubject(email), now)
+	if err != nil {
+		log.Printf("record login failure for %s failed: %v", email, err)
+	} else if p.cfg.LockThreshold > 0 && account.Failures >= p.cfg.LockThreshold {
+		if err := p.repo.Lock(ctx, account.Subject, now.Add(p.cfg.LockDuration)); err != nil {
+			log.Printf("lock account %s failed: %v", email, err)
+		} else {
+			log.Printf("account %s locked for %s after %d failed logins", email, p.cfg.LockDuration, account.Failures)
+		}
+	}
+
+	if ip != "" {
+		if _, err := p.record(ctx, ipSubject(ip), now); err != nil {
+			log.Printf("record login failure for ip %s failed: %v", ip, err)
+		}
+	}
+}
+
+func (p *loginProtection) Success(ctx context.Context, email string) {
+	if !p.cfg.Enabled {
+		return
+	}
+	if err := p.repo.Reset(ctx, accountSubject(email)); err != nil {
+		log.Printf("reset login failures for %s failed: %v", email, err)
+	}
+}
+
+func (p *loginProtection) Unlock(ctx context.Context, email string) error {
+	return p.repo.Reset(ctx, accountSubject(email))
+}
+
+// get 查询计数，没有记录或已超出统计窗口时返回 nil
+func (p *loginProtection) get(ctx context.Context, subject string) (*entity.LoginAttempt, error) {
+	attempt, err := p.repo.Get(ctx, subject)
+	if errors.Is(err, common.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if p.expired(attempt, time.Now()) {
+		return nil, nil
+	}
+	return attempt, nil
+}
+
+// record 失败次数加一，超出统计窗口的旧计数先清零
+func (p *loginProtection) record(ctx context.Context, subject string, now time.Time) (*entity.LoginAttempt, error) {
+	attempt, err := p.repo.Get(ctx, subject)
+	if err != nil && !errors.Is(err, common.ErrNotFound) {
+		return nil, err
+	}
+	if attempt != nil && p.expired(attempt, now) {
+		if err := p.repo.Reset(ctx, subject); err != nil {
+			return nil, err
+		}
+	}
+	return p.repo.RecordFailure(ctx, subject, now)
+}
+
+// expired 超过统计窗口没有新的失败，且不在锁定期内
+func (p *loginProtection) expired(attempt *entity.LoginAttempt, now time.Time) bool {
+	if attempt.LockedUntil != nil && now.Before(*attempt.LockedUntil) {
+		return false
+	}
+	return p.cfg.Window > 0 && now.Sub(attempt.LastFailedAt) > p.cfg.Window
+}
+
+// backoff 计算还需要等待多久：超过 free 次之后，每多失败一次等待时间翻倍
+func (p *loginProtection) backoff(attempt *entity.LoginAttempt, free int, now time.Time) time.Duration {
+	if attempt == nil || attempt.Failures < free {
+		return 0
+	}
+
+	delay := p.cfg.BaseDelay
+	for i := free; i < attempt.Failures && delay < p.cfg.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > p.cfg.MaxDelay {
+		delay = p.cfg.MaxDelay
+	}
+
+	if wait := attempt.LastFailedAt.Add(delay).Sub(now); wait > 0 {
+		return wait
+	}
+	return 0
+}
+
+func accountSubject(email string) string {
+	return "account:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func ipSubject(ip string) string {
+	return "ip:" + ip
+}
@@ -0,0 +1,42 @@
+package service
+
+import (
+	"context"
+	"github/CiroLong/realworld-gin/internal/model/dto"
+	"time"
+)
+
+// MFAService TOTP 两步验证：绑定 / 确认 / 关闭 / 恢复码，以及登录时的第二步校验
+type MFAService interface {
+	// Status 两步验证状态
+	Status(ctx context.Context, userID int64) (*dto.MFAStatusResponse, error)
+
+	// Enroll 生成新密钥，确认前不生效；已启用时返回 common.ErrMFAAlreadyEnabled
+	Enroll(ctx context.Context, userID int64) (*dto.MFAEnrollResponse, error)
+
+	// Confirm 用认证器上的第一个验证码确认绑定，返回恢复码（只展示这一次）
+	Confirm(ctx context.Context, userID int64, req *dto.MFACodeRequest) (*dto.MFARecoveryCodesResponse, error)
+
+	// Disable 关闭两步验证，需要密码和验证码（或恢复码）；没有设置密码的账号只需要验证码
+	Disable(ctx context.Context, userID int64, req *dto.MFADisableRequest) error
+
+	// RegenerateRecoveryCodes 重新生成恢复码，旧的全部作废
+	RegenerateRecoveryCodes(ctx context.Context, userID int64, req *dto.MFACodeRequest) (*dto.MFARecoveryCodesResponse, error)
+
+	// Challenge 登录时密码已验证，若用户开启了两步验证则签发临时 token，否则返回 nil
+	Challenge(ctx context.Context, userID int64) (*dto.MFAChallenge, error)
+
+	// ParseChallenge 只校验临时 token，返回用户 id
+	ParseChallenge(mfaToken string) (int64, error)
+
+	// VerifyChallenge 校验临时 token 和验证码（或恢复码），返回用户 id
+	VerifyChallenge(ctx context.Context, mfaToken string, code string) (int64, error)
+}
+
+// MFAConfig 两步验证配置
+type MFAConfig struct {
+	// Issuer 显示在认证器 App 中的服务名
+	Issuer string
+	// PendingTTL 登录第二步的临时 token 有效期
+	PendingTTL time.Duration
+}
@@ -0,0 +1,299 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"github/CiroLong/realworld-gin/internal/model/dto"
+	"github/CiroLong/realworld-gin/internal/model/entity"
+	"github/CiroLong/realworld-gin/internal/pkg/common"
+	"github/CiroLong/realworld-gin/internal/pkg/jwt"
+	"github/CiroLong/realworld-gin/internal/pkg/password"
+	"github/CiroLong/realworld-gin/internal/pkg/secretbox"
+	"github/CiroLong/realworld-gin/internal/pkg/token"
+	"github/CiroLong/realworld-gin/internal/pkg/totp"
+	"github/CiroLong/realworld-gin/internal/repository"
+	"strings"
+	"time"
+)
+
+const (
+	// recoveryCodeCount 每次生成的恢复码数量
+	recoveryCodeCount = 10
+	// totpSkew 允许前后各一个时间步的时钟误差
+	totpSkew = 1
+)
+
+var recoveryCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+type mfaService struct {
+	mfaRepo  repository.MFARepo
+	userRepo repository.UserRepo
+	jwtMgr   jwt.Manager
+	hasher   password.Hasher
+	box      *secretbox.Box
+	cfg      MFAConfig
+}
+
+func NewMFAService(
+	mfaRepo repository.MFARepo,
+	userRepo repository.UserRepo,
+	jwtMgr jwt.Manager,
+	hasher password.Hasher,
+	box *secretbox.Box,
+	cfg MFAConfig,
+) MFAService {
+	return &mfaService{
+		mfaRepo:  mfaRepo,
+		userRepo: userRepo,
+		jwtMgr:   jwtMgr,
+		hasher:   hasher,
+		box:      box,
+		cfg:      cfg,
+	}
+}
+
+func (s *mfaService) Status(ctx context.Context, userID int64) (*dto.MFAStatusResponse, error) {
+	mfa, err := s.findEnabled(ctx, userID)
+	if errors.Is(err, common.ErrMFANotEnabled) {
+		return &dto.MFAStatusResponse{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	remaining, err := s.mfaRepo.CountRecoveryCodes(ctx, mfa.UserID)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.MFAStatusResponse{
+		TwoFactor: dto.MFAStatusDTO{Enabled: true, RecoveryCodesRemaining: remaining},
+	}, nil
+}
+
+func (s *mfaService) Enroll(ctx context.Context, userID int64) (*dto.MFAEnrollResponse, error) {
+	// 1. 已启用的不能直接覆盖，需要先关闭
+	if _, err := s.findEnabled(ctx, userID); err == nil {
+		return nil, common.ErrMFAAlreadyEnabled
+	} else if !errors.Is(err, common.ErrMFANotEnabled) {
+		return nil, err
+	}
+
+	u, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. 生成密钥，加密保存（覆盖之前未确认的密钥）
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := s.box.Seal(secret)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.mfaRepo.Save(ctx, &entity.UserMFA{UserID: userID, Secret: sealed}); err != nil {
+		return nil, fmt.Errorf("save mfa secret: %w", err)
+	}
+
+	// 3. 返回密钥和扫码地址
+	return &dto.MFAEnrollResponse{
+		TwoFactor: dto.MFAEnrollDTO{
+			Secret:     secret,
+			OtpauthURI: totp.URI(s.cfg.Issuer, u.Email, secret),
+		},
+	}, nil
+}
+
+func (s *mfaService) Confirm(ctx context.Context, userID int64, req *dto.MFACodeRequest) (*dto.MFARecoveryCodesResponse, error) {
+	// 1. 必须先 enroll
+	mfa, err := s.mfaRepo.FindByUserID(ctx, userID)
+	if errors.Is(err, common.ErrNotFound) {
+		return nil, common.ErrMFANotEnabled
+	}
+	if err != nil {
+		return nil, err
+	}
+	if mfa.EnabledAt != nil {
+		return nil, common.ErrMFAAlreadyEnabled
+	}
+
+	// 2. 校验验证码（此时还没有恢复码，只接受 TOTP）
+	if err := s.verifyTOTP(ctx, mfa, req.Code); err != nil {
+		return nil, err
+	}
+
+	// 3. 启用并生成恢复码
+	if err := s.mfaRepo.Enable(ctx, userID, time.Now()); err != nil {
+		return nil, err
+	}
+	return s.newRecoveryCodes(ctx, userID)
+}
+
+func (s *mfaService) Disable(ctx context.Context, userID int64, req *dto.MFADisableRequest) error {
+	// 1. 校验密码，没有设置密码的账号只凭验证码确认
+	u, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if u.Password != noPassword && !s.hasher.Verify(u.Password, req.Password) {
+		return common.ErrInvalidPassword
+	}
+
+	// 2. 校验验证码
+	mfa, err := s.findEnabled(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err := s.verifyCode(ctx, mfa, req.Code); err != nil {
+		return err
+	}
+
+	// 3. 删除密钥和恢复码
+	return s.mfaRepo.Delete(ctx, userID)
+}
+
+func (s *mfaService) RegenerateRecoveryCodes(ctx context.Context, userID int64, req *dto.MFACodeRequest) (*dto.MFARecoveryCodesResponse, error) {
+	mfa, err := s.findEnabled(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.verifyTOTP(ctx, mfa, req.Code); err != nil {
+		return nil, err
+	}
+	return s.newRecoveryCodes(ctx, userID)
+}
+
+func (s *mfaService) Challenge(ctx context.Context, userID int64) (*dto.MFAChallenge, error) {
+	if _, err := s.findEnabled(ctx, userID); err != nil {
+		if errors.Is(err, common.ErrMFANotEnabled) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	mfaToken, err := s.jwtMgr.GenerateMFAToken(userID, s.cfg.PendingTTL)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.MFAChallenge{
+		Token:     mfaToken,
+		ExpiresIn: int(s.cfg.PendingTTL / time.Second),
+	}, nil
+}
+
+func (s *mfaService) ParseChallenge(mfaToken string) (int64, error) {
+	claims, err := s.jwtMgr.ParseMFAToken(mfaToken)
+	if err != nil {
+		return 0, common.ErrInvalidToken
+	}
+	return claims.UserID, nil
+}
+
+func (s *mfaService) VerifyChallenge(ctx context.Context, mfaToken string, code string) (int64, error) {
+	// 1. 校验临时 token
+	userID, err := s.ParseChallenge(mfaToken)
+	if err != nil {
+		return 0, err
+	}
+
+	// 2. 校验验证码或恢复码
+	mfa, err := s.findEnabled(ctx, userID)
+	if err != nil {
+		if errors.Is(err, common.ErrMFANotEnabled) {
+			// 签发临时 token 之后用户关闭了两步验证
+			return 0, common.ErrInvalidToken
+		}
+		return 0, err
+	}
+	if err := s.verifyCode(ctx, mfa, code); err != nil {
+		return 0, err
+	}
+	return userID, nil
+}
+
+// findEnabled 查询已启用的两步验证配置，未绑定或未确认时返回 common.ErrMFANotEnabled
+func (s *mfaService) findEnabled(ctx context.Context, userID int64) (*entity.UserMFA, error) {
+	mfa, err := s.mfaRepo.FindByUserID(ctx, userID)
+	if errors.Is(err, common.ErrNotFound) {
+		return nil, common.ErrMFANotEnabled
+	}
+	if err != nil {
+		return nil, err
+	}
+	if mfa.EnabledAt == nil {
+		return nil, common.ErrMFANotEnabled
+	}
+	return mfa, nil
+}
+
+// verifyCode 6 位数字按 TOTP 校验，其余按恢复码校验
+func (s *mfaService) verifyCode(ctx context.Context, mfa *entity.UserMFA, code string) error {
+	code = strings.TrimSpace(code)
+	if len(code) == totp.Digits {
+		return s.verifyTOTP(ctx, mfa, code)
+	}
+
+	ok, err := s.mfaRepo.UseRecoveryCode(ctx, mfa.UserID, token.Hash(normalizeRecoveryCode(code)))
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return common.ErrInvalidMFACode
+	}
+	return nil
+}
+
+func (s *mfaService) verifyTOTP(ctx context.Context, mfa *entity.UserMFA, code string) error {
+	secret, err := s.box.Open(mfa.Secret)
+	if err != nil {
+		return fmt.Errorf("decrypt mfa secret: %w", err)
+	}
+
+	step, ok := totp.Validate(secret, code, time.Now(), totpSkew)
+	if !ok {
+		return common.ErrInvalidMFACode
+	}
+
+	// 同一个时间步（及更早）的验证码只能用一次
+	ok, err = s.mfaRepo.AdvanceStep(ctx, mfa.UserID, step)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return common.ErrInvalidMFACode
+	}
+	return nil
+}
+
+// newRecoveryCodes 生成一组新的恢复码，库中只存摘要
+func (s *mfaService) newRecoveryCodes(ctx context.Context, userID int64) (*dto.MFARecoveryCodesResponse, error) {
+	plain := make([]string, 0, recoveryCodeCount)
+	codes := make([]*entity.MFARecoveryCode, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		b := make([]byte, 5) // 40 bit，编码后 8 个字符
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+		code := strings.ToLower(recoveryCodeEncoding.EncodeToString(b))
+		plain = append(plain, code[:4]+"-"+code[4:])
+		codes = append(codes, &entity.MFARecoveryCode{
+			UserID:   userID,
+			CodeHash: token.Hash(code),
+		})
+	}
+
+	if err := s.mfaRepo.ReplaceRecoveryCodes(ctx, userID, codes); err != nil {
+		return nil, fmt.Errorf("save recovery codes: %w", err)
+	}
+	return &dto.MFARecoveryCodesResponse{RecoveryCodes: plain}, nil
+}
+
+// normalizeRecoveryCode 忽略大小写、空格和连字符
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github/CiroLong/realworld-gin/internal/model/dto"
+	"github/CiroLong/realworld-gin/internal/model/entity"
+	"github/CiroLong/realworld-gin/internal/pkg/common"
+	"github/CiroLong/realworld-gin/internal/pkg/totp"
+	"testing"
+	"time"
+)
+
+func TestMFAServiceDisable(t *testing.T) {
+	s := newTestServices(t)
+	ctx := context.Background()
+
+	// enable 启用两步验证，返回恢复码
+	enable := func(userID int64) []string {
+		t.Helper()
+		enroll, err := s.mfa.Enroll(ctx, userID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		code, err := totp.Code(enroll.TwoFactor.Secret, totp.Step(time.Now()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		codes, err := s.mfa.Confirm(ctx, userID, &dto.MFACodeRequest{Code: code})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return codes.RecoveryCodes
+	}
+
+	// 1. 有密码的账号必须带上正确的密码
+	jake := s.register("jake")
+	codes := enable(jake)
+	if err := s.mfa.Disable(ctx, jake, &dto.MFADisableRequest{Code: codes[0]}); !errors.Is(err, common.ErrInvalidPassword) {
+		t.Errorf("disable without password: err = %v", err)
+	}
+	if err := s.mfa.Disable(ctx, jake, &dto.MFADisableRequest{Password: "jake-password", Code: codes[0]}); err != nil {
+		t.Fatal(err)
+	}
+
+	// 2. 第三方登录创建的账号没有密码，只凭验证码关闭
+	oidcUser := &entity.User{Username: "anna", Email: "anna@example.com", Password: noPassword}
+	if err := s.userRepo.Create(ctx, oidcUser); err != nil {
+		t.Fatal(err)
+	}
+	codes = enable(oidcUser.ID)
+	if err := s.mfa.Disable(ctx, oidcUser.ID, &dto.MFADisableRequest{Code: "wrong"}); err == nil {
+		t.Error("disable with wrong code succeeded")
+	}
+	if err := s.mfa.Disable(ctx, oidcUser.ID, &dto.MFADisableRequest{Code: codes[0]}); err != nil {
+		t.Fatal(err)
+	}
+	status, err := s.mfa.Status(ctx, oidcUser.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.TwoFactor.Enabled {
+		t.Error("2fa still enabled")
+	}
+}
@@ -0,0 +1,44 @@
+package service
+
+import (
+	"context"
+	"github/CiroLong/realworld-gin/internal/model/dto"
+	"github/CiroLong/realworld-gin/internal/pkg/oidc"
+	"time"
+)
+
+// OIDCService 第三方登录（OpenID Connect 授权码 + PKCE）以及第三方账号的绑定 / 解绑
+type OIDCService interface {
+	// Providers 已配置的 provider
+	Providers() *dto.OIDCProvidersResponse
+
+	// Authorize 生成跳转到 provider 授权页面的地址；userID 为 0 表示登录，否则表示为该用户绑定
+	Authorize(ctx context.Context, provider string, userID int64) (*dto.OIDCAuthorizeResponse, error)
+
+	// Login 登录回调：已绑定的账号直接登录，未绑定且邮箱未被占用时自动注册
+	// 邮箱已被其他账号使用时返回 common.ErrOIDCEmailInUse，开启了两步验证时只返回 MFA 临时 token
+	Login(ctx context.Context, provider string, req *dto.OIDCCallbackRequest, meta ClientMeta) (*dto.LoginResponse, error)
+
+	// Link 绑定回调，第三方账号已被绑定时返回 common.ErrIdentityAlreadyLinked
+	Link(ctx context.Context, userID int64, provider string, req *dto.OIDCCallbackRequest) (*dto.IdentityResponse, error)
+
+	// ListIdentities 用户绑定的第三方账号
+	ListIdentities(ctx context.Context, userID int64) (*dto.IdentityListResponse, error)
+
+	// Unlink 解绑；没有设置密码的账号不能解绑最后一个第三方账号，返回 common.ErrLastLoginMethod
+	Unlink(ctx context.Context, userID int64, provider string) error
+}
+
+// OIDCConfig 第三方登录配置
+type OIDCConfig struct {
+	// StateTTL 跳转到授权页面后完成登录的时限
+	StateTTL time.Duration
+	// Providers 按 Name 出现在 URL 中
+	Providers []OIDCProviderConfig
+}
+
+type OIDCProviderConfig struct {
+	Name        string
+	DisplayName string
+	oidc.Config
+}
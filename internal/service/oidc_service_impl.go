@@ -0,0 +1,348 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github/CiroLong/realworld-gin/internal/model/dto"
+	"github/CiroLong/realworld-gin/internal/model/entity"
+	"github/CiroLong/realworld-gin/internal/pkg/common"
+	"github/CiroLong/realworld-gin/internal/pkg/jwt"
+	"github/CiroLong/realworld-gin/internal/pkg/oidc"
+	"github/CiroLong/realworld-gin/internal/pkg/token"
+	"github/CiroLong/realworld-gin/internal/pkg/utils"
+	"github/CiroLong/realworld-gin/internal/repository"
+	"log"
+	"strings"
+	"time"
+)
+
+const (
+	// noPassword 通过第三方登录创建、还没有设置密码的账号，任何密码都不会与之匹配
+	// 用户可以通过找回密码流程设置密码
+	noPassword = "!"
+	// usernameAttempts 自动注册时生成不重复用户名的尝试次数
+	usernameAttempts = 5
+)
+
+type oidcService struct {
+	identityRepo repository.IdentityRepo
+	userRepo     repository.UserRepo
+	tokens       *tokenIssuer
+	accounts     AccountService
+	mfa          MFAService
+	providers    map[string]*oidcProvider
+	names        []string // 保持配置顺序
+	stateTTL     time.Duration
+}
+
+type oidcProvider struct {
+	displayName string
+	client      *oidc.Provider
+}
+
+func NewOIDCService(
+	identityRepo repository.IdentityRepo,
+	userRepo repository.UserRepo,
+	sessionRepo repository.SessionRepo,
+	jwtMgr jwt.Manager,
+	refreshTTL time.Duration,
+	accounts AccountService,
+	mfa MFAService,
+	cfg OIDCConfig,
+) OIDCService {
+	s := &oidcService{
+		identityRepo: identityRepo,
+		userRepo:     userRepo,
+		tokens:       newTokenIssuer(sessionRepo, jwtMgr, refreshTTL),
+		accounts:     accounts,
+		mfa:          mfa,
+		providers:    make(map[string]*oidcProvider, len(cfg.Providers)),
+		stateTTL:     cfg.StateTTL,
+	}
+	for _, p := range cfg.Providers {
+		s.providers[p.Name] = &oidcProvider{
+			displayName: p.DisplayName,
+			client:      oidc.NewProvider(p.Config),
+		}
+		s.names = append(s.names, p.Name)
+	}
+	return s
+}
+
+func (s *oidcService) Providers() *dto.OIDCProvidersResponse {
+	resp := &dto.OIDCProvidersResponse{Providers: make([]dto.OIDCProviderDTO, 0, len(s.names))}
+	for _, name := range s.names {
+		resp.Providers = append(resp.Providers, dto.OIDCProviderDTO{
+			Name:        name,
+			DisplayName: s.providers[name].displayName,
+		})
+	}
+	return resp
+}
+
+func (s *oidcService) Authorize(ctx context.Context, provider string, userID int64) (*dto.OIDCAuthorizeResponse, error) {
+	p, ok := s.providers[provider]
+	if !ok {
+		return nil, common.ErrUnknownProvider
+	}
+
+	// 1. state 防 CSRF，nonce 绑定 ID token，verifier 用于 PKCE
+	state, stateHash, err := token.Generate()
+	if err != nil {
+		return nil, err
+	}
+	nonce, _, err := token.Generate()
+	if err != nil {
+		return nil, err
+	}
+	verifier, err := oidc.NewVerifier()
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. 拼授权地址（第一次使用时会做端点发现）
+	authURL, err := p.client.AuthCodeURL(ctx, state, nonce, verifier)
+	if err != nil {
+		log.Printf("oidc provider %s: %v", provider, err)
+		return nil, common.ErrOIDCLoginFailed
+	}
+
+	// 3. 保存上下文，回调时取出
+	if err := s.identityRepo.CreateState(ctx, &entity.OIDCState{
+		StateHash:    stateHash,
+		Provider:     provider,
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		UserID:       userID,
+		ExpiresAt:    time.Now().Add(s.stateTTL),
+	}); err != nil {
+		return nil, err
+	}
+
+	return &dto.OIDCAuthorizeResponse{
+		OIDC: dto.OIDCAuthorizeDTO{AuthorizationURL: authURL, State: state},
+	}, nil
+}
+
+func (s *oidcService) Login(ctx context.Context, provider string, req *dto.OIDCCallbackRequest, meta ClientMeta) (*dto.LoginResponse, error) {
+	// 1. 校验 state，换 token，校验 ID token
+	claims, err := s.callback(ctx, provider, 0, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. 已绑定的直接登录，否则自动注册
+	var u *entity.User
+	identity, err := s.identityRepo.FindByProviderSubject(ctx, provider, claims.Subject)
+	switch {
+	case err == nil:
+		u, err = s.userRepo.FindByID(ctx, identity.UserID)
+		if err != nil {
+			return nil, err
+		}
+	case errors.Is(err, common.ErrNotFound):
+		u, err = s.register(ctx, provider, claims)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, err
+	}
+
+	// 3. 开启了两步验证：和密码登录一样只返回临时 token
+	challenge, err := s.mfa.Challenge(ctx, u.ID)
+	if err != nil {
+		return nil, err
+	}
+	if challenge != nil {
+		return &dto.LoginResponse{MFA: challenge}, nil
+	}
+
+	// 4. 新建会话，签发 access / refresh token
+	tokens, err := s.tokens.issue(ctx, u, meta)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.LoginResponse{UserResponse: newUserResponse(u, tokens.AccessToken, tokens.RefreshToken)}, nil
+}
+
+func (s *oidcService) Link(ctx context.Context, userID int64, provider string, req *dto.OIDCCallbackRequest) (*dto.IdentityResponse, error) {
+	claims, err := s.callback(ctx, provider, userID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	identity := &entity.UserIdentity{
+		UserID:   userID,
+		Provider: provider,
+		Subject:  claims.Subject,
+		Email:    claims.Email,
+	}
+	if err := s.identityRepo.Create(ctx, identity); err != nil {
+		return nil, err
+	}
+	return &dto.IdentityResponse{Identity: toIdentityDTO(identity)}, nil
+}
+
+func (s *oidcService) ListIdentities(ctx context.Context, userID int64) (*dto.IdentityListResponse, error) {
+	identities, err := s.identityRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &dto.IdentityListResponse{Identities: make([]dto.IdentityDTO, 0, len(identities))}
+	for _, identity := range identities {
+		resp.Identities = append(resp.Identities, toIdentityDTO(identity))
+	}
+	return resp, nil
+}
+
+func (s *oidcService) Unlink(ctx context.Context, userID int64, provider string) error {
+	// 没有密码的账号至少要保留一个第三方账号，否则再也登录不了
+	u, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if u.Password == noPassword {
+		identities, err := s.identityRepo.ListByUser(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if len(identities) == 1 && identities[0].Provider == provider {
+			return common.ErrLastLoginMethod
+		}
+	}
+
+	return s.identityRepo.Delete(ctx, userID, provider)
+}
+
+// callback 取出并校验 state（只能用一次，provider 和发起人必须一致），再用 code 换取并校验 ID token
+func (s *oidcService) callback(ctx context.Context, provider string, userID int64, req *dto.OIDCCallbackRequest) (*oidc.Claims, error) {
+	p, ok := s.providers[provider]
+	if !ok {
+		return nil, common.ErrUnknownProvider
+	}
+
+	state, err := s.identityRepo.ConsumeState(ctx, token.Hash(req.State), time.Now())
+	if err != nil {
+		if errors.Is(err, common.ErrNotFound) {
+			return nil, common.ErrInvalidToken
+		}
+		return nil, err
+	}
+	if state.Provider != provider || state.UserID != userID {
+		return nil, common.ErrInvalidToken
+	}
+
+	claims, err := p.client.Exchange(ctx, req.Code, state.CodeVerifier, state.Nonce)
+	if err != nil {
+		log.Printf("oidc provider %s: %v", provider, err)
+		return nil, common.ErrOIDCLoginFailed
+	}
+	return claims, nil
+}
+
+// register 用第三方账号信息注册新用户并绑定
+func (s *oidcService) register(ctx context.Context, provider string, claims *oidc.Claims) (*entity.User, error) {
+	// 1. 必须有邮箱；邮箱已被占用时不自动绑定，避免通过第三方账号接管已有账号
+	if claims.Email == "" {
+		return nil, fmt.Errorf("%w: no email address returned", common.ErrOIDCLoginFailed)
+	}
+	if _, err := s.userRepo.FindByEmail(ctx, claims.Email); err == nil {
+		return nil, common.ErrOIDCEmailInUse
+	} else if !errors.Is(err, common.ErrUserNotFound) {
+		return nil, err
+	}
+
+	u := &entity.User{
+		Email:    claims.Email,
+		Password: noPassword,
+	}
+	if claims.EmailVerified {
+		now := time.Now()
+		u.EmailVerifiedAt = &now
+	}
+
+	// 2. 生成不重复的用户名，冲突时加随机后缀
+	base := usernameFromClaims(claims)
+	for i := 0; ; i++ {
+		if i == usernameAttempts {
+			return nil, errors.New("could not generate a unique username")
+		}
+		u.Username = base
+		if i > 0 {
+			u.Username = base + "-" + utils.RandString(4)
+		}
+		if reservedAccount("", u.Username) {
+			continue
+		}
+		if _, err := s.userRepo.FindByUsername(ctx, u.Username); err == nil {
+			continue
+		} else if !errors.Is(err, common.ErrUserNotFound) {
+			return nil, err
+		}
+
+		err := s.userRepo.Create(ctx, u)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, common.ErrUserAlreadyExist) {
+			return nil, fmt.Errorf("create user: %w", err)
+		}
+		// 并发注册：可能是邮箱也可能是用户名冲突
+		if _, err := s.userRepo.FindByEmail(ctx, claims.Email); err == nil {
+			return nil, common.ErrOIDCEmailInUse
+		}
+	}
+
+	// 3. 绑定
+	if err := s.identityRepo.Create(ctx, &entity.UserIdentity{
+		UserID:   u.ID,
+		Provider: provider,
+		Subject:  claims.Subject,
+		Email:    claims.Email,
+	}); err != nil {
+		return nil, err
+	}
+
+	// 4. provider 没有确认过的邮箱走本站的邮箱验证
+	if u.EmailVerifiedAt == nil {
+		if err := s.accounts.SendVerificationEmail(ctx, u.ID); err != nil {
+			log.Printf("send verification email to user %d failed: %v", u.ID, err)
+		}
+	}
+	return u, nil
+}
+
+// usernameFromClaims 依次尝试 preferred_username / name / 邮箱前缀，只保留小写字母、数字和 _ - .
+func usernameFromClaims(claims *oidc.Claims) string {
+	local, _, _ := strings.Cut(claims.Email, "@")
+	for _, candidate := range []string{claims.PreferredUsername, claims.Name, local} {
+		var b strings.Builder
+		for _, r := range strings.ToLower(candidate) {
+			switch {
+			case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '_', r == '-', r == '.':
+				b.WriteRune(r)
+			case r == ' ':
+				b.WriteRune('-')
+			}
+		}
+		name := strings.Trim(b.String(), "-.")
+		if len(name) > 40 {
+			name = name[:40]
+		}
+		if len(name) >= 3 {
+			return name
+		}
+	}
+	return "user"
+}
+
+func toIdentityDTO(identity *entity.UserIdentity) dto.IdentityDTO {
+	return dto.IdentityDTO{
+		Provider:  identity.Provider,
+		Email:     identity.Email,
+		CreatedAt: identity.CreatedAt,
+	}
+}
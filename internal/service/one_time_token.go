@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github/CiroLong/realworld-gin/internal/model/entity"
+	"github/CiroLong/realworld-gin/internal/pkg/common"
+	"github/CiroLong/realworld-gin/internal/pkg/token"
+	"github/CiroLong/realworld-gin/internal/repository"
+	"time"
+)
+
+// oneTimeTokens 负责邮件链接令牌的签发和核销
+// 同一用户同一用途只保留最新的一个令牌，签发新令牌时旧令牌作废
+
+type oneTimeTokens struct {
+	repo repository.OneTimeTokenRepo
+}
+
+func newOneTimeTokens(repo repository.OneTimeTokenRepo) *oneTimeTokens {
+	return &oneTimeTokens{repo: repo}
+}
+
+// issue 签发令牌，返回明文（只用于拼邮件链接）
+func (t *oneTimeTokens) issue(ctx context.Context, userID int64, purpose string, ttl time.Duration) (string, error) {
+	if err := t.repo.InvalidateUserTokens(ctx, userID, purpose); err != nil {
+		return "", err
+	}
+
+	plain, hash, err := token.Generate()
+	if err != nil {
+		return "", err
+	}
+	if err := t.repo.Create(ctx, &entity.OneTimeToken{
+		UserID:    userID,
+		Purpose:   purpose,
+		TokenHash: hash,
+		ExpiresAt: time.Now().Add(ttl),
+	}); err != nil {
+		return "", err
+	}
+	return plain, nil
+}
+
+// issuedSince 用户某个用途下 since 之后签发的令牌数
+func (t *oneTimeTokens) issuedSince(ctx context.Context, userID int64, purpose string, since time.Time) (int64, error) {
+	return t.repo.CountIssuedSince(ctx, userID, purpose, since)
+}
+
+// consume 核销令牌，令牌不存在 / 已使用 / 已过期都返回 common.ErrInvalidToken
+func (t *oneTimeTokens) consume(ctx context.Context, purpose string, plain string) (*entity.OneTimeToken, error) {
+	// 1. 查令牌
+	ott, err := t.repo.FindByHash(ctx, purpose, token.Hash(plain))
+	if err != nil {
+		if errors.Is(err, common.ErrNotFound) {
+			return nil, common.ErrInvalidToken
+		}
+		return nil, err
+	}
+
+	// 2. 校验状态
+	if ott.UsedAt != nil || time.Now().After(ott.ExpiresAt) {
+		return nil, common.ErrInvalidToken
+	}
+
+	// 3. 条件更新标记已使用，并发下只有一个请求能成功
+	ok, err := t.repo.Consume(ctx, ott.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, common.ErrInvalidToken
+	}
+	return ott, nil
+}
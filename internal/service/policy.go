@@ -0,0 +1,18 @@
+package service
+
+import (
+	"context"
+	"github/CiroLong/realworld-gin/internal/model/entity"
+)
+
+// Policy 集中回答“某个用户能否对某个对象做某件事”
+// 作者总是可以处理自己的内容；处理别人的内容需要角色拥有对应权限（见 auth 包中的权限定义）
+// Can* 方法允许时返回 nil，否则返回 common.ErrPermissionDenied
+type Policy interface {
+	// HasPermission 用户的角色是否拥有该权限，角色不存在时视为没有任何权限
+	HasPermission(ctx context.Context, userID int64, perm string) (bool, error)
+
+	CanUpdateArticle(ctx context.Context, userID int64, article *entity.Article) error
+	CanDeleteArticle(ctx context.Context, userID int64, article *entity.Article) error
+	CanDeleteComment(ctx context.Context, userID int64, comment *entity.Comment) error
+}
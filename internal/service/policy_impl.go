@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github/CiroLong/realworld-gin/internal/model/entity"
+	"github/CiroLong/realworld-gin/internal/pkg/auth"
+	"github/CiroLong/realworld-gin/internal/pkg/common"
+	"github/CiroLong/realworld-gin/internal/repository"
+	"slices"
+	"strings"
+)
+
+type policy struct {
+	userRepo repository.UserRepo
+	roleRepo repository.RoleRepo
+}
+
+func NewPolicy(userRepo repository.UserRepo, roleRepo repository.RoleRepo) Policy {
+	return &policy{
+		userRepo: userRepo,
+		roleRepo: roleRepo,
+	}
+}
+
+// HasPermission 只在处理别人的内容和访问管理接口时调用，每次都查库，角色变更立即生效
+func (p *policy) HasPermission(ctx context.Context, userID int64, perm string) (bool, error) {
+	u, err := p.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+
+	role, err := p.roleRepo.FindByName(ctx, roleOf(u))
+	if err != nil {
+		if errors.Is(err, common.ErrNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return slices.Contains(strings.Fields(role.Permissions), perm), nil
+}
+
+func (p *policy) CanUpdateArticle(ctx context.Context, userID int64, article *entity.Article) error {
+	return p.ownerOr(ctx, userID, article.AuthorID, auth.PermArticlesUpdateAny)
+}
+
+func (p *policy) CanDeleteArticle(ctx context.Context, userID int64, article *entity.Article) error {
+	return p.ownerOr(ctx, userID, article.AuthorID, auth.PermArticlesDeleteAny)
+}
+
+func (p *policy) CanDeleteComment(ctx context.Context, userID int64, comment *entity.Comment) error {
+	return p.ownerOr(ctx, userID, comment.AuthorID, auth.PermCommentsDeleteAny)
+}
+
+// ownerOr 作者本人直接放行，否则需要 perm
+func (p *policy) ownerOr(ctx context.Context, userID int64, ownerID int64, perm string) error {
+	if userID == ownerID {
+		return nil
+	}
+	ok, err := p.HasPermission(ctx, userID, perm)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return common.ErrPermissionDenied
+	}
+	return nil
+}
+
+// roleOf 加 role 列之前创建的用户没有角色，按普通用户处理
+func roleOf(u *entity.User) string {
+	if u.Role == "" {
+		return auth.RoleUser
+	}
+	return u.Role
+}
@@ -0,0 +1,18 @@
+package service
+
+import (
+	"context"
+	"github/CiroLong/realworld-gin/internal/model/dto"
+)
+
+// RoleService 角色的查看、初始化和分配
+type RoleService interface {
+	// List 所有角色及其权限
+	List(ctx context.Context) (*dto.RoleListResponse, error)
+
+	// Seed 写入 auth.DefaultRoles，可重复执行，返回写入的角色名
+	Seed(ctx context.Context) ([]string, error)
+
+	// Assign 修改用户的角色；角色不存在返回 common.ErrUnknownRole，用户不存在返回 common.ErrUserNotFound
+	Assign(ctx context.Context, email string, role string) error
+}
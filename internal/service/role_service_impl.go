@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github/CiroLong/realworld-gin/internal/model/dto"
+	"github/CiroLong/realworld-gin/internal/model/entity"
+	"github/CiroLong/realworld-gin/internal/pkg/auth"
+	"github/CiroLong/realworld-gin/internal/pkg/common"
+	"github/CiroLong/realworld-gin/internal/repository"
+	"strings"
+)
+
+type roleService struct {
+	roleRepo repository.RoleRepo
+	userRepo repository.UserRepo
+}
+
+func NewRoleService(roleRepo repository.RoleRepo, userRepo repository.UserRepo) RoleService {
+	return &roleService{
+		roleRepo: roleRepo,
+		userRepo: userRepo,
+	}
+}
+
+func (s *roleService) List(ctx context.Context) (*dto.RoleListResponse, error) {
+	roles, err := s.roleRepo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &dto.RoleListResponse{Roles: make([]dto.RoleDTO, 0, len(roles))}
+	for _, role := range roles {
+		resp.Roles = append(resp.Roles, dto.RoleDTO{
+			Name:        role.Name,
+			Description: role.Description,
+			Permissions: strings.Fields(role.Permissions),
+		})
+	}
+	return resp, nil
+}
+
+func (s *roleService) Seed(ctx context.Context) ([]string, error) {
+	names := make([]string, 0, len(auth.DefaultRoles))
+	for _, def := range auth.DefaultRoles {
+		if err := s.roleRepo.Save(ctx, &entity.Role{
+			Name:        def.Name,
+			Description: def.Description,
+			Permissions: strings.Join(def.Permissions, " "),
+		}); err != nil {
+			return names, err
+		}
+		names = append(names, def.Name)
+	}
+	return names, nil
+}
+
+func (s *roleService) Assign(ctx context.Context, email string, role string) error {
+	// 1. 角色必须已存在
+	if _, err := s.roleRepo.FindByName(ctx, role); err != nil {
+		if errors.Is(err, common.ErrNotFound) {
+			return common.ErrUnknownRole
+		}
+		return err
+	}
+
+	// 2. 更新用户
+	u, err := s.userRepo.FindByEmail(ctx, email)
+	if err != nil {
+		return err
+	}
+	u.Role = role
+	return s.userRepo.Update(ctx, u)
+}
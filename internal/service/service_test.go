@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"github/CiroLong/realworld-gin/internal/model/dto"
+	"github/CiroLong/realworld-gin/internal/pkg/jwt"
+	"github/CiroLong/realworld-gin/internal/pkg/mailer"
+	"github/CiroLong/realworld-gin/internal/pkg/password"
+	"github/CiroLong/realworld-gin/internal/pkg/secretbox"
+	"github/CiroLong/realworld-gin/internal/pkg/slug"
+	"github/CiroLong/realworld-gin/internal/repository"
+	"github/CiroLong/realworld-gin/internal/repository/memory"
+	"testing"
+	"time"
+)
+
+// testServices 用内存 repo 组装的 service，和 cmd/server 在 database.driver=memory 时一致
+type testServices struct {
+	t             *testing.T
+	userRepo      repository.UserRepo
+	users         UserService
+	accounts      AccountService
+	mfa           MFAService
+	tokens        TokenService
+	authenticator Authenticator
+	articles      ArticleService
+	comments      CommentService
+	roles         RoleService
+}
+
+func newTestServices(t *testing.T) *testServices {
+	t.Helper()
+	store := memory.NewStore()
+	userRepo := memory.NewUserRepo(store)
+	sessionRepo := memory.NewSessionRepo(store)
+	articleRepo := memory.NewArticleRepo(store)
+	roleRepo := memory.NewRoleRepo(store)
+	patRepo := memory.NewPersonalAccessTokenRepo(store)
+
+	jwtMgr := jwt.NewManager("test-secret", time.Hour)
+	hasher, err := password.NewHasher(password.Config{Algorithm: password.AlgorithmBcrypt, BcryptCost: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	box, err := secretbox.New("test-mfa-key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	slugs, err := slug.New(slug.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	accounts := NewAccountService(userRepo, sessionRepo, patRepo, memory.NewOneTimeTokenRepo(store), hasher, mailer.NewLogMailer("test@example.com"), AccountConfig{
+		PasswordResetTTL:     time.Hour,
+		EmailVerificationTTL: time.Hour,
+		MagicLinkTTL:         time.Hour,
+	})
+	mfa := NewMFAService(memory.NewMFARepo(store), userRepo, jwtMgr, hasher, box, MFAConfig{Issuer: "test", PendingTTL: time.Minute})
+	guard := NewLoginProtection(memory.NewLoginAttemptRepo(store), LoginProtectionConfig{})
+	policy := NewPolicy(userRepo, roleRepo)
+	roles := NewRoleService(roleRepo, userRepo)
+	if _, err := roles.Seed(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	return &testServices{
+		t:             t,
+		userRepo:      userRepo,
+		users:         NewUserService(userRepo, sessionRepo, jwtMgr, hasher, time.Hour, accounts, mfa, guard, UserConfig{}),
+		accounts:      accounts,
+		mfa:           mfa,
+		tokens:        NewTokenService(patRepo),
+		authenticator: NewAuthenticator(jwtMgr, patRepo, sessionRepo, userRepo),
+		articles:      NewArticleService(articleRepo, userRepo, policy, slugs, ArticleConfig{}),
+		comments:      NewCommentService(memory.NewCommentRepo(store), articleRepo, userRepo, policy),
+		roles:         roles,
+	}
+}
+
+// register 注册用户（邮箱 <username>@example.com，密码 <username>-password），返回用户 id
+func (s *testServices) register(username string) int64 {
+	s.t.Helper()
+	ctx := context.Background()
+	var req dto.RegisterRequest
+	req.User.Username = username
+	req.User.Email = username + "@example.com"
+	req.User.Password = username + "-password"
+	if _, err := s.users.Register(ctx, &req, ClientMeta{}); err != nil {
+		s.t.Fatalf("register %s: %v", username, err)
+	}
+	u, err := s.userRepo.FindByUsername(ctx, username)
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	return u.ID
+}
+
+// createArticle 以 authorID 的身份发布文章，返回 slug
+func (s *testServices) createArticle(authorID int64, title, status string, tags ...string) string {
+	s.t.Helper()
+	var req dto.CreateArticleRequest
+	req.Article.Title = title
+	req.Article.Description = "description of " + title
+	req.Article.Body = "body of " + title
+	req.Article.Status = status
+	req.Article.TagList = tags
+	resp, err := s.articles.CreateArticle(context.Background(), authorID, &req)
+	if err != nil {
+		s.t.Fatalf("create %q: %v", title, err)
+	}
+	return resp.Article.Slug
+}
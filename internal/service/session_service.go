@@ -0,0 +1,18 @@
+package service
+
+import (
+	"context"
+	"github/CiroLong/realworld-gin/internal/model/dto"
+)
+
+// SessionService 当前用户的登录会话：查看 / 踢下线 / 在所有设备上登出
+type SessionService interface {
+	// List 用户仍然有效的会话，currentSessionID 对应的会话标记为当前会话
+	List(ctx context.Context, userID int64, currentSessionID int64) (*dto.SessionListResponse, error)
+
+	// Revoke 撤销用户的某个会话，不存在、不属于该用户或已撤销时返回 common.ErrNotFound
+	Revoke(ctx context.Context, userID int64, sessionID int64) error
+
+	// RevokeAll 撤销用户的所有会话，包括当前会话
+	RevokeAll(ctx context.Context, userID int64) error
+}
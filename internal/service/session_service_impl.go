@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"github/CiroLong/realworld-gin/internal/model/dto"
+	"github/CiroLong/realworld-gin/internal/model/entity"
+	"github/CiroLong/realworld-gin/internal/pkg/common"
+	"github/CiroLong/realworld-gin/internal/repository"
+	"strings"
+	"time"
+)
+
+type sessionService struct {
+	sessionRepo repository.SessionRepo
+	refreshTTL  time.Duration
+}
+
+func NewSessionService(sessionRepo repository.SessionRepo, refreshTTL time.Duration) SessionService {
+	return &sessionService{
+		sessionRepo: sessionRepo,
+		refreshTTL:  refreshTTL,
+	}
+}
+
+func (s *sessionService) List(ctx context.Context, userID int64, currentSessionID int64) (*dto.SessionListResponse, error) {
+	// 超过 refresh token 有效期没有活动的会话已经无法再刷新，不再展示
+	sessions, err := s.sessionRepo.ListActiveSessions(ctx, userID, time.Now().Add(-s.refreshTTL))
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &dto.SessionListResponse{Sessions: make([]dto.SessionDTO, 0, len(sessions))}
+	for _, session := range sessions {
+		resp.Sessions = append(resp.Sessions, toSessionDTO(session, currentSessionID))
+	}
+	return resp, nil
+}
+
+func (s *sessionService) Revoke(ctx context.Context, userID int64, sessionID int64) error {
+	session, err := s.sessionRepo.FindSessionByID(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+	// 别人的会话同样返回不存在，不暴露会话 id 是否有效
+	if session.UserID != userID || session.RevokedAt != nil {
+		return common.ErrNotFound
+	}
+	return s.sessionRepo.RevokeSession(ctx, sessionID)
+}
+
+func (s *sessionService) RevokeAll(ctx context.Context, userID int64) error {
+	return s.sessionRepo.RevokeUserSessions(ctx, userID)
+}
+
+func toSessionDTO(session *entity.Session, currentSessionID int64) dto.SessionDTO {
+	return dto.SessionDTO{
+		ID:         session.ID,
+		Device:     describeUserAgent(session.UserAgent),
+		UserAgent:  session.UserAgent,
+		IP:         session.IP,
+		CreatedAt:  session.CreatedAt,
+		LastSeenAt: session.LastSeenAt,
+		Current:    session.ID == currentSessionID,
+
+		Impersonated: session.ImpersonatorID != 0,
+	}
+}
+
+// describeUserAgent 从 User-Agent 中粗略识别浏览器和系统，如 "Chrome on Windows"，识别不了的原样交给前端展示
+// 顺序有讲究：Edge / Opera 的 UA 里也带 Chrome，Chrome 的 UA 里也带 Safari
+func describeUserAgent(ua string) string {
+	if ua == "" {
+		return "Unknown device"
+	}
+
+	browser := ""
+	for _, b := range []struct{ token, name string }{
+		{"Edg/", "Edge"},
+		{"OPR/", "Opera"},
+		{"Firefox/", "Firefox"},
+		{"Chrome/", "Chrome"},
+		{"CriOS/", "Chrome"},
+		{"Safari/", "Safari"},
+		{"curl/", "curl"},
+	} {
+		if strings.Contains(ua, b.token) {
+			browser = b.name
+			break
+		}
+	}
+
+	platform := ""
+	for _, o := range []struct{ token, name string }{
+		{"iPhone", "iOS"},
+		{"iPad", "iPadOS"},
+		{"Android", "Android"},
+		{"Windows", "Windows"},
+		{"Mac OS X", "macOS"},
+		{"Linux", "Linux"},
+	} {
+		if strings.Contains(ua, o.token) {
+			platform = o.name
+			break
+		}
+	}
+
+	switch {
+	case browser != "" && platform != "":
+		return browser + " on " + platform
+	case browser != "":
+		return browser
+	case platform != "":
+		return platform
+	}
+	return "Unknown device"
+}
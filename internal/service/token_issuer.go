@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github/CiroLong/realworld-gin/internal/model/entity"
+	"github/CiroLong/realworld-gin/internal/pkg/common"
+	"github/CiroLong/realworld-gin/internal/pkg/jwt"
+	"github/CiroLong/realworld-gin/internal/pkg/token"
+	"github/CiroLong/realworld-gin/internal/repository"
+	"log"
+	"time"
+)
+
+// tokenIssuer 负责会话和令牌的签发 / 轮换 / 撤销
+// access token 是短期 JWT，refresh token 是不透明随机串，只存摘要；
+// 每次刷新都会轮换 refresh token，旧 token 再次出现说明被盗用，直接撤销整个会话（token family）
+
+type tokenIssuer struct {
+	sessionRepo repository.SessionRepo
+	jwtMgr      jwt.Manager
+	refreshTTL  time.Duration
+}
+
+// tokenPair 下发给客户端的一组凭证
+type tokenPair struct {
+	AccessToken  string
+	RefreshToken string
+}
+
+func newTokenIssuer(sessionRepo repository.SessionRepo, jwtMgr jwt.Manager, refreshTTL time.Duration) *tokenIssuer {
+	return &tokenIssuer{
+		sessionRepo: sessionRepo,
+		jwtMgr:      jwtMgr,
+		refreshTTL:  refreshTTL,
+	}
+}
+
+// issue 为用户新建一个会话并签发 access / refresh token，meta 记录在会话上供会话列表展示
+// 所有登录方式最终都走这里，被停用的账号在这里拦下
+func (t *tokenIssuer) issue(ctx context.Context, u *entity.User, meta ClientMeta) (*tokenPair, error) {
+	if u.SuspendedAt != nil {
+		return nil, common.ErrAccountSuspended
+	}
+
+	now := time.Now()
+	session := &entity.Session{
+		UserID:     u.ID,
+		UserAgent:  truncate(meta.UserAgent, 255),
+		IP:         truncate(meta.IP, 64),
+		CreatedAt:  now,
+		LastSeenAt: now,
+	}
+	if err := t.sessionRepo.CreateSession(ctx, session); err != nil {
+		return nil, err
+	}
+	return t.issueForSession(ctx, session)
+}
+
+// impersonate 管理员以用户身份登录：新建一个记录了管理员 id 的会话，只签发 access token，过期后不能刷新
+func (t *tokenIssuer) impersonate(ctx context.Context, userID int64, impersonatorID int64, meta ClientMeta) (string, error) {
+	now := time.Now()
+	session := &entity.Session{
+		UserID:         userID,
+		UserAgent:      truncate(meta.UserAgent, 255),
+		IP:             truncate(meta.IP, 64),
+		ImpersonatorID: impersonatorID,
+		CreatedAt:      now,
+		LastSeenAt:     now,
+	}
+	if err := t.sessionRepo.CreateSession(ctx, session); err != nil {
+		return "", err
+	}
+	return t.jwtMgr.Generate(userID, session.ID)
+}
+
+// refresh 用 refresh token 换一组新的凭证，返回所属用户 id
+func (t *tokenIssuer) refresh(ctx context.Context, plain string) (int64, *tokenPair, error) {
+	// 1. 查 token
+	rt, err := t.sessionRepo.FindRefreshTokenByHash(ctx, token.Hash(plain))
+	if err != nil {
+		if errors.Is(err, common.ErrNotFound) {
+			return 0, nil, common.ErrInvalidToken
+		}
+		return 0, nil, err
+	}
+
+	// 2. 会话必须有效
+	session, err := t.sessionRepo.FindSessionByID(ctx, rt.SessionID)
+	if err != nil {
+		if errors.Is(err, common.ErrNotFound) {
+			return 0, nil, common.ErrInvalidToken
+		}
+		return 0, nil, err
+	}
+	if session.RevokedAt != nil {
+		return 0, nil, common.ErrInvalidToken
+	}
+
+	// 3. 重放检测：已轮换过的 token 又被使用，撤销整个 family
+	if rt.UsedAt != nil {
+		return 0, nil, t.revokeReused(ctx, session)
+	}
+	if time.Now().After(rt.ExpiresAt) {
+		return 0, nil, common.ErrInvalidToken
+	}
+
+	// 4. 轮换：条件更新保证并发下只有一个请求能用这个 token
+	ok, err := t.sessionRepo.MarkRefreshTokenUsed(ctx, rt.ID)
+	if err != nil {
+		return 0, nil, err
+	}
+	if !ok {
+		return 0, nil, t.revokeReused(ctx, session)
+	}
+
+	pair, err := t.issueForSession(ctx, session)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	// 5. 刷新说明客户端仍在使用该会话，失败不影响本次刷新
+	if err := t.sessionRepo.TouchSession(ctx, session.ID, time.Now()); err != nil {
+		log.Printf("update last seen time of session %d failed: %v", session.ID, err)
+	}
+	return session.UserID, pair, nil
+}
+
+// revoke 撤销会话，sessionID 为 0（旧版本签发的 token 没有会话）时什么也不做
+func (t *tokenIssuer) revoke(ctx context.Context, sessionID int64) error {
+	if sessionID == 0 {
+		return nil
+	}
+	return t.sessionRepo.RevokeSession(ctx, sessionID)
+}
+
+// revokeAll 撤销用户的所有会话
+func (t *tokenIssuer) revokeAll(ctx context.Context, userID int64) error {
+	return t.sessionRepo.RevokeUserSessions(ctx, userID)
+}
+
+// impersonated 会话是否是管理员模拟登录创建的
+func (t *tokenIssuer) impersonated(ctx context.Context, sessionID int64) (bool, error) {
+	if sessionID == 0 {
+		return false, nil
+	}
+	session, err := t.sessionRepo.FindSessionByID(ctx, sessionID)
+	if err != nil {
+		return false, err
+	}
+	return session.ImpersonatorID != 0, nil
+}
+
+func (t *tokenIssuer) issueForSession(ctx context.Context, session *entity.Session) (*tokenPair, error) {
+	accessToken, err := t.jwtMgr.Generate(session.UserID, session.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	plain, hash, err := token.Generate()
+	if err != nil {
+		return nil, err
+	}
+	if err := t.sessionRepo.CreateRefreshToken(ctx, &entity.RefreshToken{
+		SessionID: session.ID,
+		UserID:    session.UserID,
+		TokenHash: hash,
+		ExpiresAt: time.Now().Add(t.refreshTTL),
+	}); err != nil {
+		return nil, err
+	}
+
+	return &tokenPair{AccessToken: accessToken, RefreshToken: plain}, nil
+}
+
+func (t *tokenIssuer) revokeReused(ctx context.Context, session *entity.Session) error {
+	log.Printf("refresh token reuse detected, revoking session %d of user %d", session.ID, session.UserID)
+	if err := t.sessionRepo.RevokeSession(ctx, session.ID); err != nil {
+		return err
+	}
+	return common.ErrInvalidToken
+}
+
+// truncate 按字符截断到 n 个字符以内，避免超出列宽
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n])
+}
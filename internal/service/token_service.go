@@ -0,0 +1,24 @@
+package service
+
+import (
+	"context"
+	"github/CiroLong/realworld-gin/internal/model/dto"
+	"github/CiroLong/realworld-gin/internal/pkg/auth"
+)
+
+// TokenService 个人访问令牌：创建 / 列出 / 撤销
+type TokenService interface {
+	// Create 创建令牌，明文只在返回值里出现这一次；scope 不合法时返回 common.ErrInvalidScope
+	Create(ctx context.Context, userID int64, req *dto.CreateTokenRequest) (*dto.TokenResponse, error)
+
+	// List 用户未撤销的令牌（含已过期的），不含明文
+	List(ctx context.Context, userID int64) (*dto.TokenListResponse, error)
+
+	// Revoke 撤销令牌，不存在时返回 common.ErrNotFound
+	Revoke(ctx context.Context, userID int64, tokenID int64) error
+}
+
+// Authenticator 校验请求携带的凭证：登录得到的 JWT 或个人访问令牌
+type Authenticator interface {
+	Authenticate(ctx context.Context, token string) (*auth.Principal, error)
+}
@@ -0,0 +1,239 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github/CiroLong/realworld-gin/internal/model/dto"
+	"github/CiroLong/realworld-gin/internal/model/entity"
+	"github/CiroLong/realworld-gin/internal/pkg/auth"
+	"github/CiroLong/realworld-gin/internal/pkg/common"
+	"github/CiroLong/realworld-gin/internal/pkg/jwt"
+	"github/CiroLong/realworld-gin/internal/pkg/token"
+	"github/CiroLong/realworld-gin/internal/repository"
+	"log"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	// patPrefix 个人访问令牌的固定前缀，便于和 JWT 区分，也方便密钥扫描工具识别
+	patPrefix = "rwpat_"
+	// patDisplayLen 列表中展示的明文前缀长度
+	patDisplayLen = len(patPrefix) + 4
+	// patTouchInterval 最近使用时间的更新间隔，避免每个请求都写库
+	patTouchInterval = time.Minute
+	// sessionTouchInterval 会话最近活跃时间的更新间隔
+	sessionTouchInterval = time.Minute
+)
+
+type tokenService struct {
+	repo repository.PersonalAccessTokenRepo
+}
+
+func NewTokenService(repo repository.PersonalAccessTokenRepo) TokenService {
+	return &tokenService{repo: repo}
+}
+
+func (s *tokenService) Create(ctx context.Context, userID int64, req *dto.CreateTokenRequest) (*dto.TokenResponse, error) {
+	// 1. 校验并规整 scope（去重、排序）
+	scopes, err := normalizeScopes(req.Token.Scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. 生成明文，库里只存摘要
+	plain, _, err := token.Generate()
+	if err != nil {
+		return nil, err
+	}
+	plain = patPrefix + plain
+
+	pat := &entity.PersonalAccessToken{
+		UserID:    userID,
+		Name:      strings.TrimSpace(req.Token.Name),
+		TokenHash: token.Hash(plain),
+		Prefix:    plain[:patDisplayLen],
+		Scopes:    strings.Join(scopes, " "),
+	}
+	if req.Token.ExpiresInDays > 0 {
+		expiresAt := time.Now().AddDate(0, 0, req.Token.ExpiresInDays)
+		pat.ExpiresAt = &expiresAt
+	}
+
+	// 3. 保存
+	if err := s.repo.Create(ctx, pat); err != nil {
+		return nil, err
+	}
+
+	resp := toTokenDTO(pat)
+	resp.Token = plain
+	return &dto.TokenResponse{Token: resp}, nil
+}
+
+func (s *tokenService) List(ctx context.Context, userID int64) (*dto.TokenListResponse, error) {
+	tokens, err := s.repo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &dto.TokenListResponse{Tokens: make([]dto.TokenDTO, 0, len(tokens))}
+	for _, t := range tokens {
+		resp.Tokens = append(resp.Tokens, toTokenDTO(t))
+	}
+	return resp, nil
+}
+
+func (s *tokenService) Revoke(ctx context.Context, userID int64, tokenID int64) error {
+	return s.repo.Revoke(ctx, userID, tokenID)
+}
+
+type authenticator struct {
+	jwtMgr      jwt.Manager
+	repo        repository.PersonalAccessTokenRepo
+	sessionRepo repository.SessionRepo
+	userRepo    repository.UserRepo
+}
+
+// NewAuthenticator sessionRepo 每个请求都会查，建议传入带缓存的实现（见 repository/cache）
+func NewAuthenticator(
+	jwtMgr jwt.Manager,
+	repo repository.PersonalAccessTokenRepo,
+	sessionRepo repository.SessionRepo,
+	userRepo repository.UserRepo,
+) Authenticator {
+	return &authenticator{
+		jwtMgr:      jwtMgr,
+		repo:        repo,
+		sessionRepo: sessionRepo,
+		userRepo:    userRepo,
+	}
+}
+
+func (a *authenticator) Authenticate(ctx context.Context, raw string) (*auth.Principal, error) {
+	// 1. 带固定前缀的是个人访问令牌，其余按 JWT 处理
+	if !strings.HasPrefix(raw, patPrefix) {
+		claims, err := a.jwtMgr.Parse(raw)
+		if err != nil {
+			return nil, common.ErrInvalidToken
+		}
+		session, err := a.checkSession(ctx, claims.UserID, claims.SessionID)
+		if err != nil {
+			return nil, err
+		}
+		principal := &auth.Principal{
+			UserID:    claims.UserID,
+			Kind:      auth.KindSession,
+			SessionID: claims.SessionID,
+		}
+		if session != nil {
+			principal.ImpersonatorID = session.ImpersonatorID
+		}
+		return principal, nil
+	}
+
+	// 2. 查令牌，撤销或过期的一律视为无效
+	pat, err := a.repo.FindByHash(ctx, token.Hash(raw))
+	if err != nil {
+		if errors.Is(err, common.ErrNotFound) {
+			return nil, common.ErrInvalidToken
+		}
+		return nil, err
+	}
+	now := time.Now()
+	if pat.RevokedAt != nil || (pat.ExpiresAt != nil && now.After(*pat.ExpiresAt)) {
+		return nil, common.ErrInvalidToken
+	}
+
+	// 3. 停用账号的令牌不撤销（恢复后继续可用），但停用期间不能使用
+	if err := a.checkUser(ctx, pat.UserID); err != nil {
+		return nil, err
+	}
+
+	// 4. 记录最近使用时间，失败不影响本次请求
+	if pat.LastUsedAt == nil || now.Sub(*pat.LastUsedAt) >= patTouchInterval {
+		if err := a.repo.TouchLastUsed(ctx, pat.ID, now); err != nil {
+			log.Printf("update last used time of token %d failed: %v", pat.ID, err)
+		}
+	}
+
+	return &auth.Principal{
+		UserID:  pat.UserID,
+		Kind:    auth.KindPAT,
+		TokenID: pat.ID,
+		Scopes:  strings.Fields(pat.Scopes),
+	}, nil
+}
+
+// checkSession 会话被撤销（登出 / 踢下线 / 重置密码 / 账号停用）后，未过期的 access token 也随之失效；
+// sessionID 为 0 的是旧版本签发的 token，没有会话可查、登出和重置密码都撤销不了，
+// 只在 jwt.LegacyTokenDeadline 之前直接查账号状态放行，返回的会话为 nil
+func (a *authenticator) checkSession(ctx context.Context, userID int64, sessionID int64) (*entity.Session, error) {
+	if sessionID == 0 {
+		if !time.Now().Before(jwt.LegacyTokenDeadline) {
+			return nil, common.ErrInvalidToken
+		}
+		return nil, a.checkUser(ctx, userID)
+	}
+
+	session, err := a.sessionRepo.FindSessionByID(ctx, sessionID)
+	if err != nil {
+		if errors.Is(err, common.ErrNotFound) {
+			return nil, common.ErrInvalidToken
+		}
+		return nil, err
+	}
+	if session.RevokedAt != nil || session.UserID != userID {
+		return nil, common.ErrInvalidToken
+	}
+
+	// 记录最近活跃时间，失败不影响本次请求
+	now := time.Now()
+	if now.Sub(session.LastSeenAt) >= sessionTouchInterval {
+		if err := a.sessionRepo.TouchSession(ctx, session.ID, now); err != nil {
+			log.Printf("update last seen time of session %d failed: %v", session.ID, err)
+		}
+	}
+	return session, nil
+}
+
+// checkUser 账号被删除或停用时凭证无效
+func (a *authenticator) checkUser(ctx context.Context, userID int64) error {
+	u, err := a.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, common.ErrUserNotFound) {
+			return common.ErrInvalidToken
+		}
+		return err
+	}
+	if u.SuspendedAt != nil {
+		return common.ErrAccountSuspended
+	}
+	return nil
+}
+
+func normalizeScopes(scopes []string) ([]string, error) {
+	out := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		if !auth.ValidScope(scope) {
+			return nil, common.ErrInvalidScope
+		}
+		if !slices.Contains(out, scope) {
+			out = append(out, scope)
+		}
+	}
+	slices.Sort(out)
+	return out, nil
+}
+
+func toTokenDTO(t *entity.PersonalAccessToken) dto.TokenDTO {
+	return dto.TokenDTO{
+		ID:         t.ID,
+		Name:       t.Name,
+		Scopes:     strings.Fields(t.Scopes),
+		Prefix:     t.Prefix,
+		CreatedAt:  t.CreatedAt,
+		ExpiresAt:  t.ExpiresAt,
+		LastUsedAt: t.LastUsedAt,
+	}
+}
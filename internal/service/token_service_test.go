@@ -0,0 +1,40 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github/CiroLong/realworld-gin/internal/pkg/common"
+	"github/CiroLong/realworld-gin/internal/pkg/jwt"
+	"testing"
+	"time"
+)
+
+func TestAuthenticatorSessionlessToken(t *testing.T) {
+	s := newTestServices(t)
+	ctx := context.Background()
+	jake := s.register("jake")
+	deadline := jwt.LegacyTokenDeadline
+	t.Cleanup(func() { jwt.LegacyTokenDeadline = deadline })
+
+	// 旧版本签发的 token 不带会话，和 newTestServices 用同一个密钥
+	raw, err := jwt.NewManager("test-secret", time.Hour).Generate(jake, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 1. 过渡期内按账号状态放行
+	jwt.LegacyTokenDeadline = time.Now().Add(time.Hour)
+	principal, err := s.authenticator.Authenticate(ctx, raw)
+	if err != nil {
+		t.Fatalf("before the deadline: %v", err)
+	}
+	if principal.UserID != jake || principal.SessionID != 0 {
+		t.Errorf("principal = %+v", principal)
+	}
+
+	// 2. 过了期限，撤销不了的 token 一律拒绝
+	jwt.LegacyTokenDeadline = time.Now().Add(-time.Hour)
+	if _, err := s.authenticator.Authenticate(ctx, raw); !errors.Is(err, common.ErrInvalidToken) {
+		t.Errorf("after the deadline: %v", err)
+	}
+}
@@ -5,18 +5,49 @@ import (
 	"github/CiroLong/realworld-gin/internal/model/dto"
 )
 
+// 注销账号时文章和评论的处理方式
+const (
+	// DeletionPolicyAnonymize 转给 deleted-user 占位账号，保留讨论的上下文
+	DeletionPolicyAnonymize = "anonymize"
+	// DeletionPolicyDelete 连同文章下别人的评论一起删除
+	DeletionPolicyDelete = "delete"
+)
+
+// UserConfig DeletionPolicy 为空时按 anonymize 处理
+type UserConfig struct {
+	DeletionPolicy string
+}
+
 type UserService interface {
 	// Register 用户注册
-	Register(ctx context.Context, req *dto.RegisterRequest) (*dto.UserResponse, error)
+	Register(ctx context.Context, req *dto.RegisterRequest, meta ClientMeta) (*dto.UserResponse, error)
+
+	// Login 用户登录，开启了两步验证时只返回 MFA 临时 token
+	// 连续失败过多时返回 *common.RetryAfterError（ErrTooManyAttempts / ErrAccountLocked）
+	Login(ctx context.Context, req *dto.LoginRequest, meta ClientMeta) (*dto.LoginResponse, error)
+
+	// LoginMFA 登录第二步：用临时 token 和验证码（或恢复码）换取 token
+	LoginMFA(ctx context.Context, req *dto.MFALoginRequest, meta ClientMeta) (*dto.UserResponse, error)
+
+	// LoginMagicLink 用邮件中的免密登录链接登录，开启了两步验证时同样只返回 MFA 临时 token
+	LoginMagicLink(ctx context.Context, req *dto.RedeemMagicLinkRequest, meta ClientMeta) (*dto.LoginResponse, error)
+
+	// Refresh 用 refresh token 换取新的 access / refresh token（refresh token 会轮换）
+	Refresh(ctx context.Context, req *dto.RefreshRequest) (*dto.UserResponse, error)
+
+	// Logout 撤销当前会话
+	Logout(ctx context.Context, userID int64, sessionID int64) error
 
-	// Login 用户登录
-	Login(ctx context.Context, req *dto.LoginRequest) (*dto.UserResponse, error)
+	// GetCurrentUser 获取当前登录用户，token 为请求携带的 access token，原样返回
+	GetCurrentUser(ctx context.Context, userID int64, token string) (*dto.UserResponse, error)
 
-	// GetCurrentUser 获取当前登录用户
-	GetCurrentUser(ctx context.Context, userID int64) (*dto.UserResponse, error)
+	// UpdateCurrentUser 更新当前用户信息，version 为 If-Match 带回的版本，不一致返回 ErrVersionMismatch；0 表示不检查
+	// 管理员模拟登录的会话不能修改邮箱和密码（common.ErrImpersonating）
+	UpdateCurrentUser(ctx context.Context, userID int64, sessionID int64, token string, version int64, req *dto.UpdateUserRequest) (*dto.UserResponse, error)
 
-	// UpdateCurrentUser 更新当前用户信息
-	UpdateCurrentUser(ctx context.Context, userID int64, req *dto.UpdateUserRequest) (*dto.UserResponse, error)
+	// DeleteAccount 注销当前用户：有密码的账号需要输入密码确认，没有密码的账号输入用户名确认
+	// 文章和评论按 DeletionPolicy 处理；管理员模拟登录的会话不能注销账号（common.ErrImpersonating）
+	DeleteAccount(ctx context.Context, userID int64, sessionID int64, req *dto.DeleteAccountRequest) error
 
 	FollowUserByName(ctx context.Context, userID int64, username string) (*dto.ProfileResponse, error)
 	UnfollowUserByName(ctx context.Context, userID int64, username string) (*dto.ProfileResponse, error)
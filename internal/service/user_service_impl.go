@@ -11,24 +11,50 @@ import (
 	"github/CiroLong/realworld-gin/internal/pkg/password"
 	"github/CiroLong/realworld-gin/internal/repository"
 	"log"
+	"strings"
+	"time"
 )
 
 type userService struct {
 	userRepo repository.UserRepo
-	jwtMgr   jwt.Manager
+	tokens   *tokenIssuer
+	hasher   password.Hasher
+	accounts AccountService
+	mfa      MFAService
+	guard    LoginProtection
+	cfg      UserConfig
 }
 
+// 注销账号后（anonymize 策略）文章和评论的作者，首次注销时创建
+// 没有密码、邮箱不可投递，不能登录；这个用户名和邮箱不允许注册
+const (
+	deletedUsername  = "deleted-user"
+	deletedUserEmail = "deleted-user@users.invalid"
+)
+
 func NewUserService(
 	userRepo repository.UserRepo,
+	sessionRepo repository.SessionRepo,
 	jwtMgr jwt.Manager,
+	hasher password.Hasher,
+	refreshTTL time.Duration,
+	accounts AccountService,
+	mfa MFAService,
+	guard LoginProtection,
+	cfg UserConfig,
 ) UserService {
 	return &userService{
 		userRepo: userRepo,
-		jwtMgr:   jwtMgr,
+		tokens:   newTokenIssuer(sessionRepo, jwtMgr, refreshTTL),
+		hasher:   hasher,
+		accounts: accounts,
+		mfa:      mfa,
+		guard:    guard,
+		cfg:      cfg,
 	}
 }
 
-func (s *userService) Register(ctx context.Context, req *dto.RegisterRequest) (*dto.UserResponse, error) {
+func (s *userService) Register(ctx context.Context, req *dto.RegisterRequest, meta ClientMeta) (*dto.UserResponse, error) {
 
 	// 1. email 是否已存在
 	if _, err := s.userRepo.FindByEmail(ctx, req.User.Email); err == nil {
@@ -39,6 +65,9 @@ func (s *userService) Register(ctx context.Context, req *dto.RegisterRequest) (*
 	}
 
 	// 2. username 是否已存在
+	if reservedAccount(req.User.Email, req.User.Username) {
+		return nil, errors.New("username already exists")
+	}
 	if _, err := s.userRepo.FindByUsername(ctx, req.User.Username); err == nil {
 		return nil, errors.New("username already exists")
 	} else if !errors.Is(err, common.ErrUserNotFound) {
@@ -47,7 +76,7 @@ func (s *userService) Register(ctx context.Context, req *dto.RegisterRequest) (*
 	}
 
 	// 3. hash password
-	hash, err := password.Hash(req.User.Password)
+	hash, err := s.hasher.Hash(req.User.Password)
 	if err != nil {
 		return nil, err
 	}
@@ -63,102 +92,209 @@ func (s *userService) Register(ctx context.Context, req *dto.RegisterRequest) (*
 
 	// 5. 持久化
 	if err := s.userRepo.Create(ctx, u); err != nil {
-		fmt.Errorf("create fail")
-		return nil, err
+		return nil, fmt.Errorf("create user: %w", err)
 	}
 
-	// 6. 生成 JWT
-	token, err := s.jwtMgr.Generate(u.ID)
+	// 6. 发送验证邮件，失败不影响注册，用户可以稍后重发
+	if err := s.accounts.SendVerificationEmail(ctx, u.ID); err != nil {
+		log.Printf("send verification email to user %d failed: %v", u.ID, err)
+	}
+
+	// 7. 新建会话，签发 access / refresh token
+	tokens, err := s.tokens.issue(ctx, u, meta)
 	if err != nil {
-		fmt.Errorf("Generate jwt fail")
-		return nil, err
+		return nil, fmt.Errorf("issue tokens: %w", err)
 	}
 
-	// 7. 组装 Response DTO
-	return &dto.UserResponse{
-		User: dto.UserDTO{
-			Email:    u.Email,
-			Username: u.Username,
-			Bio:      u.Bio,
-			Image:    u.Image,
-			Token:    token,
-		},
-	}, nil
+	// 8. 组装 Response DTO
+	return newUserResponse(u, tokens.AccessToken, tokens.RefreshToken), nil
 }
 
-func (s *userService) Login(ctx context.Context, req *dto.LoginRequest) (*dto.UserResponse, error) {
+func (s *userService) Login(ctx context.Context, req *dto.LoginRequest, meta ClientMeta) (*dto.LoginResponse, error) {
+
+	// 0. 防爆破：退避 / 锁定期内直接拒绝，不再校验密码
+	// 不存在的邮箱同样计数，避免通过响应差异探测注册邮箱
+	if err := s.guard.Check(ctx, req.User.Email, meta.IP); err != nil {
+		return nil, err
+	}
 
 	// 1. 根据 email 查用户
 	u, err := s.userRepo.FindByEmail(ctx, req.User.Email)
 	if err != nil {
 		if errors.Is(err, common.ErrUserNotFound) {
-			return nil, errors.New("invalid email or password")
+			s.guard.Failure(ctx, req.User.Email, meta.IP)
+			return nil, common.ErrInvalidCredentials
 		}
 		return nil, err
 	}
 
 	// 2. 校验密码
-	if !password.Verify(u.Password, req.User.Password) {
-		return nil, errors.New("invalid email or password")
+	if !s.hasher.Verify(u.Password, req.User.Password) {
+		s.guard.Failure(ctx, req.User.Email, meta.IP)
+		return nil, common.ErrInvalidCredentials
 	}
 
-	// 3. 生成 JWT
-	token, err := s.jwtMgr.Generate(u.ID)
+	// 旧算法（bcrypt）或弱参数的哈希，趁有明文时升级，失败不影响登录
+	if s.hasher.NeedsRehash(u.Password) {
+		s.rehashPassword(ctx, u, req.User.Password)
+	}
+
+	// 3. 开启了两步验证：先不建会话，只返回临时 token
+	challenge, err := s.mfa.Challenge(ctx, u.ID)
 	if err != nil {
 		return nil, err
 	}
+	if challenge != nil {
+		return &dto.LoginResponse{MFA: challenge}, nil
+	}
+	s.guard.Success(ctx, u.Email)
 
-	// 4. 返回 Response DTO
-	return &dto.UserResponse{
-		User: dto.UserDTO{
-			Email:    u.Email,
-			Username: u.Username,
-			Bio:      u.Bio,
-			Image:    u.Image,
-			Token:    token,
-		},
-	}, nil
+	// 4. 新建会话，签发 access / refresh token
+	tokens, err := s.tokens.issue(ctx, u, meta)
+	if err != nil {
+		return nil, err
+	}
+
+	// 5. 返回 Response DTO
+	return &dto.LoginResponse{UserResponse: newUserResponse(u, tokens.AccessToken, tokens.RefreshToken)}, nil
 }
 
-// authed
-func (s *userService) GetCurrentUser(ctx context.Context, userID int64) (*dto.UserResponse, error) {
-	// 1. 根据 userID 查用户
+func (s *userService) LoginMFA(ctx context.Context, req *dto.MFALoginRequest, meta ClientMeta) (*dto.UserResponse, error) {
+	// 1. 校验临时 token，查用户
+	userID, err := s.mfa.ParseChallenge(req.MFAToken)
+	if err != nil {
+		return nil, err
+	}
 	u, err := s.userRepo.FindByID(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
 
-	// 2. 生成 token（RealWorld 要求返回 token）
-	token, err := s.jwtMgr.Generate(u.ID)
+	// 2. 验证码错误和密码错误计入同一个账号的失败次数
+	if err := s.guard.Check(ctx, u.Email, meta.IP); err != nil {
+		return nil, err
+	}
+	if _, err := s.mfa.VerifyChallenge(ctx, req.MFAToken, req.Code); err != nil {
+		if errors.Is(err, common.ErrInvalidMFACode) {
+			s.guard.Failure(ctx, u.Email, meta.IP)
+		}
+		return nil, err
+	}
+	s.guard.Success(ctx, u.Email)
+
+	// 3. 新建会话，签发 access / refresh token
+	tokens, err := s.tokens.issue(ctx, u, meta)
 	if err != nil {
 		return nil, err
 	}
 
-	// 3. 组装响应 DTO
-	return &dto.UserResponse{
-		User: dto.UserDTO{
-			Email:    u.Email,
-			Username: u.Username,
-			Bio:      u.Bio,
-			Image:    u.Image,
-			Token:    token,
-		},
-	}, nil
+	return newUserResponse(u, tokens.AccessToken, tokens.RefreshToken), nil
+}
+
+func (s *userService) LoginMagicLink(ctx context.Context, req *dto.RedeemMagicLinkRequest, meta ClientMeta) (*dto.LoginResponse, error) {
+	// 1. 核销链接
+	userID, err := s.accounts.RedeemMagicLink(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	u, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. 邮件链接只相当于一个因子，开启了两步验证时仍需验证码
+	challenge, err := s.mfa.Challenge(ctx, u.ID)
+	if err != nil {
+		return nil, err
+	}
+	if challenge != nil {
+		return &dto.LoginResponse{MFA: challenge}, nil
+	}
+
+	// 3. 新建会话，签发 access / refresh token
+	tokens, err := s.tokens.issue(ctx, u, meta)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.LoginResponse{UserResponse: newUserResponse(u, tokens.AccessToken, tokens.RefreshToken)}, nil
+}
+
+func (s *userService) Refresh(ctx context.Context, req *dto.RefreshRequest) (*dto.UserResponse, error) {
+	// 1. 轮换 refresh token
+	userID, tokens, err := s.tokens.refresh(ctx, req.RefreshToken)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. 查用户
+	u, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return newUserResponse(u, tokens.AccessToken, tokens.RefreshToken), nil
 }
 
 // authed
-func (s *userService) UpdateCurrentUser(ctx context.Context, userID int64, req *dto.UpdateUserRequest) (*dto.UserResponse, error) {
-	// 1. 查当前用户（确保存在）
+func (s *userService) Logout(ctx context.Context, userID int64, sessionID int64) error {
+	return s.tokens.revoke(ctx, sessionID)
+}
+
+// authed
+func (s *userService) GetCurrentUser(ctx context.Context, userID int64, token string) (*dto.UserResponse, error) {
+	// 1. 根据 userID 查用户
 	u, err := s.userRepo.FindByID(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
+
+	// 2. 组装响应 DTO（RealWorld 要求返回 token，这里原样返回当前 token，不再额外签发）
+	return newUserResponse(u, token, ""), nil
+}
+
+// authed
+func (s *userService) UpdateCurrentUser(ctx context.Context, userID int64, sessionID int64, token string, version int64, req *dto.UpdateUserRequest) (*dto.UserResponse, error) {
+	// 0. 管理员模拟登录时不能改邮箱和密码，否则可以借此接管账号
+	if req.User.Email != nil || req.User.Password != nil {
+		impersonated, err := s.tokens.impersonated(ctx, sessionID)
+		if err != nil {
+			return nil, err
+		}
+		if impersonated {
+			return nil, common.ErrImpersonating
+		}
+	}
+
+	// 1. 查当前用户（确保存在），客户端基于旧版本修改时拒绝
+	u, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkVersion(u.Version, version); err != nil {
+		return nil, err
+	}
 	// 2. 按需更新字段
+	if reservedAccount(stringValue(req.User.Email), stringValue(req.User.Username)) {
+		return nil, common.ErrUserAlreadyExist
+	}
+	emailChanged := false
 	if req.User.Email != nil {
+		// 只改大小写时仍是同一个邮箱，不用查重
+		if !strings.EqualFold(u.Email, *req.User.Email) {
+			if err := checkTaken(s.userRepo.FindByEmail(ctx, *req.User.Email)); err != nil {
+				return nil, err
+			}
+			emailChanged = true
+		}
 		u.Email = *req.User.Email
 	}
 
 	if req.User.Username != nil {
+		if !strings.EqualFold(u.Username, *req.User.Username) {
+			if err := checkTaken(s.userRepo.FindByUsername(ctx, *req.User.Username)); err != nil {
+				return nil, err
+			}
+		}
 		u.Username = *req.User.Username
 	}
 
@@ -171,7 +307,7 @@ func (s *userService) UpdateCurrentUser(ctx context.Context, userID int64, req *
 	}
 
 	if req.User.Password != nil {
-		hashed, err := password.Hash(*req.User.Password)
+		hashed, err := s.hasher.Hash(*req.User.Password)
 		if err != nil {
 			return nil, err
 		}
@@ -183,22 +319,68 @@ func (s *userService) UpdateCurrentUser(ctx context.Context, userID int64, req *
 		return nil, err
 	}
 
-	// 4. 生成 token（可轮换）
-	token, err := s.jwtMgr.Generate(u.ID)
-	if err != nil {
-		return nil, err
+	// 4. 换了邮箱需要重新验证
+	if emailChanged {
+		if err := s.userRepo.SetEmailVerified(ctx, u.ID, nil); err != nil {
+			return nil, err
+		}
+		u.EmailVerifiedAt = nil
+		if err := s.accounts.SendVerificationEmail(ctx, u.ID); err != nil {
+			log.Printf("send verification email to user %d failed: %v", u.ID, err)
+		}
 	}
 
 	// 5. 响应
-	return &dto.UserResponse{
-		User: dto.UserDTO{
-			Email:    u.Email,
-			Username: u.Username,
-			Bio:      u.Bio,
-			Image:    u.Image,
-			Token:    token,
-		},
-	}, nil
+	return newUserResponse(u, token, ""), nil
+}
+
+// authed
+func (s *userService) DeleteAccount(ctx context.Context, userID int64, sessionID int64, req *dto.DeleteAccountRequest) error {
+	// 1. 管理员模拟登录时不能替用户注销
+	impersonated, err := s.tokens.impersonated(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+	if impersonated {
+		return common.ErrImpersonating
+	}
+
+	// 2. 确认是本人操作
+	u, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if u.Password == noPassword {
+		if !strings.EqualFold(strings.TrimSpace(req.Username), u.Username) {
+			return common.ErrConfirmationMismatch
+		}
+	} else if !s.hasher.Verify(u.Password, req.Password) {
+		return common.ErrInvalidPassword
+	}
+
+	// 3. 先撤销会话：走 sessionRepo 才能同时清掉撤销检查的缓存
+	if err := s.tokens.revokeAll(ctx, u.ID); err != nil {
+		return err
+	}
+
+	// 4. 删除账号，文章和评论按策略转给占位账号或一并删除
+	var reassignTo int64
+	if s.cfg.DeletionPolicy != DeletionPolicyDelete {
+		placeholder, err := s.deletedUser(ctx)
+		if err != nil {
+			return err
+		}
+		reassignTo = placeholder.ID
+	}
+	if err := s.userRepo.Delete(ctx, u.ID, reassignTo); err != nil {
+		return err
+	}
+	if reassignTo != 0 {
+		log.Printf("user %d deleted their account, content reassigned to user %d", u.ID, reassignTo)
+	} else {
+		log.Printf("user %d deleted their account together with their content", u.ID)
+	}
+	return nil
 }
 
 func (s *userService) FollowUserByName(ctx context.Context, userID int64, username string) (*dto.ProfileResponse, error) {
@@ -276,3 +458,90 @@ func (s *userService) GetProfile(ctx context.Context, username string, userID in
 		},
 	}, nil
 }
+
+// rehashPassword 用当前配置的算法重新哈希密码并保存，不改 version（客户端的 ETag 仍然有效）
+func (s *userService) rehashPassword(ctx context.Context, u *entity.User, plain string) {
+	hashed, err := s.hasher.Hash(plain)
+	if err != nil {
+		log.Printf("rehash password of user %d failed: %v", u.ID, err)
+		return
+	}
+	if err := s.userRepo.RehashPassword(ctx, u.ID, u.Password, hashed); err != nil {
+		log.Printf("save rehashed password of user %d failed: %v", u.ID, err)
+		return
+	}
+	u.Password = hashed
+}
+
+// deletedUser 查找或创建注销账号的占位账号，按邮箱识别（用户名可能在保留之前已被注册）
+func (s *userService) deletedUser(ctx context.Context) (*entity.User, error) {
+	u, err := s.userRepo.FindByEmail(ctx, deletedUserEmail)
+	if err == nil {
+		return u, nil
+	}
+	if !errors.Is(err, common.ErrUserNotFound) {
+		return nil, err
+	}
+
+	u = &entity.User{
+		Email:    deletedUserEmail,
+		Username: deletedUsername,
+		Password: noPassword,
+	}
+	if err := s.userRepo.Create(ctx, u); err != nil {
+		// 并发注销时别的请求已经建好了
+		if existing, findErr := s.userRepo.FindByEmail(ctx, deletedUserEmail); findErr == nil {
+			return existing, nil
+		}
+		return nil, fmt.Errorf("create placeholder account %q: %w", deletedUsername, err)
+	}
+	return u, nil
+}
+
+// reservedAccount 占位账号的用户名和邮箱不允许注册或改用
+func reservedAccount(email string, username string) bool {
+	return strings.EqualFold(email, deletedUserEmail) || strings.EqualFold(username, deletedUsername)
+}
+
+// checkTaken 按邮箱 / 用户名查到了用户说明已被占用
+func checkTaken(_ *entity.User, err error) error {
+	if err == nil {
+		return common.ErrUserAlreadyExist
+	}
+	if errors.Is(err, common.ErrUserNotFound) {
+		return nil
+	}
+	return err
+}
+
+// checkVersion 乐观锁检查，expected 为 0 表示客户端没有带版本
+func checkVersion(current int64, expected int64) error {
+	if expected != 0 && expected != current {
+		return common.ErrVersionMismatch
+	}
+	return nil
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func newUserResponse(u *entity.User, token string, refreshToken string) *dto.UserResponse {
+	return &dto.UserResponse{
+		User: dto.UserDTO{
+			Email:        u.Email,
+			Username:     u.Username,
+			Bio:          u.Bio,
+			Image:        u.Image,
+			Token:        token,
+			RefreshToken: refreshToken,
+
+			EmailVerified: u.EmailVerifiedAt != nil,
+			Role:          roleOf(u),
+			Version:       u.Version,
+		},
+	}
+}
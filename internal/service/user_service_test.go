@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github/CiroLong/realworld-gin/internal/model/dto"
+	"github/CiroLong/realworld-gin/internal/pkg/common"
+	"github/CiroLong/realworld-gin/internal/pkg/password"
+	"strings"
+	"testing"
+)
+
+func TestUserServiceRegisterAndLogin(t *testing.T) {
+	s := newTestServices(t)
+	ctx := context.Background()
+	s.register("jake")
+
+	// 1. 邮箱或用户名已被占用时注册失败
+	tests := []struct {
+		name     string
+		username string
+		email    string
+	}{
+		{"duplicate email", "jacob", "jake@example.com"},
+		{"duplicate username", "jake", "jacob@example.com"},
+	}
+	for _, tc := range tests {
+		var req dto.RegisterRequest
+		req.User.Username, req.User.Email, req.User.Password = tc.username, tc.email, "password"
+		if _, err := s.users.Register(ctx, &req, ClientMeta{}); err == nil {
+			t.Errorf("%s: register succeeded", tc.name)
+		}
+	}
+
+	// 2. 密码正确时登录并签发 token，错误时失败
+	login := func(email, password string) (*dto.LoginResponse, error) {
+		var req dto.LoginRequest
+		req.User.Email, req.User.Password = email, password
+		return s.users.Login(ctx, &req, ClientMeta{})
+	}
+	resp, err := login("jake@example.com", "jake-password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.UserResponse == nil || resp.User.Username != "jake" || resp.User.Token == "" || resp.User.RefreshToken == "" {
+		t.Errorf("login response = %+v", resp)
+	}
+	if _, err := login("jake@example.com", "wrong-password"); err == nil {
+		t.Error("login with wrong password succeeded")
+	}
+	if _, err := login("nobody@example.com", "jake-password"); err == nil {
+		t.Error("login with unknown email succeeded")
+	}
+}
+
+func TestUserServiceLoginRehashKeepsVersion(t *testing.T) {
+	s := newTestServices(t)
+	ctx := context.Background()
+	jake := s.register("jake")
+
+	// 1. 把密码换成非首选算法（测试配置首选 bcrypt）的哈希，下次登录时会升级
+	argon, err := password.NewHasher(password.Config{Argon2: password.Argon2Params{Memory: 1024, Iterations: 1, Parallelism: 1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	hashed, err := argon.Hash("jake-password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, err := s.userRepo.FindByID(ctx, jake)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u.Password = hashed
+	if err := s.userRepo.Update(ctx, u); err != nil {
+		t.Fatal(err)
+	}
+
+	// 2. 登录后哈希已升级，但 version 不变，客户端手里的 ETag 仍然有效
+	var req dto.LoginRequest
+	req.User.Email, req.User.Password = "jake@example.com", "jake-password"
+	if _, err := s.users.Login(ctx, &req, ClientMeta{}); err != nil {
+		t.Fatal(err)
+	}
+	after, err := s.userRepo.FindByID(ctx, jake)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if after.Password == hashed || !strings.HasPrefix(after.Password, "$2") {
+		t.Errorf("password was not rehashed: %q", after.Password)
+	}
+	if after.Version != u.Version {
+		t.Errorf("version = %d, want %d", after.Version, u.Version)
+	}
+	if _, err := s.users.Login(ctx, &req, ClientMeta{}); err != nil {
+		t.Errorf("login after rehash: %v", err)
+	}
+}
+
+func TestUserServiceUpdateCurrentUserTaken(t *testing.T) {
+	s := newTestServices(t)
+	ctx := context.Background()
+	jake := s.register("jake")
+	s.register("anna")
+
+	update := func(email, username *string) error {
+		var req dto.UpdateUserRequest
+		req.User.Email, req.User.Username = email, username
+		_, err := s.users.UpdateCurrentUser(ctx, jake, 0, "", 0, &req)
+		return err
+	}
+	ptr := func(v string) *string { return &v }
+
+	// 1. 不能改成别人在用的邮箱 / 用户名，大小写不同也不行
+	if err := update(ptr("Anna@Example.com"), nil); !errors.Is(err, common.ErrUserAlreadyExist) {
+		t.Errorf("take email: err = %v", err)
+	}
+	if err := update(nil, ptr("ANNA")); !errors.Is(err, common.ErrUserAlreadyExist) {
+		t.Errorf("take username: err = %v", err)
+	}
+
+	// 2. 只改自己邮箱 / 用户名的大小写可以
+	if err := update(ptr("Jake@Example.com"), ptr("Jake")); err != nil {
+		t.Errorf("change case: %v", err)
+	}
+}
+
+func TestUserServiceFollow(t *testing.T) {
+	s := newTestServices(t)
+	ctx := context.Background()
+	jake := s.register("jake")
+	s.register("anna")
+
+	if _, err := s.users.FollowUserByName(ctx, jake, "anna"); err != nil {
+		t.Fatal(err)
+	}
+	if profile, err := s.users.GetProfile(ctx, "anna", jake); err != nil || !profile.Profile.Following {
+		t.Errorf("after follow: %+v, %v", profile, err)
+	}
+	// 未登录时 following 总是 false
+	if profile, err := s.users.GetProfile(ctx, "anna", 0); err != nil || profile.Profile.Following {
+		t.Errorf("anonymous: %+v, %v", profile, err)
+	}
+
+	if _, err := s.users.UnfollowUserByName(ctx, jake, "anna"); err != nil {
+		t.Fatal(err)
+	}
+	if profile, err := s.users.GetProfile(ctx, "anna", jake); err != nil || profile.Profile.Following {
+		t.Errorf("after unfollow: %+v, %v", profile, err)
+	}
+
+	// 不能关注自己和不存在的用户
+	if _, err := s.users.FollowUserByName(ctx, jake, "jake"); err == nil {
+		t.Error("follow yourself succeeded")
+	}
+	if _, err := s.users.FollowUserByName(ctx, jake, "nobody"); err == nil {
+		t.Error("follow unknown user succeeded")
+	}
+	if _, err := s.users.GetProfile(ctx, "nobody", jake); err == nil {
+		t.Error("profile of unknown user succeeded")
+	}
+}